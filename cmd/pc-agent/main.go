@@ -0,0 +1,92 @@
+// pc-agent chạy trên PC để nhận lệnh sleep/shutdown từ pi-monitor.
+//
+// Build:
+//
+//	GOOS=windows GOARCH=amd64 go build -o pc-agent.exe ./cmd/pc-agent
+//	GOOS=linux GOARCH=amd64 go build -o pc-agent ./cmd/pc-agent
+//
+// Chạy:
+//
+//	PC_AGENT_SECRET=<secret> pc-agent -listen :9770
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+
+	"pi-monitor/services"
+)
+
+func main() {
+	listen := flag.String("listen", ":9770", "Địa chỉ lắng nghe")
+	secret := flag.String("secret", os.Getenv("PC_AGENT_SECRET"), "Shared secret (hoặc biến môi trường PC_AGENT_SECRET)")
+	dryRun := flag.Bool("dry-run", false, "Chỉ ghi log, không thực sự sleep/shutdown")
+	flag.Parse()
+
+	if len(*secret) < 16 {
+		log.Fatal("Secret phải có ít nhất 16 ký tự (-secret hoặc PC_AGENT_SECRET)")
+	}
+
+	execute := func(action services.AgentAction) error {
+		name, args, err := powerCommand(action)
+		if err != nil {
+			return err
+		}
+		if *dryRun {
+			log.Printf("🧪 Dry run: %s %v", name, args)
+			return nil
+		}
+
+		// Chạy sau khi đã trả lời Pi để response không bị mất khi máy tắt
+		go func() {
+			time.Sleep(time.Second)
+			if out, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+				log.Printf("❌ %s failed: %v: %s", action, err, out)
+			}
+		}()
+		return nil
+	}
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           services.NewAgentServer(*secret, execute),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	log.Printf("🖥️ pc-agent listening on %s (%s)", *listen, runtime.GOOS)
+	log.Fatal(server.ListenAndServe())
+}
+
+// powerCommand trả về lệnh hệ điều hành tương ứng với action
+func powerCommand(action services.AgentAction) (string, []string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		switch action {
+		case services.AgentShutdown:
+			return "shutdown", []string{"/s", "/t", "0"}, nil
+		case services.AgentSleep:
+			return "rundll32.exe", []string{"powrprof.dll,SetSuspendState", "0,1,0"}, nil
+		}
+	case "linux":
+		switch action {
+		case services.AgentShutdown:
+			return "systemctl", []string{"poweroff"}, nil
+		case services.AgentSleep:
+			return "systemctl", []string{"suspend"}, nil
+		}
+	case "darwin":
+		switch action {
+		case services.AgentShutdown:
+			return "shutdown", []string{"-h", "now"}, nil
+		case services.AgentSleep:
+			return "pmset", []string{"sleepnow"}, nil
+		}
+	}
+	return "", nil, fmt.Errorf("%s không hỗ trợ trên %s", action, runtime.GOOS)
+}
@@ -1,19 +1,60 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
 	"time"
+
+	"pi-monitor/i18n"
 )
 
 type Config struct {
 	BotToken     string
-	AllowedUsers []int64
+	AllowedUsers []int64 // Cũ: tương đương Admins
+
+	// Phân quyền người dùng theo Telegram User ID
+	Admins    []int64
+	Operators []int64
+	Viewers   []int64
+	UserRoles map[int64]Role // Quyền đổi bằng /user (users.json), ưu tiên hơn cấu hình; RoleNone = đã xoá
+
+	// Thư mục lưu trạng thái (public IP, ...)
+	DataDir string
+
+	// Cách nhận update từ Telegram: polling (mặc định) hoặc webhook
+	BotMode           string
+	WebhookURL        string // URL public Telegram gọi đến (vd: https://pi.example.com/telegram), trống = không gọi setWebhook
+	WebhookListen     string // Địa chỉ lắng nghe (vd: :8443)
+	WebhookSecret     string // Secret token Telegram gửi kèm header X-Telegram-Bot-Api-Secret-Token
+	WebhookCertFile   string // Certificate TLS, trống = HTTP (chạy sau reverse proxy)
+	WebhookKeyFile    string // Private key TLS
+	WebhookSelfSigned bool   // Gửi certificate cho Telegram khi setWebhook (certificate tự ký)
+
+	// Timezone dùng khi hiển thị thời gian và chạy lịch (TIMEZONE, mặc định TZ hoặc Asia/Ho_Chi_Minh)
+	Timezone string
+	Location *time.Location
+
+	// Ngôn ngữ mặc định của tin nhắn (vi, en) khi người dùng chưa chọn bằng /lang
+	// và Telegram không gửi language_code được hỗ trợ
+	Language string
+
+	// Thông báo khi bot khởi động: first, restart, crash, reboot (all = tất cả, none = không thông báo)
+	StartupNotify []string
+
+	// Dừng bot (SIGTERM/SIGINT)
+	ShutdownTimeout time.Duration // Thời gian tối đa chờ lệnh đang chạy và lưu trạng thái trước khi thoát
+	ShutdownNotify  bool          // Gửi thông báo "🔴 Bot đang dừng" cho người dùng
+
+	// Heartbeat đến dịch vụ giám sát bên ngoài (healthchecks.io...), báo khi Pi mất điện hoặc bot dừng
+	HeartbeatURL      string        // URL ping, lỗi gửi đến <url>/fail; trống = tắt
+	HeartbeatInterval time.Duration // Khoảng thời gian giữa các lần ping
 
 	// Alert settings
 	AlertEnabled  bool
 	AlertInterval time.Duration // Khoảng thời gian kiểm tra
+	AlertCooldown time.Duration // Thời gian chờ trước khi cảnh báo lại cùng loại
 
 	// Alert thresholds
 	CPUTempThreshold  float64
@@ -21,19 +62,99 @@ type Config struct {
 	MemoryThreshold   float64
 	DiskThreshold     float64
 
+	// Wi-Fi monitoring
+	WiFiInterface       string        // Interface Wi-Fi (vd: wlan0), để trống để tắt
+	WiFiSignalThreshold float64       // Cảnh báo khi tín hiệu < ngưỡng (dBm)
+	WiFiReconnectLimit  int           // Số lần kết nối lại tối đa trong WiFiReconnectWindow, 0 = tắt
+	WiFiReconnectWindow time.Duration // Cửa sổ thời gian đếm số lần kết nối lại
+
 	// Wake-on-LAN settings
-	WOLMACAddress string // MAC address của PC (vd: AA:BB:CC:DD:EE:FF)
-	WOLBroadcast  string // Broadcast address (vd: 192.168.1.255:9)
-	WOLHost       string // IP/hostname của PC để kiểm tra xem có đang bật không
+	WOLTargets       []WOLTarget
+	WOLWaitTimeout   time.Duration // Thời gian chờ PC online sau khi gửi magic packet, 0 = không chờ
+	WOLRetries       int           // Số lần gửi magic packet tối đa khi chờ
+	WOLRetryInterval time.Duration // Gửi lại magic packet nếu PC chưa online sau khoảng này
+
+	// Theo dõi trạng thái bật/tắt của các PC
+	WOLTrackEnabled       bool
+	WOLTrackInterval      time.Duration
+	WOLTrackOfflineChecks int // Số lần kiểm tra thất bại liên tiếp trước khi coi là PC đã tắt
+
+	// Public IP monitoring
+	PublicIPEnabled   bool
+	PublicIPInterval  time.Duration
+	PublicIPEndpoints []string // Các URL trả về IP dạng text (vd: https://api.ipify.org)
+	PublicIPSTUN      string   // STUN server (vd: stun.l.google.com:19302), để trống nếu không dùng
+	PublicIPv6        bool     // Theo dõi cả IPv6
+
+	// LAN device discovery
+	DevicesEnabled      bool          // Cảnh báo khi có thiết bị lạ
+	DevicesInterval     time.Duration // Khoảng thời gian quét
+	DevicesSweep        bool          // Quét chủ động subnet trước khi đọc bảng ARP
+	DevicesOfflineAfter time.Duration // Không thấy trong bảng ARP quá thời gian này -> offline
+	OUIFile             string        // File OUI đầy đủ (tuỳ chọn), bổ sung cho bảng có sẵn
+}
+
+// WOLTarget là một PC có thể bật qua Wake-on-LAN
+type WOLTarget struct {
+	Name      string `yaml:"name"`       // Tên dùng với lệnh /wake <name>
+	MAC       string `yaml:"mac"`        // MAC address của PC (vd: AA:BB:CC:DD:EE:FF)
+	Host      string `yaml:"host"`       // IP/hostname của PC để kiểm tra xem có đang bật không
+	Broadcast string `yaml:"broadcast"`  // Broadcast address (vd: 192.168.1.255); có port (vd: :9) thì chỉ gửi port đó khi không set send_ports
+	Interface string `yaml:"interface"`  // Interface mạng nối với PC (vd: eth0), dùng để tính broadcast
+	Password  string `yaml:"password"`   // SecureOn password (tuỳ chọn, dạng AA:BB:CC:DD:EE:FF)
+	Mode      string `yaml:"mode"`       // Cách gửi magic packet: udp (mặc định), raw (Ethernet 0x0842), all
+	SendPorts []int  `yaml:"send_ports"` // Port UDP gửi magic packet (mặc định: port trong broadcast, không có thì 7,9)
+
+	CheckMethods []string `yaml:"check"` // Cách kiểm tra PC online: icmp, arp, tcp (mặc định: tất cả)
+	Ports        []int    `yaml:"ports"` // Port TCP dùng khi kiểm tra (mặc định: 445,80,3389,22)
+
+	AgentURL    string `yaml:"agent_url"`    // URL của pc-agent trên PC (vd: http://192.168.1.100:9770), dùng cho /sleep, /shutdown
+	AgentSecret string `yaml:"agent_secret"` // Shared secret để ký request đến pc-agent
+}
+
+// Load đọc cấu hình theo thứ tự: giá trị mặc định, file cấu hình (nếu path khác rỗng),
+// rồi biến môi trường (ghi đè file). Trả về ValidationError chứa tất cả lỗi nếu cấu hình không hợp lệ.
+func Load(path string) (*Config, error) {
+	cfg := defaults()
+
+	var errs ValidationError
+	if path != "" {
+		errs = append(errs, loadFile(path, cfg)...)
+	}
+
+	env := &envLoader{}
+	env.apply(cfg)
+	errs = append(errs, env.errs...)
+	errs = append(errs, cfg.validate()...)
+
+	if len(errs) > 0 {
+		return cfg, errs
+	}
+	return cfg, nil
 }
 
-func Load() *Config {
-	cfg := &Config{
-		BotToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
+// defaults trả về cấu hình mặc định
+func defaults() *Config {
+	return &Config{
+		DataDir:  "data",
+		Timezone: getEnvOrDefault("TZ", "Asia/Ho_Chi_Minh"),
+		Language: "vi",
+
+		// Telegram updates
+		BotMode:       "polling",
+		WebhookListen: ":8443",
+
+		// Khởi động lại bình thường (redeploy) không thông báo
+		StartupNotify: []string{"first", "crash", "reboot"},
+
+		// Docker chờ 10 giây sau SIGTERM trước khi SIGKILL
+		ShutdownTimeout: 8 * time.Second,
+
+		HeartbeatInterval: time.Minute,
 
 		// Default alert settings
-		AlertEnabled:  os.Getenv("ALERT_ENABLED") == "true",
 		AlertInterval: 30 * time.Second, // Default: check every 30 seconds
+		AlertCooldown: 5 * time.Minute,
 
 		// Default thresholds
 		CPUTempThreshold:  70.0,
@@ -41,54 +162,295 @@ func Load() *Config {
 		MemoryThreshold:   85.0,
 		DiskThreshold:     90.0,
 
+		// Wi-Fi
+		WiFiInterface:       "wlan0",
+		WiFiSignalThreshold: -75.0,
+		WiFiReconnectLimit:  3,
+		WiFiReconnectWindow: 10 * time.Minute,
+
 		// Wake-on-LAN
-		WOLMACAddress: os.Getenv("WOL_MAC_ADDRESS"),
-		WOLBroadcast:  getEnvOrDefault("WOL_BROADCAST", "255.255.255.255:9"),
-		WOLHost:       os.Getenv("WOL_HOST"),
+		WOLWaitTimeout:   2 * time.Minute,
+		WOLRetries:       3,
+		WOLRetryInterval: 20 * time.Second,
+
+		WOLTrackInterval:      time.Minute,
+		WOLTrackOfflineChecks: 3,
+
+		// Public IP
+		PublicIPInterval:  5 * time.Minute,
+		PublicIPEndpoints: []string{"https://api.ipify.org", "https://icanhazip.com", "https://ifconfig.me/ip"},
+
+		// LAN devices
+		DevicesInterval:     5 * time.Minute,
+		DevicesOfflineAfter: 15 * time.Minute,
+	}
+}
+
+// envLoader đọc biến môi trường vào Config, ghi lại lỗi khi giá trị sai định dạng.
+// Biến không được set (hoặc rỗng) giữ nguyên giá trị từ file/mặc định.
+type envLoader struct {
+	errs ValidationError
+}
+
+// apply ghi đè cấu hình bằng các biến môi trường đã set
+func (l *envLoader) apply(cfg *Config) {
+	l.string("TELEGRAM_BOT_TOKEN", &cfg.BotToken)
+	l.string("DATA_DIR", &cfg.DataDir)
+	l.string("TIMEZONE", &cfg.Timezone)
+	l.string("BOT_LANGUAGE", &cfg.Language)
+
+	// Telegram updates
+	l.string("BOT_MODE", &cfg.BotMode)
+	l.string("WEBHOOK_URL", &cfg.WebhookURL)
+	l.string("WEBHOOK_LISTEN", &cfg.WebhookListen)
+	l.string("WEBHOOK_SECRET", &cfg.WebhookSecret)
+	l.string("WEBHOOK_CERT_FILE", &cfg.WebhookCertFile)
+	l.string("WEBHOOK_KEY_FILE", &cfg.WebhookKeyFile)
+	l.bool("WEBHOOK_SELF_SIGNED", &cfg.WebhookSelfSigned)
+
+	// Parse allowed users from comma-separated string
+	// Example: ALLOWED_USERS=123456789,987654321
+	l.ids("ALLOWED_USERS", &cfg.AllowedUsers)
+	l.ids("ADMIN_USERS", &cfg.Admins)
+	l.ids("OPERATOR_USERS", &cfg.Operators)
+	l.ids("VIEWER_USERS", &cfg.Viewers)
+
+	// Khởi động, dừng bot
+	l.list("STARTUP_NOTIFY", &cfg.StartupNotify)
+	l.seconds("SHUTDOWN_TIMEOUT", &cfg.ShutdownTimeout)
+	l.bool("SHUTDOWN_NOTIFY", &cfg.ShutdownNotify)
+
+	// Heartbeat
+	l.string("HEARTBEAT_URL", &cfg.HeartbeatURL)
+	l.seconds("HEARTBEAT_INTERVAL", &cfg.HeartbeatInterval)
+
+	// Alert settings (interval in seconds)
+	l.bool("ALERT_ENABLED", &cfg.AlertEnabled)
+	l.seconds("ALERT_INTERVAL", &cfg.AlertInterval)
+	l.seconds("ALERT_COOLDOWN", &cfg.AlertCooldown)
+	l.float("ALERT_CPU_TEMP", &cfg.CPUTempThreshold)
+	l.float("ALERT_CPU_USAGE", &cfg.CPUUsageThreshold)
+	l.float("ALERT_MEMORY", &cfg.MemoryThreshold)
+	l.float("ALERT_DISK", &cfg.DiskThreshold)
+
+	// Wi-Fi
+	l.string("WIFI_INTERFACE", &cfg.WiFiInterface)
+	l.float("ALERT_WIFI_SIGNAL", &cfg.WiFiSignalThreshold)
+	l.int("ALERT_WIFI_RECONNECTS", &cfg.WiFiReconnectLimit)
+	l.seconds("ALERT_WIFI_WINDOW", &cfg.WiFiReconnectWindow)
+
+	// Wake-on-LAN
+	cfg.WOLTargets = l.wolTargets(cfg.WOLTargets)
+	l.seconds("WOL_WAIT_TIMEOUT", &cfg.WOLWaitTimeout)
+	l.int("WOL_RETRIES", &cfg.WOLRetries)
+	l.seconds("WOL_RETRY_INTERVAL", &cfg.WOLRetryInterval)
+	l.bool("WOL_TRACK_ENABLED", &cfg.WOLTrackEnabled)
+	l.seconds("WOL_TRACK_INTERVAL", &cfg.WOLTrackInterval)
+	l.int("WOL_TRACK_OFFLINE_CHECKS", &cfg.WOLTrackOfflineChecks)
+
+	// Public IP
+	l.bool("PUBLIC_IP_ENABLED", &cfg.PublicIPEnabled)
+	l.seconds("PUBLIC_IP_INTERVAL", &cfg.PublicIPInterval)
+	l.list("PUBLIC_IP_ENDPOINTS", &cfg.PublicIPEndpoints)
+	l.string("PUBLIC_IP_STUN", &cfg.PublicIPSTUN)
+	l.bool("PUBLIC_IP_IPV6", &cfg.PublicIPv6)
+
+	// LAN devices
+	l.bool("DEVICES_ENABLED", &cfg.DevicesEnabled)
+	l.seconds("DEVICES_INTERVAL", &cfg.DevicesInterval)
+	l.bool("DEVICES_SWEEP", &cfg.DevicesSweep)
+	l.seconds("DEVICES_OFFLINE_AFTER", &cfg.DevicesOfflineAfter)
+	l.string("OUI_FILE", &cfg.OUIFile)
+}
+
+// wolTargets đọc danh sách PC từ biến môi trường.
+// Mỗi tên trong WOL_TARGETS (vd: office,gaming) có các biến WOL_<NAME>_MAC, WOL_<NAME>_HOST,
+// WOL_<NAME>_BROADCAST, WOL_<NAME>_INTERFACE, WOL_<NAME>_PASSWORD...
+// WOL_MAC_ADDRESS/WOL_HOST/WOL_BROADCAST cũ vẫn được hỗ trợ như một PC tên WOL_NAME (mặc định: pc).
+// PC trùng tên với PC trong file cấu hình được ghi đè từng trường.
+func (l *envLoader) wolTargets(targets []WOLTarget) []WOLTarget {
+	if os.Getenv("WOL_MAC_ADDRESS") != "" {
+		targets = l.wolTarget(targets, getEnvOrDefault("WOL_NAME", "pc"), "WOL_", "WOL_MAC_ADDRESS")
+	}
+	for _, name := range splitList(os.Getenv("WOL_TARGETS")) {
+		prefix := "WOL_" + envKey(name) + "_"
+		targets = l.wolTarget(targets, name, prefix, prefix+"MAC")
+	}
+	return targets
+}
+
+// wolTarget ghi đè (hoặc thêm) một PC từ các biến môi trường có tiền tố prefix
+func (l *envLoader) wolTarget(targets []WOLTarget, name, prefix, macKey string) []WOLTarget {
+	i := -1
+	for j := range targets {
+		if strings.EqualFold(targets[j].Name, name) {
+			i = j
+			break
+		}
 	}
+	if i < 0 {
+		targets = append(targets, WOLTarget{Name: name})
+		i = len(targets) - 1
+	}
+
+	t := &targets[i]
+	l.string(macKey, &t.MAC)
+	l.string(prefix+"HOST", &t.Host)
+	l.string(prefix+"BROADCAST", &t.Broadcast)
+	l.string(prefix+"INTERFACE", &t.Interface)
+	l.string(prefix+"PASSWORD", &t.Password)
+	l.string(prefix+"MODE", &t.Mode)
+	l.ports(prefix+"SEND_PORTS", &t.SendPorts)
+	l.list(prefix+"CHECK", &t.CheckMethods)
+	l.ports(prefix+"PORTS", &t.Ports)
+	l.string(prefix+"AGENT_URL", &t.AgentURL)
+	l.string(prefix+"AGENT_SECRET", &t.AgentSecret)
+	return targets
+}
+
+// errorf ghi lại lỗi của biến môi trường key
+func (l *envLoader) errorf(key, format string, args ...interface{}) {
+	l.errs = append(l.errs, FieldError{Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *envLoader) string(key string, dst *string) {
+	if v := os.Getenv(key); v != "" {
+		*dst = v
+	}
+}
 
-	// Parse Alert Interval (in seconds)
-	if intervalStr := os.Getenv("ALERT_INTERVAL"); intervalStr != "" {
-		if interval, err := strconv.Atoi(intervalStr); err == nil && interval > 0 {
-			cfg.AlertInterval = time.Duration(interval) * time.Second
+func (l *envLoader) list(key string, dst *[]string) {
+	if v := os.Getenv(key); v != "" {
+		*dst = splitList(v)
+	}
+}
+
+func (l *envLoader) bool(key string, dst *bool) {
+	if v := os.Getenv(key); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			l.errorf(key, "%q không phải true/false", v)
+			return
 		}
+		*dst = b
 	}
+}
 
-	// Parse custom thresholds
-	if temp := os.Getenv("ALERT_CPU_TEMP"); temp != "" {
-		if v, err := strconv.ParseFloat(temp, 64); err == nil {
-			cfg.CPUTempThreshold = v
+func (l *envLoader) int(key string, dst *int) {
+	if v := os.Getenv(key); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			l.errorf(key, "%q không phải số nguyên", v)
+			return
 		}
+		*dst = n
 	}
-	if usage := os.Getenv("ALERT_CPU_USAGE"); usage != "" {
-		if v, err := strconv.ParseFloat(usage, 64); err == nil {
-			cfg.CPUUsageThreshold = v
+}
+
+func (l *envLoader) float(key string, dst *float64) {
+	if v := os.Getenv(key); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			l.errorf(key, "%q không phải số", v)
+			return
 		}
+		*dst = f
 	}
-	if mem := os.Getenv("ALERT_MEMORY"); mem != "" {
-		if v, err := strconv.ParseFloat(mem, 64); err == nil {
-			cfg.MemoryThreshold = v
+}
+
+// seconds đọc số giây (vd: 30) hoặc khoảng thời gian (vd: 30s, 5m)
+func (l *envLoader) seconds(key string, dst *time.Duration) {
+	if v := os.Getenv(key); v != "" {
+		d, err := parseDuration(v)
+		if err != nil {
+			l.errorf(key, "%q không phải số giây hoặc khoảng thời gian (vd: 30, 5m)", v)
+			return
 		}
+		*dst = d
 	}
-	if disk := os.Getenv("ALERT_DISK"); disk != "" {
-		if v, err := strconv.ParseFloat(disk, 64); err == nil {
-			cfg.DiskThreshold = v
+}
+
+func (l *envLoader) ports(key string, dst *[]int) {
+	if v := os.Getenv(key); v != "" {
+		ports, err := parsePorts(v)
+		if err != nil {
+			l.errorf(key, "%v", err)
+			return
 		}
+		*dst = ports
 	}
+}
 
-	// Parse allowed users from comma-separated string
-	// Example: ALLOWED_USERS=123456789,987654321
-	allowedStr := os.Getenv("ALLOWED_USERS")
-	if allowedStr != "" {
-		for _, idStr := range strings.Split(allowedStr, ",") {
-			idStr = strings.TrimSpace(idStr)
-			if id, err := strconv.ParseInt(idStr, 10, 64); err == nil {
-				cfg.AllowedUsers = append(cfg.AllowedUsers, id)
+func (l *envLoader) ids(key string, dst *[]int64) {
+	if v := os.Getenv(key); v != "" {
+		var ids []int64
+		for _, item := range splitList(v) {
+			id, err := strconv.ParseInt(item, 10, 64)
+			if err != nil {
+				l.errorf(key, "%q không phải User ID", item)
+				return
 			}
+			ids = append(ids, id)
+		}
+		*dst = ids
+	}
+}
+
+// splitList tách chuỗi phân cách bởi dấu phẩy, bỏ phần tử rỗng
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parsePorts parse danh sách port dạng "445,3389"
+func parsePorts(s string) ([]int, error) {
+	var ports []int
+	for _, item := range splitList(s) {
+		port, err := strconv.Atoi(item)
+		if err != nil || port <= 0 || port >= 65536 {
+			return nil, fmt.Errorf("port không hợp lệ: %q", item)
 		}
+		ports = append(ports, port)
 	}
+	return ports, nil
+}
 
-	return cfg
+// parseDuration parse số giây (vd: 30) hoặc khoảng thời gian dạng Go (vd: 30s, 5m, 1h30m)
+func parseDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if secs, err := strconv.Atoi(s); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// envKey chuyển tên (vd: office-pc) thành dạng dùng trong tên biến môi trường (OFFICE_PC)
+func envKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// FindWOLTarget tìm PC theo tên (không phân biệt hoa thường)
+func (c *Config) FindWOLTarget(name string) (WOLTarget, bool) {
+	for _, t := range c.WOLTargets {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return WOLTarget{}, false
 }
 
 // getEnvOrDefault trả về giá trị env hoặc giá trị mặc định nếu env không được set
@@ -99,18 +461,26 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
-// IsUserAllowed checks if a user ID is in the whitelist
-// If whitelist is empty, all users are allowed
-func (c *Config) IsUserAllowed(userID int64) bool {
-	// Nếu không set whitelist, cho phép tất cả
-	if len(c.AllowedUsers) == 0 {
-		return true
-	}
-
-	for _, id := range c.AllowedUsers {
-		if id == userID {
+// NotifyStartup kiểm tra có thông báo khi bot khởi động vì lý do kind (first, restart, crash, reboot) không
+func (c *Config) NotifyStartup(kind string) bool {
+	for _, k := range c.StartupNotify {
+		if k == kind || k == "all" {
 			return true
 		}
 	}
 	return false
 }
+
+// DefaultLang trả về ngôn ngữ mặc định của tin nhắn
+func (c *Config) DefaultLang() i18n.Lang {
+	if l, ok := i18n.Parse(c.Language); ok {
+		return l
+	}
+	return i18n.Default
+}
+
+// IsUserAllowed kiểm tra user có quyền dùng bot không (bất kỳ role nào).
+// Không cấu hình user nào thì từ chối tất cả.
+func (c *Config) IsUserAllowed(userID int64) bool {
+	return c.RoleOf(userID) != RoleNone
+}
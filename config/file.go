@@ -0,0 +1,348 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration là khoảng thời gian trong file cấu hình: số giây (30) hoặc dạng Go (30s, 5m, 1h30m)
+type Duration time.Duration
+
+// UnmarshalYAML parse Duration từ YAML
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	v, err := parseDuration(s)
+	if err != nil {
+		return &yaml.TypeError{Errors: []string{
+			fmt.Sprintf("line %d: %q không phải số giây hoặc khoảng thời gian (vd: 30, 5m)", value.Line, s),
+		}}
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// fileConfig là cấu trúc của file cấu hình YAML, ví dụ:
+//
+//	bot_token: "123:abc"
+//	users:
+//	  admins: [123456789]
+//	  viewers: [987654321]
+//	timezone: Asia/Ho_Chi_Minh
+//	bot_mode: webhook
+//	webhook:
+//	  url: https://pi.example.com/telegram
+//	  listen: 127.0.0.1:8080
+//	alert:
+//	  enabled: true
+//	  interval: 30s
+//	  cpu_temp: 70
+//	wol:
+//	  targets:
+//	    - name: office-pc
+//	      mac: AA:BB:CC:DD:EE:01
+//	      host: 192.168.1.101
+//	      check: [icmp, tcp]
+//	      ports: [3389]
+type fileConfig struct {
+	BotToken     string  `yaml:"bot_token"`
+	AllowedUsers []int64 `yaml:"allowed_users"`
+	DataDir      string  `yaml:"data_dir"`
+	Timezone     string  `yaml:"timezone"`
+	Language     string  `yaml:"language"`
+	BotMode      string  `yaml:"bot_mode"`
+
+	Webhook struct {
+		URL        string `yaml:"url"`
+		Listen     string `yaml:"listen"`
+		Secret     string `yaml:"secret"`
+		CertFile   string `yaml:"cert_file"`
+		KeyFile    string `yaml:"key_file"`
+		SelfSigned bool   `yaml:"self_signed"`
+	} `yaml:"webhook"`
+
+	Startup struct {
+		Notify []string `yaml:"notify"`
+	} `yaml:"startup"`
+
+	Shutdown struct {
+		Timeout Duration `yaml:"timeout"`
+		Notify  bool     `yaml:"notify"`
+	} `yaml:"shutdown"`
+
+	Heartbeat struct {
+		URL      string   `yaml:"url"`
+		Interval Duration `yaml:"interval"`
+	} `yaml:"heartbeat"`
+
+	Users struct {
+		Admins    []int64 `yaml:"admins"`
+		Operators []int64 `yaml:"operators"`
+		Viewers   []int64 `yaml:"viewers"`
+	} `yaml:"users"`
+
+	Alert struct {
+		Enabled  bool     `yaml:"enabled"`
+		Interval Duration `yaml:"interval"`
+		Cooldown Duration `yaml:"cooldown"`
+		CPUTemp  float64  `yaml:"cpu_temp"`
+		CPUUsage float64  `yaml:"cpu_usage"`
+		Memory   float64  `yaml:"memory"`
+		Disk     float64  `yaml:"disk"`
+	} `yaml:"alert"`
+
+	WiFi struct {
+		Interface  string   `yaml:"interface"`
+		Signal     float64  `yaml:"signal"`
+		Reconnects int      `yaml:"reconnects"`
+		Window     Duration `yaml:"window"`
+	} `yaml:"wifi"`
+
+	WOL struct {
+		Targets       []WOLTarget `yaml:"targets"`
+		WaitTimeout   Duration    `yaml:"wait_timeout"`
+		Retries       int         `yaml:"retries"`
+		RetryInterval Duration    `yaml:"retry_interval"`
+
+		Track struct {
+			Enabled       bool     `yaml:"enabled"`
+			Interval      Duration `yaml:"interval"`
+			OfflineChecks int      `yaml:"offline_checks"`
+		} `yaml:"track"`
+	} `yaml:"wol"`
+
+	PublicIP struct {
+		Enabled   bool     `yaml:"enabled"`
+		Interval  Duration `yaml:"interval"`
+		Endpoints []string `yaml:"endpoints"`
+		STUN      string   `yaml:"stun"`
+		IPv6      bool     `yaml:"ipv6"`
+	} `yaml:"public_ip"`
+
+	Devices struct {
+		Enabled      bool     `yaml:"enabled"`
+		Interval     Duration `yaml:"interval"`
+		Sweep        bool     `yaml:"sweep"`
+		OfflineAfter Duration `yaml:"offline_after"`
+		OUIFile      string   `yaml:"oui_file"`
+	} `yaml:"devices"`
+}
+
+// loadFile đọc file cấu hình YAML và ghi đè các giá trị có trong file lên cfg.
+// Key không được hỗ trợ và giá trị sai kiểu đều được báo lỗi kèm tên key.
+func loadFile(path string, cfg *Config) ValidationError {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ValidationError{{Key: path, Message: fmt.Sprintf("không đọc được file cấu hình: %v", err)}}
+	}
+
+	// Điền giá trị hiện tại trước, key không có trong file giữ nguyên
+	f := toFile(cfg)
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err = dec.Decode(&f)
+	if err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return ValidationError{{Key: path, Message: err.Error()}}
+		}
+
+		var root yaml.Node
+		yaml.Unmarshal(data, &root)
+		keys := make(map[int]string)
+		indexKeys(&root, "", keys)
+
+		var errs ValidationError
+		for _, msg := range typeErr.Errors {
+			errs = append(errs, yamlFieldError(path, msg, keys))
+		}
+
+		// Các key còn lại vẫn được áp dụng để kiểm tra tiếp và báo đủ lỗi
+		f.apply(cfg)
+		return errs
+	}
+
+	f.apply(cfg)
+	return nil
+}
+
+// toFile chuyển Config sang fileConfig
+func toFile(cfg *Config) fileConfig {
+	var f fileConfig
+	f.BotToken = cfg.BotToken
+	f.AllowedUsers = cfg.AllowedUsers
+	f.DataDir = cfg.DataDir
+	f.Timezone = cfg.Timezone
+	f.Language = cfg.Language
+	f.BotMode = cfg.BotMode
+
+	f.Webhook.URL = cfg.WebhookURL
+	f.Webhook.Listen = cfg.WebhookListen
+	f.Webhook.Secret = cfg.WebhookSecret
+	f.Webhook.CertFile = cfg.WebhookCertFile
+	f.Webhook.KeyFile = cfg.WebhookKeyFile
+	f.Webhook.SelfSigned = cfg.WebhookSelfSigned
+
+	f.Startup.Notify = cfg.StartupNotify
+	f.Shutdown.Timeout = Duration(cfg.ShutdownTimeout)
+	f.Shutdown.Notify = cfg.ShutdownNotify
+	f.Heartbeat.URL = cfg.HeartbeatURL
+	f.Heartbeat.Interval = Duration(cfg.HeartbeatInterval)
+
+	f.Users.Admins = cfg.Admins
+	f.Users.Operators = cfg.Operators
+	f.Users.Viewers = cfg.Viewers
+
+	f.Alert.Enabled = cfg.AlertEnabled
+	f.Alert.Interval = Duration(cfg.AlertInterval)
+	f.Alert.Cooldown = Duration(cfg.AlertCooldown)
+	f.Alert.CPUTemp = cfg.CPUTempThreshold
+	f.Alert.CPUUsage = cfg.CPUUsageThreshold
+	f.Alert.Memory = cfg.MemoryThreshold
+	f.Alert.Disk = cfg.DiskThreshold
+
+	f.WiFi.Interface = cfg.WiFiInterface
+	f.WiFi.Signal = cfg.WiFiSignalThreshold
+	f.WiFi.Reconnects = cfg.WiFiReconnectLimit
+	f.WiFi.Window = Duration(cfg.WiFiReconnectWindow)
+
+	f.WOL.Targets = cfg.WOLTargets
+	f.WOL.WaitTimeout = Duration(cfg.WOLWaitTimeout)
+	f.WOL.Retries = cfg.WOLRetries
+	f.WOL.RetryInterval = Duration(cfg.WOLRetryInterval)
+	f.WOL.Track.Enabled = cfg.WOLTrackEnabled
+	f.WOL.Track.Interval = Duration(cfg.WOLTrackInterval)
+	f.WOL.Track.OfflineChecks = cfg.WOLTrackOfflineChecks
+
+	f.PublicIP.Enabled = cfg.PublicIPEnabled
+	f.PublicIP.Interval = Duration(cfg.PublicIPInterval)
+	f.PublicIP.Endpoints = cfg.PublicIPEndpoints
+	f.PublicIP.STUN = cfg.PublicIPSTUN
+	f.PublicIP.IPv6 = cfg.PublicIPv6
+
+	f.Devices.Enabled = cfg.DevicesEnabled
+	f.Devices.Interval = Duration(cfg.DevicesInterval)
+	f.Devices.Sweep = cfg.DevicesSweep
+	f.Devices.OfflineAfter = Duration(cfg.DevicesOfflineAfter)
+	f.Devices.OUIFile = cfg.OUIFile
+	return f
+}
+
+// apply ghi giá trị từ fileConfig vào Config
+func (f *fileConfig) apply(cfg *Config) {
+	cfg.BotToken = f.BotToken
+	cfg.AllowedUsers = f.AllowedUsers
+	cfg.DataDir = f.DataDir
+	cfg.Timezone = f.Timezone
+	cfg.Language = f.Language
+	cfg.BotMode = f.BotMode
+
+	cfg.WebhookURL = f.Webhook.URL
+	cfg.WebhookListen = f.Webhook.Listen
+	cfg.WebhookSecret = f.Webhook.Secret
+	cfg.WebhookCertFile = f.Webhook.CertFile
+	cfg.WebhookKeyFile = f.Webhook.KeyFile
+	cfg.WebhookSelfSigned = f.Webhook.SelfSigned
+
+	cfg.StartupNotify = f.Startup.Notify
+	cfg.ShutdownTimeout = time.Duration(f.Shutdown.Timeout)
+	cfg.ShutdownNotify = f.Shutdown.Notify
+	cfg.HeartbeatURL = f.Heartbeat.URL
+	cfg.HeartbeatInterval = time.Duration(f.Heartbeat.Interval)
+
+	cfg.Admins = f.Users.Admins
+	cfg.Operators = f.Users.Operators
+	cfg.Viewers = f.Users.Viewers
+
+	cfg.AlertEnabled = f.Alert.Enabled
+	cfg.AlertInterval = time.Duration(f.Alert.Interval)
+	cfg.AlertCooldown = time.Duration(f.Alert.Cooldown)
+	cfg.CPUTempThreshold = f.Alert.CPUTemp
+	cfg.CPUUsageThreshold = f.Alert.CPUUsage
+	cfg.MemoryThreshold = f.Alert.Memory
+	cfg.DiskThreshold = f.Alert.Disk
+
+	cfg.WiFiInterface = f.WiFi.Interface
+	cfg.WiFiSignalThreshold = f.WiFi.Signal
+	cfg.WiFiReconnectLimit = f.WiFi.Reconnects
+	cfg.WiFiReconnectWindow = time.Duration(f.WiFi.Window)
+
+	cfg.WOLTargets = f.WOL.Targets
+	cfg.WOLWaitTimeout = time.Duration(f.WOL.WaitTimeout)
+	cfg.WOLRetries = f.WOL.Retries
+	cfg.WOLRetryInterval = time.Duration(f.WOL.RetryInterval)
+	cfg.WOLTrackEnabled = f.WOL.Track.Enabled
+	cfg.WOLTrackInterval = time.Duration(f.WOL.Track.Interval)
+	cfg.WOLTrackOfflineChecks = f.WOL.Track.OfflineChecks
+
+	cfg.PublicIPEnabled = f.PublicIP.Enabled
+	cfg.PublicIPInterval = time.Duration(f.PublicIP.Interval)
+	cfg.PublicIPEndpoints = f.PublicIP.Endpoints
+	cfg.PublicIPSTUN = f.PublicIP.STUN
+	cfg.PublicIPv6 = f.PublicIP.IPv6
+
+	cfg.DevicesEnabled = f.Devices.Enabled
+	cfg.DevicesInterval = time.Duration(f.Devices.Interval)
+	cfg.DevicesSweep = f.Devices.Sweep
+	cfg.DevicesOfflineAfter = time.Duration(f.Devices.OfflineAfter)
+	cfg.OUIFile = f.Devices.OUIFile
+}
+
+// indexKeys ghi lại key (dạng alert.cpu_temp, wol.targets[0].mac) của từng dòng trong file YAML
+func indexKeys(node *yaml.Node, path string, keys map[int]string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			indexKeys(child, path, keys)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if path != "" {
+				key = path + "." + key
+			}
+			keys[node.Content[i].Line] = key
+			indexKeys(node.Content[i+1], key, keys)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			key := fmt.Sprintf("%s[%d]", path, i)
+			if _, ok := keys[child.Line]; !ok {
+				keys[child.Line] = key
+			}
+			indexKeys(child, key, keys)
+		}
+	}
+}
+
+// yamlFieldError chuyển lỗi của yaml.v3 (dạng "line 5: ...") thành lỗi kèm tên key
+func yamlFieldError(path, msg string, keys map[int]string) FieldError {
+	var line int
+	if _, err := fmt.Sscanf(msg, "line %d:", &line); err != nil {
+		return FieldError{Key: path, Message: msg}
+	}
+
+	key, ok := keys[line]
+	if !ok {
+		key = path + ":" + strconv.Itoa(line)
+	}
+	msg = strings.TrimSpace(msg[strings.Index(msg, ":")+1:])
+
+	if strings.HasPrefix(msg, "field ") && strings.Contains(msg, " not found in type ") {
+		msg = "key không được hỗ trợ"
+	} else if strings.HasPrefix(msg, "cannot unmarshal ") {
+		msg = "sai kiểu dữ liệu (" + strings.TrimPrefix(msg, "cannot unmarshal ") + ")"
+	}
+	return FieldError{Key: key, Message: msg}
+}
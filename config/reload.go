@@ -0,0 +1,243 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Holder giữ cấu hình hiện tại, cho phép thay cấu hình mới một cách atomic khi reload
+type Holder struct {
+	cfg atomic.Pointer[Config]
+}
+
+// NewHolder tạo Holder với cấu hình ban đầu
+func NewHolder(cfg *Config) *Holder {
+	h := &Holder{}
+	h.cfg.Store(cfg)
+	return h
+}
+
+// Get trả về cấu hình hiện tại. Không sửa Config trả về, hãy Load bản mới rồi Set.
+func (h *Holder) Get() *Config {
+	return h.cfg.Load()
+}
+
+// Set thay cấu hình hiện tại
+func (h *Holder) Set(cfg *Config) {
+	h.cfg.Store(cfg)
+}
+
+// Change là một thay đổi cấu hình sau khi reload
+type Change struct {
+	Key     string
+	Old     string
+	New     string
+	Restart bool // Chỉ có hiệu lực sau khi khởi động lại
+}
+
+// String mô tả thay đổi (vd: "alert.cpu_temp: 70 → 75")
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s → %s", c.Key, c.Old, c.New)
+}
+
+// liveKeys là các key được áp dụng ngay khi reload, các key khác cần khởi động lại
+var liveKeys = []string{
+	"allowed_users", "users", "shutdown", "language",
+	"alert.interval", "alert.cooldown",
+	"alert.cpu_temp", "alert.cpu_usage", "alert.memory", "alert.disk",
+	"wifi.signal", "wifi.reconnects", "wifi.window",
+	"wol.targets", "wol.wait_timeout", "wol.retries", "wol.retry_interval",
+}
+
+// secretKeys là các key không hiển thị giá trị khi báo thay đổi
+var secretKeys = []string{"bot_token", "password", "agent_secret", "secret", "heartbeat.url"}
+
+// Diff so sánh hai cấu hình và trả về danh sách thay đổi, sắp xếp theo key
+func Diff(old, new *Config) []Change {
+	before := flatten(old)
+	after := flatten(new)
+
+	keys := make(map[string]bool)
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+
+	var changes []Change
+	for k := range keys {
+		o, n := before[k], after[k]
+		if o == n {
+			continue
+		}
+		// PC thêm mới hoặc bị xoá chỉ báo một dòng (wol.targets[tên]: MAC)
+		if i := strings.Index(k, "]."); i >= 0 && (before[k[:i+1]] == "" || after[k[:i+1]] == "") {
+			continue
+		}
+		if isSecretKey(k) {
+			o, n = maskSecret(o), maskSecret(n)
+			if o == n {
+				n = "*** (đã đổi)"
+			}
+		}
+		changes = append(changes, Change{Key: k, Old: valueOrNone(o), New: valueOrNone(n), Restart: !isLiveKey(k)})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// flatten chuyển cấu hình thành map key (dạng trong file YAML) -> giá trị dạng chuỗi
+func flatten(cfg *Config) map[string]string {
+	out := make(map[string]string)
+	f := toFile(cfg)
+	flattenValue(reflect.ValueOf(f), "", out)
+	return out
+}
+
+func flattenValue(v reflect.Value, prefix string, out map[string]string) {
+	switch {
+	case v.Type() == reflect.TypeOf(Duration(0)):
+		out[prefix] = time.Duration(v.Int()).String()
+
+	case v.Kind() == reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			tag := strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")[0]
+			if tag == "" || tag == "-" {
+				continue
+			}
+			key := tag
+			if prefix != "" {
+				key = prefix + "." + tag
+			}
+			flattenValue(v.Field(i), key, out)
+		}
+
+	case v.Type() == reflect.TypeOf([]WOLTarget(nil)):
+		// PC được so sánh theo tên để thêm/bớt một PC không làm đổi các PC còn lại
+		for i := 0; i < v.Len(); i++ {
+			t := v.Index(i).Interface().(WOLTarget)
+			key := fmt.Sprintf("%s[%s]", prefix, t.Name)
+			out[key] = t.MAC
+			flattenValue(reflect.ValueOf(t), key, out)
+		}
+
+	case v.Kind() == reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		out[prefix] = strings.Join(items, ",")
+
+	default:
+		out[prefix] = fmt.Sprint(v.Interface())
+	}
+}
+
+func isLiveKey(key string) bool {
+	for _, k := range liveKeys {
+		if key == k || strings.HasPrefix(key, k+".") || strings.HasPrefix(key, k+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func isSecretKey(key string) bool {
+	for _, k := range secretKeys {
+		if key == k || strings.HasSuffix(key, "."+k) {
+			return true
+		}
+	}
+	return false
+}
+
+func maskSecret(v string) string {
+	if v == "" {
+		return ""
+	}
+	return "***"
+}
+
+func valueOrNone(v string) string {
+	if v == "" {
+		return "(trống)"
+	}
+	return v
+}
+
+// Watch kiểm tra file cấu hình định kỳ và gọi onChange khi file thay đổi (mtime hoặc kích thước).
+// Dùng polling thay cho inotify để hoạt động cả khi file được mount vào Docker hoặc bị thay bằng file mới.
+// Dừng khi ctx bị huỷ.
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	stat := func() (time.Time, int64) {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, -1
+		}
+		return info.ModTime(), info.Size()
+	}
+
+	lastMod, lastSize := stat()
+	log.Printf("👀 Watching config file %s (interval: %v)", path, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		mod, size := stat()
+		if size < 0 || (mod.Equal(lastMod) && size == lastSize) {
+			continue
+		}
+		lastMod, lastSize = mod, size
+		onChange()
+	}
+}
+
+// ApplyLive trả về bản sao của cfg đang chạy với các giá trị có thể đổi ngay (liveKeys) lấy từ next.
+// Các giá trị khác giữ nguyên đến khi khởi động lại để trạng thái hiển thị khớp với thực tế.
+func ApplyLive(cfg, next *Config) *Config {
+	applied := *cfg
+	applied.AllowedUsers = next.AllowedUsers
+	applied.Admins = next.Admins
+	applied.Operators = next.Operators
+	applied.Viewers = next.Viewers
+	applied.UserRoles = next.UserRoles
+
+	applied.Language = next.Language
+
+	applied.ShutdownTimeout = next.ShutdownTimeout
+	applied.ShutdownNotify = next.ShutdownNotify
+
+	applied.AlertInterval = next.AlertInterval
+	applied.AlertCooldown = next.AlertCooldown
+
+	applied.CPUTempThreshold = next.CPUTempThreshold
+	applied.CPUUsageThreshold = next.CPUUsageThreshold
+	applied.MemoryThreshold = next.MemoryThreshold
+	applied.DiskThreshold = next.DiskThreshold
+
+	applied.WiFiSignalThreshold = next.WiFiSignalThreshold
+	applied.WiFiReconnectLimit = next.WiFiReconnectLimit
+	applied.WiFiReconnectWindow = next.WiFiReconnectWindow
+
+	applied.WOLTargets = next.WOLTargets
+	applied.WOLWaitTimeout = next.WOLWaitTimeout
+	applied.WOLRetries = next.WOLRetries
+	applied.WOLRetryInterval = next.WOLRetryInterval
+	return &applied
+}
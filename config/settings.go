@@ -0,0 +1,250 @@
+package config
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pi-monitor/i18n"
+)
+
+// Setting là một giá trị cảnh báo có thể đổi từ Telegram bằng /set hoặc menu cài đặt.
+// Tên hiển thị của Setting nằm trong catalog i18n với key "setting.<Key>".
+type Setting struct {
+	Key      string
+	Unit     string  // Đơn vị hiển thị (°C, %, dBm)
+	Min, Max float64 // Khoảng giá trị hợp lệ (giây với Duration)
+	Step     float64 // Bước tăng/giảm trong menu
+	Duration bool    // Giá trị là khoảng thời gian, lưu bằng giây
+	Count    bool    // Giá trị là số lần (số nguyên, hiển thị kèm "lần")
+
+	Get func(*Config) float64
+	Set func(*Config, float64)
+}
+
+// Settings là danh sách giá trị có thể đổi khi đang chạy
+var Settings = []Setting{
+	{
+		Key: "cpu_temp", Unit: "°C", Min: 30, Max: 120, Step: 1,
+		Get: func(c *Config) float64 { return c.CPUTempThreshold },
+		Set: func(c *Config, v float64) { c.CPUTempThreshold = v },
+	},
+	{
+		Key: "cpu_usage", Unit: "%", Min: 1, Max: 100, Step: 1,
+		Get: func(c *Config) float64 { return c.CPUUsageThreshold },
+		Set: func(c *Config, v float64) { c.CPUUsageThreshold = v },
+	},
+	{
+		Key: "memory", Unit: "%", Min: 1, Max: 100, Step: 1,
+		Get: func(c *Config) float64 { return c.MemoryThreshold },
+		Set: func(c *Config, v float64) { c.MemoryThreshold = v },
+	},
+	{
+		Key: "disk", Unit: "%", Min: 1, Max: 100, Step: 1,
+		Get: func(c *Config) float64 { return c.DiskThreshold },
+		Set: func(c *Config, v float64) { c.DiskThreshold = v },
+	},
+	{
+		Key: "wifi_signal", Unit: "dBm", Min: -120, Max: 0, Step: 1,
+		Get: func(c *Config) float64 { return c.WiFiSignalThreshold },
+		Set: func(c *Config, v float64) { c.WiFiSignalThreshold = v },
+	},
+	{
+		Key: "wifi_reconnects", Count: true, Min: 0, Max: 100, Step: 1,
+		Get: func(c *Config) float64 { return float64(c.WiFiReconnectLimit) },
+		Set: func(c *Config, v float64) { c.WiFiReconnectLimit = int(v) },
+	},
+	{
+		Key: "wifi_window", Min: 60, Max: 86400, Step: 60, Duration: true,
+		Get: func(c *Config) float64 { return c.WiFiReconnectWindow.Seconds() },
+		Set: func(c *Config, v float64) { c.WiFiReconnectWindow = time.Duration(v) * time.Second },
+	},
+	{
+		Key: "interval", Min: 5, Max: 3600, Step: 5, Duration: true,
+		Get: func(c *Config) float64 { return c.AlertInterval.Seconds() },
+		Set: func(c *Config, v float64) { c.AlertInterval = time.Duration(v) * time.Second },
+	},
+	{
+		Key: "cooldown", Min: 0, Max: 86400, Step: 60, Duration: true,
+		Get: func(c *Config) float64 { return c.AlertCooldown.Seconds() },
+		Set: func(c *Config, v float64) { c.AlertCooldown = time.Duration(v) * time.Second },
+	},
+}
+
+// FindSetting tìm Setting theo key
+func FindSetting(key string) (Setting, bool) {
+	for _, s := range Settings {
+		if s.Key == strings.ToLower(key) {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Format hiển thị giá trị kèm đơn vị (vd: 75°C, 5m0s)
+func (s Setting) Format(v float64) string {
+	if s.Duration {
+		return (time.Duration(v) * time.Second).String()
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64) + s.Unit
+}
+
+// Parse đọc giá trị người dùng nhập (số, hoặc 30s/5m với Duration) và kiểm tra khoảng hợp lệ
+func (s Setting) Parse(text string) (float64, error) {
+	var v float64
+	if s.Duration {
+		d, err := parseDuration(text)
+		if err != nil {
+			return 0, i18n.Errorf("setting.err.duration", text)
+		}
+		v = d.Seconds()
+	} else {
+		f, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(text), s.Unit), 64)
+		if err != nil {
+			return 0, i18n.Errorf("setting.err.number", text)
+		}
+		v = f
+	}
+	return v, s.check(v)
+}
+
+// check kiểm tra giá trị nằm trong khoảng cho phép
+func (s Setting) check(v float64) error {
+	if math.IsNaN(v) || v < s.Min || v > s.Max {
+		return i18n.Errorf("setting.err.range", s.Key, s.Format(s.Min), s.Format(s.Max))
+	}
+	if (s.Duration || s.Count) && v != math.Trunc(v) {
+		return i18n.Errorf("setting.err.integer", s.Key)
+	}
+	return nil
+}
+
+// Overrides là các giá trị đã đổi bằng /set, lưu trong file (vd: data/settings.json).
+// Giá trị trong file này được ưu tiên hơn biến môi trường và file cấu hình.
+type Overrides struct {
+	mu     sync.Mutex
+	path   string
+	values map[string]float64
+	base   *Config // Cấu hình gốc (chưa áp dụng Overrides) lần gần nhất
+}
+
+// LoadOverrides đọc các giá trị đã lưu (nếu có)
+func LoadOverrides(path string) *Overrides {
+	o := &Overrides{path: path, values: make(map[string]float64)}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &o.values); err != nil {
+			log.Printf("⚠️ Cannot parse settings %s: %v", path, err)
+			o.values = make(map[string]float64)
+		}
+	}
+	return o
+}
+
+// Apply trả về bản sao của base với các giá trị đã đổi bằng /set.
+// Giá trị không còn hợp lệ được bỏ qua.
+func (o *Overrides) Apply(base *Config) *Config {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.base = base
+	cfg := *base
+	for key, v := range o.values {
+		s, ok := FindSetting(key)
+		if !ok {
+			log.Printf("⚠️ Ignoring unknown setting %q", key)
+			continue
+		}
+		if err := s.check(v); err != nil {
+			log.Printf("⚠️ Ignoring setting: %v", err)
+			continue
+		}
+		s.Set(&cfg, v)
+	}
+	return &cfg
+}
+
+// IsSet cho biết key đã được đổi bằng /set chưa
+func (o *Overrides) IsSet(key string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, ok := o.values[key]
+	return ok
+}
+
+// Default trả về giá trị từ cấu hình gốc (biến môi trường/file cấu hình) của một Setting
+func (o *Overrides) Default(s Setting) (float64, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.base == nil {
+		return 0, false
+	}
+	return s.Get(o.base), true
+}
+
+// Set lưu giá trị mới và trả về bản sao của cfg với giá trị đó
+func (o *Overrides) Set(cfg *Config, s Setting, v float64) (*Config, error) {
+	if err := s.check(v); err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	old, had := o.values[s.Key]
+	o.values[s.Key] = v
+	if err := o.save(); err != nil {
+		if had {
+			o.values[s.Key] = old
+		} else {
+			delete(o.values, s.Key)
+		}
+		return nil, i18n.Errorf("setting.err.save", err)
+	}
+
+	next := *cfg
+	s.Set(&next, v)
+	return &next, nil
+}
+
+// Reset bỏ giá trị đã đổi, trả về bản sao của cfg với giá trị từ cấu hình gốc
+func (o *Overrides) Reset(cfg *Config, s Setting) (*Config, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	old, ok := o.values[s.Key]
+	delete(o.values, s.Key)
+	if err := o.save(); err != nil {
+		if ok {
+			o.values[s.Key] = old
+		}
+		return nil, i18n.Errorf("setting.err.save", err)
+	}
+
+	next := *cfg
+	if o.base != nil {
+		s.Set(&next, s.Get(o.base))
+	}
+	return &next, nil
+}
+
+// save ghi các giá trị xuống file (gọi khi đang giữ lock)
+func (o *Overrides) save() error {
+	if o.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(o.values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(o.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(o.path, data, 0o644)
+}
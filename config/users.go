@@ -0,0 +1,204 @@
+package config
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"pi-monitor/i18n"
+)
+
+// Role là quyền của người dùng. Role cao hơn có tất cả quyền của role thấp hơn.
+type Role string
+
+const (
+	RoleNone     Role = ""
+	RoleViewer   Role = "viewer"   // Xem thông tin: /pi, /ip, /devices, /alert...
+	RoleOperator Role = "operator" // Thêm /wake, /schedule, đặt tên thiết bị
+	RoleAdmin    Role = "admin"    // Thêm /sleep, /shutdown, /set, /user
+)
+
+// roleRanks là thứ tự của các role
+var roleRanks = map[Role]int{RoleNone: 0, RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// ParseRole đọc role từ chuỗi (admin, operator, viewer)
+func ParseRole(s string) (Role, error) {
+	r := Role(strings.ToLower(strings.TrimSpace(s)))
+	if r == RoleNone || roleRanks[r] == 0 {
+		return RoleNone, i18n.Errorf("user.err.role", s)
+	}
+	return r, nil
+}
+
+// Allows kiểm tra role có đủ quyền của required không
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] >= roleRanks[required]
+}
+
+// RoleOf trả về role của user: role đổi bằng /user, rồi admins (và allowed_users cũ), operators, viewers
+func (c *Config) RoleOf(userID int64) Role {
+	if r, ok := c.UserRoles[userID]; ok {
+		return r
+	}
+	switch {
+	case containsID(c.Admins, userID), containsID(c.AllowedUsers, userID):
+		return RoleAdmin
+	case containsID(c.Operators, userID):
+		return RoleOperator
+	case containsID(c.Viewers, userID):
+		return RoleViewer
+	}
+	return RoleNone
+}
+
+// Users trả về ID của tất cả người dùng có quyền (nhận thông báo), sắp xếp tăng dần
+func (c *Config) Users() []int64 {
+	seen := make(map[int64]bool)
+	for _, list := range [][]int64{c.AllowedUsers, c.Admins, c.Operators, c.Viewers} {
+		for _, id := range list {
+			seen[id] = true
+		}
+	}
+	for id := range c.UserRoles {
+		seen[id] = true
+	}
+
+	var ids []int64
+	for id := range seen {
+		if c.RoleOf(id) != RoleNone {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// UserStore là các thay đổi quyền bằng /user, lưu trong file (vd: data/users.json).
+// Quyền trong file này được ưu tiên hơn biến môi trường và file cấu hình.
+type UserStore struct {
+	mu    sync.Mutex
+	path  string
+	roles map[int64]Role
+}
+
+// LoadUsers đọc các quyền đã lưu (nếu có)
+func LoadUsers(path string) *UserStore {
+	u := &UserStore{path: path, roles: make(map[int64]Role)}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &u.roles); err != nil {
+			log.Printf("⚠️ Cannot parse users %s: %v", path, err)
+			u.roles = make(map[int64]Role)
+		}
+	}
+	for id, r := range u.roles {
+		if r != RoleNone && roleRanks[r] == 0 {
+			log.Printf("⚠️ Ignoring invalid role %q of user %d", r, id)
+			delete(u.roles, id)
+		}
+	}
+	return u
+}
+
+// Apply trả về bản sao của base với các quyền đã đổi bằng /user
+func (u *UserStore) Apply(base *Config) *Config {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	cfg := *base
+	cfg.UserRoles = u.copyRoles()
+	return &cfg
+}
+
+// SetRole thêm user hoặc đổi role, trả về bản sao của cfg với quyền mới
+func (u *UserStore) SetRole(cfg *Config, userID int64, role Role) (*Config, error) {
+	return u.update(cfg, userID, role)
+}
+
+// Remove xoá quyền của user, trả về bản sao của cfg với quyền mới
+func (u *UserStore) Remove(cfg *Config, userID int64) (*Config, error) {
+	if cfg.RoleOf(userID) == RoleNone {
+		return nil, i18n.Errorf("user.err.not_found", userID)
+	}
+	return u.update(cfg, userID, RoleNone)
+}
+
+// update đổi role của user, không cho phép xoá hoặc hạ quyền admin cuối cùng
+func (u *UserStore) update(cfg *Config, userID int64, role Role) (*Config, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	next := *cfg
+	next.UserRoles = u.copyRoles()
+	next.UserRoles[userID] = role
+
+	// User không có trong cấu hình thì không cần lưu dòng "đã xoá"
+	if role == RoleNone {
+		plain := next
+		plain.UserRoles = nil
+		if plain.RoleOf(userID) == RoleNone {
+			delete(next.UserRoles, userID)
+		}
+	}
+
+	if !next.hasAdmin() {
+		return nil, i18n.Errorf("user.err.last_admin")
+	}
+
+	old := u.roles
+	u.roles = next.UserRoles
+	if err := u.save(); err != nil {
+		u.roles = old
+		return nil, i18n.Errorf("user.err.save", err)
+	}
+
+	next.UserRoles = u.copyRoles()
+	return &next, nil
+}
+
+// hasAdmin kiểm tra còn ít nhất một admin
+func (c *Config) hasAdmin() bool {
+	for _, id := range c.Users() {
+		if c.RoleOf(id) == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// copyRoles sao chép map role (gọi khi đang giữ lock)
+func (u *UserStore) copyRoles() map[int64]Role {
+	roles := make(map[int64]Role, len(u.roles))
+	for id, r := range u.roles {
+		roles[id] = r
+	}
+	return roles
+}
+
+// save ghi danh sách quyền xuống file (gọi khi đang giữ lock)
+func (u *UserStore) save() error {
+	if u.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(u.roles, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(u.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(u.path, data, 0o644)
+}
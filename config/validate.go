@@ -0,0 +1,288 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"pi-monitor/i18n"
+)
+
+// FieldError là lỗi cấu hình của một key (key trong file hoặc tên biến môi trường)
+type FieldError struct {
+	Key     string
+	Message string
+}
+
+// Error trả về lỗi dạng "key: message"
+func (e FieldError) Error() string {
+	return e.Key + ": " + e.Message
+}
+
+// ValidationError chứa tất cả lỗi cấu hình tìm được
+type ValidationError []FieldError
+
+// Error liệt kê các lỗi, mỗi lỗi một dòng
+func (v ValidationError) Error() string {
+	lines := make([]string, len(v))
+	for i, e := range v {
+		lines[i] = "  - " + e.Error()
+	}
+	return fmt.Sprintf("%d lỗi cấu hình:\n%s", len(v), strings.Join(lines, "\n"))
+}
+
+// validator gom lỗi khi kiểm tra cấu hình
+type validator struct {
+	errs ValidationError
+}
+
+func (v *validator) errorf(key, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) positive(key string, d time.Duration) {
+	if d <= 0 {
+		v.errorf(key, "phải lớn hơn 0")
+	}
+}
+
+func (v *validator) percent(key string, p float64) {
+	if p <= 0 || p > 100 {
+		v.errorf(key, "phải trong khoảng (0, 100], hiện tại %.1f", p)
+	}
+}
+
+func (v *validator) ports(key string, ports []int) {
+	for _, p := range ports {
+		if p <= 0 || p >= 65536 {
+			v.errorf(key, "port không hợp lệ: %d", p)
+		}
+	}
+}
+
+func (v *validator) file(key, path string) {
+	if path == "" {
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		v.errorf(key, "không đọc được file: %v", err)
+	}
+}
+
+// validate kiểm tra ý nghĩa của các giá trị cấu hình và nạp timezone.
+// Key báo lỗi theo dạng trong file cấu hình, kèm tên biến môi trường tương ứng.
+func (c *Config) validate() ValidationError {
+	v := &validator{}
+
+	if c.BotToken == "" {
+		v.errorf("bot_token (TELEGRAM_BOT_TOKEN)", "bắt buộc")
+	}
+	if c.DataDir == "" {
+		v.errorf("data_dir (DATA_DIR)", "không được để trống")
+	}
+
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		v.errorf("timezone (TIMEZONE)", "timezone không hợp lệ %q", c.Timezone)
+		loc = time.UTC
+	}
+	c.Location = loc
+
+	if _, ok := i18n.Parse(c.Language); !ok {
+		v.errorf("language (BOT_LANGUAGE)", "phải là vi hoặc en, hiện tại %q", c.Language)
+	}
+
+	// Telegram updates
+	switch c.BotMode {
+	case "polling":
+	case "webhook":
+		v.webhook(c)
+	default:
+		v.errorf("bot_mode (BOT_MODE)", "phải là polling hoặc webhook, hiện tại %q", c.BotMode)
+	}
+
+	for _, k := range c.StartupNotify {
+		switch k {
+		case "first", "restart", "crash", "reboot", "all", "none":
+		default:
+			v.errorf("startup.notify (STARTUP_NOTIFY)", "giá trị không hợp lệ %q (first, restart, crash, reboot, all, none)", k)
+		}
+	}
+	v.positive("shutdown.timeout (SHUTDOWN_TIMEOUT)", c.ShutdownTimeout)
+
+	// Heartbeat
+	v.positive("heartbeat.interval (HEARTBEAT_INTERVAL)", c.HeartbeatInterval)
+	if c.HeartbeatURL != "" {
+		if u, err := url.Parse(c.HeartbeatURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.errorf("heartbeat.url (HEARTBEAT_URL)", "URL không hợp lệ: %q", c.HeartbeatURL)
+		}
+	}
+
+	// Alert
+	v.positive("alert.interval (ALERT_INTERVAL)", c.AlertInterval)
+	if c.AlertCooldown < 0 {
+		v.errorf("alert.cooldown (ALERT_COOLDOWN)", "không được âm")
+	}
+	if c.CPUTempThreshold <= 0 || c.CPUTempThreshold > 120 {
+		v.errorf("alert.cpu_temp (ALERT_CPU_TEMP)", "phải trong khoảng (0, 120]°C, hiện tại %.1f", c.CPUTempThreshold)
+	}
+	v.percent("alert.cpu_usage (ALERT_CPU_USAGE)", c.CPUUsageThreshold)
+	v.percent("alert.memory (ALERT_MEMORY)", c.MemoryThreshold)
+	v.percent("alert.disk (ALERT_DISK)", c.DiskThreshold)
+
+	// Wi-Fi
+	if c.WiFiSignalThreshold < -120 || c.WiFiSignalThreshold > 0 {
+		v.errorf("wifi.signal (ALERT_WIFI_SIGNAL)", "phải trong khoảng [-120, 0] dBm, hiện tại %.0f", c.WiFiSignalThreshold)
+	}
+	if c.WiFiReconnectLimit < 0 {
+		v.errorf("wifi.reconnects (ALERT_WIFI_RECONNECTS)", "không được âm (0 = tắt)")
+	}
+	v.positive("wifi.window (ALERT_WIFI_WINDOW)", c.WiFiReconnectWindow)
+
+	// Wake-on-LAN
+	if c.WOLWaitTimeout < 0 {
+		v.errorf("wol.wait_timeout (WOL_WAIT_TIMEOUT)", "không được âm")
+	}
+	if c.WOLRetries < 1 {
+		v.errorf("wol.retries (WOL_RETRIES)", "phải lớn hơn 0")
+	}
+	v.positive("wol.retry_interval (WOL_RETRY_INTERVAL)", c.WOLRetryInterval)
+	v.positive("wol.track.interval (WOL_TRACK_INTERVAL)", c.WOLTrackInterval)
+	if c.WOLTrackOfflineChecks < 1 {
+		v.errorf("wol.track.offline_checks (WOL_TRACK_OFFLINE_CHECKS)", "phải lớn hơn 0")
+	}
+
+	names := make(map[string]bool)
+	for i, t := range c.WOLTargets {
+		key := fmt.Sprintf("wol.targets[%d]", i)
+		if t.Name != "" {
+			key = fmt.Sprintf("wol.targets[%s]", t.Name)
+		}
+		v.wolTarget(key, t)
+
+		lower := strings.ToLower(t.Name)
+		if names[lower] {
+			v.errorf(key+".name", "trùng tên với PC khác")
+		}
+		names[lower] = true
+	}
+
+	// Public IP
+	v.positive("public_ip.interval (PUBLIC_IP_INTERVAL)", c.PublicIPInterval)
+	if c.PublicIPEnabled && len(c.PublicIPEndpoints) == 0 && c.PublicIPSTUN == "" {
+		v.errorf("public_ip.endpoints (PUBLIC_IP_ENDPOINTS)", "cần ít nhất một endpoint hoặc STUN server")
+	}
+	for _, endpoint := range c.PublicIPEndpoints {
+		if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.errorf("public_ip.endpoints (PUBLIC_IP_ENDPOINTS)", "URL không hợp lệ: %q", endpoint)
+		}
+	}
+	if c.PublicIPSTUN != "" {
+		if _, _, err := net.SplitHostPort(c.PublicIPSTUN); err != nil {
+			v.errorf("public_ip.stun (PUBLIC_IP_STUN)", "cần dạng host:port, hiện tại %q", c.PublicIPSTUN)
+		}
+	}
+
+	// LAN devices
+	v.positive("devices.interval (DEVICES_INTERVAL)", c.DevicesInterval)
+	v.positive("devices.offline_after (DEVICES_OFFLINE_AFTER)", c.DevicesOfflineAfter)
+	v.file("devices.oui_file (OUI_FILE)", c.OUIFile)
+
+	return v.errs
+}
+
+// webhook kiểm tra cấu hình webhook
+func (v *validator) webhook(c *Config) {
+	if c.WebhookURL != "" {
+		if u, err := url.Parse(c.WebhookURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			v.errorf("webhook.url (WEBHOOK_URL)", "Telegram chỉ gọi webhook qua HTTPS, hiện tại %q", c.WebhookURL)
+		}
+	}
+	if _, _, err := net.SplitHostPort(c.WebhookListen); err != nil {
+		v.errorf("webhook.listen (WEBHOOK_LISTEN)", "cần dạng host:port hoặc :port, hiện tại %q", c.WebhookListen)
+	}
+
+	// Secret token: 1-256 ký tự A-Z, a-z, 0-9, _ và -
+	if len(c.WebhookSecret) > 256 || strings.Trim(c.WebhookSecret, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789_-") != "" {
+		v.errorf("webhook.secret (WEBHOOK_SECRET)", "tối đa 256 ký tự A-Z, a-z, 0-9, _ và -")
+	}
+
+	if (c.WebhookCertFile == "") != (c.WebhookKeyFile == "") {
+		v.errorf("webhook.cert_file (WEBHOOK_CERT_FILE)", "cần cấu hình cả cert_file và key_file")
+	}
+	v.file("webhook.cert_file (WEBHOOK_CERT_FILE)", c.WebhookCertFile)
+	v.file("webhook.key_file (WEBHOOK_KEY_FILE)", c.WebhookKeyFile)
+	if c.WebhookSelfSigned && c.WebhookCertFile == "" {
+		v.errorf("webhook.self_signed (WEBHOOK_SELF_SIGNED)", "cần cert_file để gửi certificate cho Telegram")
+	}
+}
+
+// wolTarget kiểm tra cấu hình của một PC
+func (v *validator) wolTarget(key string, t WOLTarget) {
+	if t.Name == "" {
+		v.errorf(key+".name", "bắt buộc")
+	}
+
+	if t.MAC == "" {
+		v.errorf(key+".mac", "bắt buộc")
+	} else if !validMAC(t.MAC) {
+		v.errorf(key+".mac", "địa chỉ MAC không hợp lệ %q", t.MAC)
+	}
+
+	if t.Password != "" && !validMAC(t.Password) && (net.ParseIP(t.Password).To4() == nil || strings.Count(t.Password, ".") != 3) {
+		v.errorf(key+".password", "SecureOn password cần dạng AA:BB:CC:DD:EE:FF hoặc a.b.c.d")
+	}
+
+	if t.Broadcast != "" {
+		host := t.Broadcast
+		if h, _, err := net.SplitHostPort(t.Broadcast); err == nil {
+			host = h
+		}
+		if net.ParseIP(host).To4() == nil {
+			v.errorf(key+".broadcast", "cần địa chỉ IPv4 (vd: 192.168.1.255 hoặc 192.168.1.255:9), hiện tại %q", t.Broadcast)
+		}
+	}
+
+	switch strings.ToLower(t.Mode) {
+	case "", "udp":
+	case "raw", "all":
+		if t.Interface == "" {
+			v.errorf(key+".mode", "chế độ %s cần cấu hình interface", t.Mode)
+		}
+	default:
+		v.errorf(key+".mode", "phải là udp, raw hoặc all, hiện tại %q", t.Mode)
+	}
+	v.ports(key+".send_ports", t.SendPorts)
+
+	for _, m := range t.CheckMethods {
+		switch strings.ToLower(m) {
+		case "icmp", "arp", "tcp":
+		default:
+			v.errorf(key+".check", "phương thức không hợp lệ %q (icmp, arp, tcp)", m)
+		}
+	}
+	v.ports(key+".ports", t.Ports)
+
+	if t.AgentURL != "" {
+		if u, err := url.Parse(t.AgentURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.errorf(key+".agent_url", "URL không hợp lệ %q", t.AgentURL)
+		}
+		if t.AgentSecret == "" {
+			v.errorf(key+".agent_secret", "bắt buộc khi có agent_url")
+		}
+	}
+}
+
+// validMAC kiểm tra MAC dạng AA:BB:CC:DD:EE:FF, AA-BB-CC-DD-EE-FF hoặc aabbccddeeff
+func validMAC(mac string) bool {
+	normalized := strings.NewReplacer(":", "", "-", "").Replace(strings.TrimSpace(mac))
+	if len(normalized) != 12 {
+		return false
+	}
+	_, err := hex.DecodeString(normalized)
+	return err == nil
+}
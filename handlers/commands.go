@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"pi-monitor/config"
+	"pi-monitor/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// AnyArgs dùng cho MaxArgs khi lệnh nhận số tham số bất kỳ
+const AnyArgs = -1
+
+// Command mô tả một lệnh. /help, menu lệnh của Telegram (setMyCommands) và kiểm tra cú pháp
+// đều sinh ra từ danh sách lệnh đăng ký bằng Router.Register.
+// Mô tả lệnh nằm trong catalog i18n với key "cmd.<Name>", cú pháp tham số (nếu có) với key "cmd.<Name>.usage".
+type Command struct {
+	Name    string
+	Role    config.Role // Quyền tối thiểu, trống = viewer
+	MinArgs int
+	MaxArgs int  // Số tham số tối đa, AnyArgs = không giới hạn
+	Public  bool // Hiện trong menu của người chưa có quyền (vd: /id để lấy User ID)
+	Hidden  bool // Không hiện trong /help và menu (vd: /start)
+	Handler HandlerFunc
+}
+
+// MinRole trả về quyền tối thiểu để chạy lệnh
+func (c Command) MinRole() config.Role {
+	if c.Role == config.RoleNone {
+		return config.RoleViewer
+	}
+	return c.Role
+}
+
+// Syntax trả về cú pháp đầy đủ của lệnh (vd: /wake <tên>)
+func (c Command) Syntax(p *i18n.Printer) string {
+	if key := "cmd." + c.Name + ".usage"; p.Has(key) {
+		return "/" + c.Name + " " + p.Text(key)
+	}
+	return "/" + c.Name
+}
+
+// Description trả về mô tả lệnh
+func (c Command) Description(p *i18n.Printer) string {
+	return p.Text("cmd." + c.Name)
+}
+
+// checkArgs bọc handler để trả lời cú pháp đúng khi số tham số không hợp lệ
+func (c Command) checkArgs(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, req *Request) error {
+		n := len(strings.Fields(req.Message.CommandArguments()))
+		if n < c.MinArgs || (c.MaxArgs != AnyArgs && n > c.MaxArgs) {
+			p := req.printer()
+			return req.Reply(ctx, tgbotapi.NewMessage(req.ChatID(), p.T("help.syntax", c.Syntax(p), c.Description(p))))
+		}
+		return next(ctx, req)
+	}
+}
+
+// Register đăng ký lệnh vào danh sách lệnh và handler của lệnh (kèm kiểm tra số tham số)
+func (r *Router) Register(c Command) {
+	r.commands = append(r.commands, c)
+	r.Handle(c.Name, c.checkArgs(c.Handler))
+}
+
+// Commands trả về các lệnh đã đăng ký theo thứ tự đăng ký
+func (r *Router) Commands() []Command {
+	return r.commands
+}
+
+// Command tìm lệnh đã đăng ký theo tên
+func (r *Router) Command(name string) (Command, bool) {
+	for _, c := range r.commands {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Command{}, false
+}
+
+// HelpText liệt kê các lệnh role được dùng, kèm cú pháp và mô tả
+func HelpText(p *i18n.Printer, commands []Command, role config.Role) string {
+	var sb strings.Builder
+	sb.WriteString(p.T("help.title") + "\n\n")
+	for _, c := range visibleCommands(commands, role) {
+		sb.WriteString(fmt.Sprintf("%s - %s\n", i18n.Escape(c.Syntax(p)), i18n.Escape(c.Description(p))))
+	}
+	sb.WriteString("\n" + p.T("help.role", role))
+	return sb.String()
+}
+
+// visibleCommands trả về các lệnh không ẩn mà role được dùng
+func visibleCommands(commands []Command, role config.Role) []Command {
+	var visible []Command
+	for _, c := range commands {
+		if !c.Hidden && role.Allows(c.MinRole()) {
+			visible = append(visible, c)
+		}
+	}
+	return visible
+}
+
+// CommandMenu đăng ký menu lệnh với Telegram (setMyCommands) theo quyền của từng người dùng:
+// chat riêng của mỗi user chỉ hiện lệnh user đó được dùng, group hiện lệnh của viewer,
+// người chưa có quyền chỉ thấy lệnh Public. Mỗi menu được đăng ký cho từng ngôn ngữ có catalog
+// và cho ngôn ngữ mặc định trong cấu hình (người dùng có language_code khác).
+type CommandMenu struct {
+	bot     *tgbotapi.BotAPI
+	current func() *config.Config
+
+	mu       sync.Mutex
+	commands []Command
+	lang     i18n.Lang             // Ngôn ngữ mặc định đã đăng ký menu
+	shared   bool                  // Đã đăng ký menu mặc định và menu group
+	roles    map[int64]config.Role // Quyền đã đăng ký menu cho từng user
+}
+
+// NewCommandMenu tạo menu lệnh, current trả về cấu hình đang chạy (danh sách user và quyền)
+func NewCommandMenu(bot *tgbotapi.BotAPI, current func() *config.Config) *CommandMenu {
+	return &CommandMenu{bot: bot, current: current, roles: make(map[int64]config.Role)}
+}
+
+// SetCommands đặt danh sách lệnh và đăng ký lại toàn bộ menu
+func (m *CommandMenu) SetCommands(commands []Command) {
+	m.mu.Lock()
+	m.commands = commands
+	m.shared = false
+	m.roles = make(map[int64]config.Role)
+	m.mu.Unlock()
+	m.Sync()
+}
+
+// Sync đăng ký menu cho user mới hoặc đổi quyền và xoá menu của user đã bị xoá.
+// Gọi sau mỗi lần cấu hình thay đổi, không làm gì khi chưa có danh sách lệnh.
+func (m *CommandMenu) Sync() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.commands == nil {
+		return
+	}
+	cfg := m.current()
+
+	// Đổi language trong cấu hình thì đăng ký lại menu cho người dùng có language_code khác
+	if m.lang != cfg.DefaultLang() {
+		m.lang = cfg.DefaultLang()
+		m.shared = false
+		for id := range m.roles {
+			m.roles[id] = config.RoleNone
+		}
+	}
+
+	if !m.shared {
+		errDefault := m.set(tgbotapi.NewBotCommandScopeDefault(), m.publicCommands())
+		errGroups := m.set(tgbotapi.NewBotCommandScopeAllGroupChats(), visibleCommands(m.commands, config.RoleViewer))
+		m.shared = errDefault == nil && errGroups == nil
+	}
+
+	users := make(map[int64]bool)
+	for _, id := range cfg.Users() {
+		users[id] = true
+		role := cfg.RoleOf(id)
+		if m.roles[id] == role {
+			continue
+		}
+		if err := m.set(tgbotapi.NewBotCommandScopeChat(id), visibleCommands(m.commands, role)); err == nil {
+			m.roles[id] = role
+		}
+	}
+
+	for id := range m.roles {
+		if users[id] {
+			continue
+		}
+		// Bỏ menu riêng, user đã bị xoá quay về menu mặc định
+		if err := m.set(tgbotapi.NewBotCommandScopeChat(id), nil); err == nil {
+			delete(m.roles, id)
+		}
+	}
+}
+
+// publicCommands trả về các lệnh hiện cho người chưa có quyền
+func (m *CommandMenu) publicCommands() []Command {
+	var public []Command
+	for _, c := range m.commands {
+		if c.Public && !c.Hidden {
+			public = append(public, c)
+		}
+	}
+	return public
+}
+
+// set đăng ký menu commands cho scope với ngôn ngữ mặc định và từng ngôn ngữ có catalog.
+// Danh sách rỗng thì xoá menu của scope (Telegram dùng menu của scope rộng hơn).
+func (m *CommandMenu) set(scope tgbotapi.BotCommandScope, commands []Command) error {
+	var errs []error
+	for _, lang := range append([]i18n.Lang{""}, i18n.Supported...) {
+		// language_code trống: người dùng có ngôn ngữ chưa có catalog, dùng ngôn ngữ mặc định
+		p := i18n.NewPrinter(lang, nil)
+		if lang == "" {
+			p = i18n.NewPrinter(m.lang, nil)
+		}
+		var req tgbotapi.Chattable
+		if len(commands) == 0 {
+			del := tgbotapi.NewDeleteMyCommandsWithScope(scope)
+			del.LanguageCode = string(lang)
+			req = del
+		} else {
+			botCommands := make([]tgbotapi.BotCommand, len(commands))
+			for i, c := range commands {
+				botCommands[i] = tgbotapi.BotCommand{Command: c.Name, Description: c.Description(p)}
+			}
+			req = tgbotapi.NewSetMyCommandsWithScopeAndLanguage(scope, string(lang), botCommands...)
+		}
+		if _, err := m.bot.Request(req); err != nil {
+			log.Printf("⚠️ Cannot set bot commands (scope %s, chat %d, language %q): %v", scope.Type, scope.ChatID, lang, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
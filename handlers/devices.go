@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"pi-monitor/i18n"
+	"pi-monitor/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HandleDevicesCommand xử lý lệnh /devices - liệt kê thiết bị trong mạng LAN
+//
+//	/devices                  - danh sách thiết bị
+//	/devices scan             - quét chủ động subnet rồi liệt kê
+//	/devices name <MAC> <tên> - đặt tên cho thiết bị
+//	/devices history <MAC>    - lịch sử online/offline
+func HandleDevicesCommand(p *i18n.Printer, message *tgbotapi.Message, registry *services.DeviceRegistry, sweep bool) tgbotapi.MessageConfig {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+
+	sub := ""
+	if len(args) > 0 {
+		sub = strings.ToLower(args[0])
+	}
+
+	switch sub {
+	case "", "scan":
+		newDevices, err := registry.Scan(sweep || sub == "scan")
+		if err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("devices.scan_error", p.Err(err)))
+		}
+		return tgbotapi.NewMessage(chatID, formatDeviceList(p, registry.List(), newDevices))
+
+	case "name":
+		if len(args) < 2 {
+			return tgbotapi.NewMessage(chatID, p.T("devices.name_syntax"))
+		}
+		name := strings.Join(args[2:], " ")
+		if err := registry.SetName(args[1], name); err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("devices.name_error", p.Err(err)))
+		}
+		if name == "" {
+			return tgbotapi.NewMessage(chatID, p.T("devices.name_cleared", args[1]))
+		}
+		return tgbotapi.NewMessage(chatID, p.T("devices.named", name, args[1]))
+
+	case "history":
+		if len(args) < 2 {
+			return tgbotapi.NewMessage(chatID, p.T("devices.history_syntax"))
+		}
+		device, ok := registry.Find(strings.Join(args[1:], " "))
+		if !ok {
+			return tgbotapi.NewMessage(chatID, p.T("devices.not_found"))
+		}
+		return tgbotapi.NewMessage(chatID, formatDeviceHistory(p, device))
+
+	default:
+		return tgbotapi.NewMessage(chatID, p.T("devices.syntax"))
+	}
+}
+
+// formatDeviceList format danh sách thiết bị, đánh dấu thiết bị mới phát hiện
+func formatDeviceList(p *i18n.Printer, devices, newDevices []services.Device) string {
+	if len(devices) == 0 {
+		return p.T("devices.list_empty")
+	}
+
+	isNew := make(map[string]bool)
+	for _, d := range newDevices {
+		isNew[d.MAC] = true
+	}
+
+	online := 0
+	var sb strings.Builder
+	for _, d := range devices {
+		if d.Online {
+			online++
+		}
+
+		status := "🟢"
+		if !d.Online {
+			status = "⚫"
+		}
+		label := i18n.Escape(d.DisplayName(p))
+		if isNew[d.MAC] {
+			label += " 🆕"
+		}
+
+		sb.WriteString(fmt.Sprintf("\n%s <b>%s</b>\n", status, label))
+		sb.WriteString(fmt.Sprintf("├ IP: <code>%s</code>\n", i18n.Escape(valueOrDash(d.IP))))
+		if vendor := d.VendorName(p); d.Name != "" && vendor != "" {
+			sb.WriteString(p.T("devices.vendor", vendor))
+		}
+		if d.Online || d.LastSeen.IsZero() {
+			sb.WriteString(fmt.Sprintf("└ MAC: <code>%s</code>\n", i18n.Escape(d.MAC)))
+		} else {
+			sb.WriteString(fmt.Sprintf("├ MAC: <code>%s</code>\n", i18n.Escape(d.MAC)))
+			sb.WriteString(p.T("devices.last_seen", p.DateTimeShort(d.LastSeen)))
+		}
+	}
+
+	return p.T("devices.list_title", online, len(devices)) + sb.String()
+}
+
+// formatDeviceHistory format lịch sử online/offline của một thiết bị
+func formatDeviceHistory(p *i18n.Printer, d services.Device) string {
+	var sb strings.Builder
+	sb.WriteString(p.T("devices.history_title", d.DisplayName(p)))
+	sb.WriteString(fmt.Sprintf("├ MAC: <code>%s</code>\n├ IP: <code>%s</code>\n", i18n.Escape(d.MAC), i18n.Escape(valueOrDash(d.IP))))
+	if !d.FirstSeen.IsZero() {
+		sb.WriteString(p.T("devices.first_seen", p.DateTimeShort(d.FirstSeen)))
+	}
+
+	if len(d.History) == 0 {
+		sb.WriteString(p.T("devices.history_empty"))
+		return sb.String()
+	}
+
+	sb.WriteString("\n")
+	// Hiển thị tối đa 15 sự kiện gần nhất, mới nhất trước
+	start := len(d.History) - 15
+	if start < 0 {
+		start = 0
+	}
+	for i := len(d.History) - 1; i >= start; i-- {
+		e := d.History[i]
+		status := "🟢 online"
+		if !e.Online {
+			status = "⚫ offline"
+		}
+		sb.WriteString(fmt.Sprintf("%s - %s", p.DayTime(e.Time), status))
+		if i < len(d.History)-1 {
+			sb.WriteString(fmt.Sprintf(" (%s)", formatShortDuration(d.History[i+1].Time.Sub(e.Time))))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// formatShortDuration format khoảng thời gian ngắn gọn (vd: 2h15m)
+func formatShortDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "<1m"
+	}
+	s := d.String()
+	return strings.TrimSuffix(s, "0s")
+}
+
+// valueOrDash trả về "-" cho chuỗi rỗng
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+
+	"pi-monitor/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Send gửi tin nhắn (hoặc sửa nội dung tin nhắn) với parse mode HTML.
+// Nếu Telegram không parse được HTML (thẻ sai, ký tự chưa escape) thì gửi lại dạng text thường để tin nhắn không bị mất.
+func Send(bot *tgbotapi.BotAPI, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	switch m := c.(type) {
+	case tgbotapi.MessageConfig:
+		m.ParseMode = tgbotapi.ModeHTML
+		sent, err := bot.Send(m)
+		if IsParseError(err) {
+			log.Printf("⚠️ Telegram rejected HTML message, sending as plain text: %v", err)
+			m.Text, m.ParseMode = i18n.PlainText(m.Text), ""
+			return bot.Send(m)
+		}
+		return sent, err
+	case tgbotapi.EditMessageTextConfig:
+		m.ParseMode = tgbotapi.ModeHTML
+		sent, err := bot.Send(m)
+		if IsParseError(err) {
+			log.Printf("⚠️ Telegram rejected HTML edit, sending as plain text: %v", err)
+			m.Text, m.ParseMode = i18n.PlainText(m.Text), ""
+			return bot.Send(m)
+		}
+		return sent, err
+	}
+	return bot.Send(c)
+}
+
+// IsParseError kiểm tra lỗi Telegram trả về khi không parse được định dạng tin nhắn
+func IsParseError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "can't parse entities")
+}
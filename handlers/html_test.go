@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sentRequest là một request sendMessage/editMessageText mà Telegram giả nhận được
+type sentRequest struct {
+	method    string
+	text      string
+	parseMode string
+}
+
+// fakeTelegram là Bot API giả: từ chối tin nhắn HTML khi rejectHTML và ghi lại các request đã nhận
+type fakeTelegram struct {
+	rejectHTML bool
+
+	mu       sync.Mutex
+	requests []sentRequest
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	r.ParseForm()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch method {
+	case "getMe":
+		fmt.Fprint(w, `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"pi","username":"pi_bot"}}`)
+		return
+	case "sendMessage", "editMessageText":
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	req := sentRequest{method: method, text: r.PostForm.Get("text"), parseMode: r.PostForm.Get("parse_mode")}
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+
+	if f.rejectHTML && req.parseMode == tgbotapi.ModeHTML {
+		fmt.Fprint(w, `{"ok":false,"error_code":400,"description":"Bad Request: can't parse entities: Unsupported start tag \"x\" at byte offset 3"}`)
+		return
+	}
+	result, _ := json.Marshal(map[string]interface{}{
+		"ok":     true,
+		"result": map[string]interface{}{"message_id": 7, "date": 0, "chat": map[string]interface{}{"id": 42}, "text": req.text},
+	})
+	w.Write(result)
+}
+
+// newFakeBot tạo bot kết nối đến Telegram giả
+func newFakeBot(t *testing.T, rejectHTML bool) (*tgbotapi.BotAPI, *fakeTelegram) {
+	t.Helper()
+	fake := &fakeTelegram{rejectHTML: rejectHTML}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	bot, err := tgbotapi.NewBotAPIWithClient("token", server.URL+"/bot%s/%s", server.Client())
+	if err != nil {
+		t.Fatalf("NewBotAPIWithClient: %v", err)
+	}
+	return bot, fake
+}
+
+func TestSend(t *testing.T) {
+	const html = "<b>PC &lt;x&gt;</b> is <i>on</i> &amp; *ready*"
+
+	tests := []struct {
+		name       string
+		chattable  tgbotapi.Chattable
+		rejectHTML bool
+		want       []sentRequest
+	}{
+		{
+			name:      "message HTML",
+			chattable: tgbotapi.NewMessage(42, html),
+			want:      []sentRequest{{"sendMessage", html, tgbotapi.ModeHTML}},
+		},
+		{
+			name:       "message plain-text fallback",
+			chattable:  tgbotapi.NewMessage(42, html),
+			rejectHTML: true,
+			want: []sentRequest{
+				{"sendMessage", html, tgbotapi.ModeHTML},
+				{"sendMessage", "PC <x> is on & *ready*", ""},
+			},
+		},
+		{
+			name:      "edit HTML",
+			chattable: tgbotapi.NewEditMessageText(42, 7, html),
+			want:      []sentRequest{{"editMessageText", html, tgbotapi.ModeHTML}},
+		},
+		{
+			name:       "edit plain-text fallback",
+			chattable:  tgbotapi.NewEditMessageText(42, 7, html),
+			rejectHTML: true,
+			want: []sentRequest{
+				{"editMessageText", html, tgbotapi.ModeHTML},
+				{"editMessageText", "PC <x> is on & *ready*", ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot, fake := newFakeBot(t, tt.rejectHTML)
+
+			if _, err := Send(bot, tt.chattable); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if len(fake.requests) != len(tt.want) {
+				t.Fatalf("requests = %+v, want %+v", fake.requests, tt.want)
+			}
+			for i, want := range tt.want {
+				if fake.requests[i] != want {
+					t.Errorf("request %d = %+v, want %+v", i, fake.requests[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestIsParseError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("Bad Request: can't parse entities: Can't find end of the entity starting at byte offset 12"), true},
+		{&tgbotapi.Error{Code: 400, Message: "Bad Request: can't parse entities: Unsupported start tag \"x\""}, true},
+		{errors.New("Bad Request: chat not found"), false},
+		{errors.New("Forbidden: bot was blocked by the user"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsParseError(tt.err); got != tt.want {
+			t.Errorf("IsParseError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
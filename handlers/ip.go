@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"pi-monitor/i18n"
+	"pi-monitor/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HandleIPCommand xử lý lệnh /ip - hiển thị IP public hiện tại của Pi
+func HandleIPCommand(p *i18n.Printer, message *tgbotapi.Message, monitor *services.PublicIPMonitor) tgbotapi.MessageConfig {
+	chatID := message.Chat.ID
+
+	ip, err := monitor.Resolve()
+	if err != nil {
+		// Không lấy được IP mới -> hiển thị giá trị đã biết gần nhất (nếu có)
+		last := monitor.Last()
+		if last.IPv4 == "" && last.IPv6 == "" {
+			return tgbotapi.NewMessage(chatID, p.T("ip.error", p.Err(err)))
+		}
+		text := p.T("ip.last_known",
+			ipOrNA(last.IPv4),
+			ipOrNA(last.IPv6),
+			p.DateTime(last.UpdatedAt),
+		)
+		return tgbotapi.NewMessage(chatID, text)
+	}
+
+	text := p.T("ip.current",
+		ipOrNA(ip.IPv4),
+		ipOrNA(ip.IPv6),
+		services.GetLocalIP(),
+	)
+	return tgbotapi.NewMessage(chatID, text)
+}
+
+// ipOrNA trả về "N/A" nếu chưa có địa chỉ
+func ipOrNA(ip string) string {
+	if ip == "" {
+		return "N/A"
+	}
+	return ip
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"strings"
+
+	"pi-monitor/config"
+	"pi-monitor/i18n"
+	"pi-monitor/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// langCallbackPrefix là prefix của callback data cho nút chọn ngôn ngữ trong /lang
+const langCallbackPrefix = "lang:"
+
+// langAuto bỏ ngôn ngữ đã chọn, quay về dùng language_code của Telegram
+const langAuto = "auto"
+
+// HandleLangCommand xử lý lệnh /lang - chọn ngôn ngữ trả lời và thông báo cho chat
+//
+//	/lang           - ngôn ngữ hiện tại kèm nút chọn
+//	/lang <vi|en>   - chọn ngôn ngữ
+//	/lang auto      - theo ngôn ngữ ứng dụng Telegram của người gửi
+func HandleLangCommand(p *i18n.Printer, message *tgbotapi.Message, cfg *config.Config, langs *services.LanguageStore) tgbotapi.MessageConfig {
+	chatID := message.Chat.ID
+	arg := strings.TrimSpace(message.CommandArguments())
+
+	if arg == "" {
+		msg := tgbotapi.NewMessage(chatID, formatLang(p, chatID, langs))
+		msg.ReplyMarkup = langKeyboard(p, chatID, langs)
+		return msg
+	}
+
+	next, err := setLang(chatID, message.From.ID, arg, cfg, langs)
+	if err != nil {
+		return tgbotapi.NewMessage(chatID, p.T("common.error", p.Err(err))+"\n\n"+p.T("lang.usage"))
+	}
+	return tgbotapi.NewMessage(chatID, langChanged(next, arg))
+}
+
+// HandleLangCallback xử lý khi người dùng bấm nút chọn ngôn ngữ, trả lời bằng ngôn ngữ vừa chọn
+func HandleLangCallback(p *i18n.Printer, query *tgbotapi.CallbackQuery, cfg *config.Config, langs *services.LanguageStore) tgbotapi.EditMessageTextConfig {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+	arg := strings.TrimPrefix(query.Data, langCallbackPrefix)
+
+	next, err := setLang(chatID, query.From.ID, arg, cfg, langs)
+	if err != nil {
+		return tgbotapi.NewEditMessageText(chatID, messageID, p.T("common.error", p.Err(err)))
+	}
+	return tgbotapi.NewEditMessageText(chatID, messageID, langChanged(next, arg))
+}
+
+// IsLangCallback kiểm tra callback data có thuộc nút chọn ngôn ngữ không
+func IsLangCallback(data string) bool {
+	return strings.HasPrefix(data, langCallbackPrefix)
+}
+
+// setLang lưu ngôn ngữ đã chọn cho chat (hoặc bỏ chọn với auto), trả về Printer theo ngôn ngữ mới
+func setLang(chatID, userID int64, arg string, cfg *config.Config, langs *services.LanguageStore) (*i18n.Printer, error) {
+	if strings.EqualFold(arg, langAuto) {
+		if err := langs.Reset(chatID); err != nil {
+			return nil, i18n.Errorf("lang.err.save", err)
+		}
+	} else {
+		lang, ok := i18n.Parse(arg)
+		if !ok {
+			return nil, i18n.Errorf("lang.err.unknown", arg)
+		}
+		if err := langs.Set(chatID, lang); err != nil {
+			return nil, i18n.Errorf("lang.err.save", err)
+		}
+	}
+	return i18n.NewPrinter(langs.Resolve(chatID, userID, cfg.DefaultLang()), cfg.Location), nil
+}
+
+// langChanged trả về tin nhắn xác nhận bằng ngôn ngữ vừa chọn
+func langChanged(p *i18n.Printer, arg string) string {
+	if strings.EqualFold(arg, langAuto) {
+		return p.T("lang.auto", p.Lang().Name())
+	}
+	return p.T("lang.changed", p.Lang().Name())
+}
+
+// formatLang format ngôn ngữ đang dùng trong chat
+func formatLang(p *i18n.Printer, chatID int64, langs *services.LanguageStore) string {
+	source := p.T("lang.source.auto")
+	if _, ok := langs.Chosen(chatID); ok {
+		source = p.T("lang.source.chosen")
+	}
+	return p.T("lang.title", p.Lang().Name(), i18n.HTML(source))
+}
+
+// langKeyboard tạo nút chọn ngôn ngữ, đánh dấu lựa chọn hiện tại của chat
+func langKeyboard(p *i18n.Printer, chatID int64, langs *services.LanguageStore) tgbotapi.InlineKeyboardMarkup {
+	chosen, ok := langs.Chosen(chatID)
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, l := range i18n.Supported {
+		label := l.Name()
+		if ok && l == chosen {
+			label = "✅ " + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, langCallbackPrefix+string(l)))
+	}
+	auto := p.T("lang.button.auto")
+	if !ok {
+		auto = "✅ " + auto
+	}
+	row = append(row, tgbotapi.NewInlineKeyboardButtonData(auto, langCallbackPrefix+langAuto))
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pi-monitor/config"
+	"pi-monitor/i18n"
+	"pi-monitor/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Giới hạn của /live: Telegram giới hạn số lần sửa tin nhắn mỗi phút trong group
+const (
+	liveDefaultInterval = 5 * time.Second
+	liveDefaultDuration = 5 * time.Minute
+	liveMinInterval     = 3 * time.Second
+	liveMaxDuration     = 30 * time.Minute
+)
+
+// liveCallbackPrefix là prefix của callback data các nút /live
+const liveCallbackPrefix = "live:"
+
+// Lý do dừng cập nhật, hiển thị ở cuối tin nhắn
+var (
+	errLiveTimeout  = i18n.Errorf("live.reason.timeout")
+	errLiveStopped  = i18n.Errorf("live.reason.stopped")
+	errLiveReplaced = i18n.Errorf("live.reason.replaced")
+	errLiveShutdown = i18n.Errorf("live.reason.shutdown")
+)
+
+// liveSession là một tin nhắn đang được cập nhật liên tục
+type liveSession struct {
+	chatID    int64
+	messageID int
+	interval  time.Duration
+	duration  time.Duration
+	printer   *i18n.Printer // Ngôn ngữ của người mở /live
+	cancel    context.CancelCauseFunc
+}
+
+// LiveManager quản lý các tin nhắn /live đang cập nhật, mỗi chat tối đa một tin nhắn
+type LiveManager struct {
+	ctx      context.Context
+	mu       sync.Mutex
+	sessions map[int64]*liveSession
+	wg       sync.WaitGroup
+}
+
+// NewLiveManager tạo LiveManager, tất cả tin nhắn ngừng cập nhật khi ctx bị huỷ (bot dừng)
+func NewLiveManager(ctx context.Context) *LiveManager {
+	return &LiveManager{ctx: ctx, sessions: make(map[int64]*liveSession)}
+}
+
+// Wait chờ tất cả tin nhắn /live dừng cập nhật
+func (m *LiveManager) Wait() {
+	m.wg.Wait()
+}
+
+// Start bắt đầu cập nhật tin nhắn messageID mỗi interval trong duration.
+// Tin nhắn /live khác đang chạy trong cùng chat bị dừng.
+func (m *LiveManager) Start(bot *tgbotapi.BotAPI, cfg *config.Config, p *i18n.Printer, chatID int64, messageID int, interval, duration time.Duration) {
+	ctx, cancel := context.WithCancelCause(m.ctx)
+	s := &liveSession{chatID: chatID, messageID: messageID, interval: interval, duration: duration, printer: p, cancel: cancel}
+
+	m.mu.Lock()
+	if old, ok := m.sessions[chatID]; ok {
+		old.cancel(errLiveReplaced)
+	}
+	m.sessions[chatID] = s
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer cancel(nil)
+		m.run(ctx, bot, cfg, s)
+
+		m.mu.Lock()
+		if m.sessions[chatID] == s {
+			delete(m.sessions, chatID)
+		}
+		m.mu.Unlock()
+	}()
+}
+
+// Stop dừng cập nhật tin nhắn messageID, trả về false nếu tin nhắn không còn cập nhật
+func (m *LiveManager) Stop(chatID int64, messageID int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[chatID]
+	if !ok || s.messageID != messageID {
+		return false
+	}
+	s.cancel(errLiveStopped)
+	return true
+}
+
+// run lấy số liệu và sửa tin nhắn cho đến khi hết thời gian hoặc bị dừng
+func (m *LiveManager) run(ctx context.Context, bot *tgbotapi.BotAPI, cfg *config.Config, s *liveSession) {
+	log.Printf("📈 Live dashboard started in chat %d (every %v for %v)", s.chatID, s.interval, s.duration)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	deadline := time.Now().Add(s.duration)
+	timeout := time.NewTimer(s.duration)
+	defer timeout.Stop()
+
+	var prev *liveSample
+	text := ""
+	for {
+		cur, err := takeLiveSample(prev)
+		if err != nil {
+			log.Printf("⚠️ Live dashboard in chat %d: %v", s.chatID, err)
+		} else {
+			text = formatLive(s.printer, cfg, prev, cur, s.interval, max(time.Until(deadline), 0))
+			prev = cur
+
+			edit := tgbotapi.NewEditMessageTextAndMarkup(s.chatID, s.messageID, text, liveKeyboard(s.printer))
+			if _, err := Send(bot, edit); err != nil && !isNotModified(err) {
+				// Tin nhắn đã bị xoá hoặc bot bị xoá khỏi chat: dừng luôn
+				log.Printf("⚠️ Live dashboard in chat %d stopped: %v", s.chatID, err)
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			m.finish(bot, s, text, context.Cause(ctx))
+			return
+		case <-timeout.C:
+			m.finish(bot, s, text, errLiveTimeout)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// finish sửa tin nhắn lần cuối: giữ số liệu cuối cùng, bỏ nút Dừng và ghi lý do dừng
+func (m *LiveManager) finish(bot *tgbotapi.BotAPI, s *liveSession, text string, reason error) {
+	if errors.Is(reason, context.Canceled) {
+		reason = errLiveShutdown
+	}
+	log.Printf("📈 Live dashboard in chat %d stopped: %v", s.chatID, reason)
+
+	if text == "" {
+		text = "📈 <b>Pi Live</b>"
+	}
+	edit := tgbotapi.NewEditMessageText(s.chatID, s.messageID, text+"\n\n"+s.printer.T("live.finished", s.printer.Err(reason)))
+	if _, err := Send(bot, edit); err != nil && !isNotModified(err) {
+		log.Printf("⚠️ Cannot finish live dashboard in chat %d: %v", s.chatID, err)
+	}
+}
+
+// HandleLiveCommand xử lý lệnh /live [chu kỳ] [thời gian]: gửi tin nhắn rồi cập nhật liên tục
+func HandleLiveCommand(ctx context.Context, req *Request, live *LiveManager) error {
+	chatID, p := req.ChatID(), req.Printer
+	interval, duration, err := parseLiveArgs(strings.Fields(req.Message.CommandArguments()))
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, p.T("live.usage", p.Err(err), liveDefaultInterval, liveDefaultDuration))
+		return req.Reply(ctx, msg)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, "📈 <b>Pi Live</b>\n\n"+p.T("live.loading"))
+	msg.ReplyMarkup = liveKeyboard(p)
+	sent, err := req.Send(ctx, msg)
+	if err != nil {
+		return err
+	}
+	live.Start(req.Bot, req.Config, p, chatID, sent.MessageID, interval, duration)
+	return nil
+}
+
+// HandleLiveCallback xử lý nút của /live: bắt đầu cập nhật tin nhắn /pi hoặc dừng cập nhật.
+// Trả về nội dung thông báo nhỏ cho người bấm.
+func HandleLiveCallback(p *i18n.Printer, bot *tgbotapi.BotAPI, query *tgbotapi.CallbackQuery, cfg *config.Config, live *LiveManager) string {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+
+	switch strings.TrimPrefix(query.Data, liveCallbackPrefix) {
+	case "start":
+		live.Start(bot, cfg, p, chatID, messageID, liveDefaultInterval, liveDefaultDuration)
+		return p.Text("live.started", liveDefaultInterval, liveDefaultDuration)
+	case "stop":
+		if !live.Stop(chatID, messageID) {
+			// Tin nhắn cũ (bot đã khởi động lại): chỉ bỏ nút
+			bot.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+		}
+		return p.Text("live.stopped")
+	default:
+		return p.Text("common.invalid_button")
+	}
+}
+
+// IsLiveCallback kiểm tra callback data có phải của /live không
+func IsLiveCallback(data string) bool {
+	return strings.HasPrefix(data, liveCallbackPrefix)
+}
+
+// LiveButton là nút chuyển tin nhắn /pi thành bảng cập nhật liên tục
+func LiveButton(p *i18n.Printer) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(p.T("live.button.start"), liveCallbackPrefix+"start")
+}
+
+// liveKeyboard là nút Dừng của tin nhắn đang cập nhật
+func liveKeyboard(p *i18n.Printer) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(p.T("live.button.stop"), liveCallbackPrefix+"stop"),
+	))
+}
+
+// parseLiveArgs đọc chu kỳ và thời gian cập nhật (số giây hoặc dạng 10s, 5m)
+func parseLiveArgs(args []string) (interval, duration time.Duration, err error) {
+	interval, duration = liveDefaultInterval, liveDefaultDuration
+	if len(args) > 2 {
+		return 0, 0, i18n.Errorf("live.err.too_many_args")
+	}
+
+	parse := func(s string) (time.Duration, error) {
+		if n, err := strconv.Atoi(s); err == nil {
+			return time.Duration(n) * time.Second, nil
+		}
+		return time.ParseDuration(s)
+	}
+	if len(args) > 0 {
+		if interval, err = parse(args[0]); err != nil {
+			return 0, 0, i18n.Errorf("live.err.interval", args[0])
+		}
+	}
+	if len(args) > 1 {
+		if duration, err = parse(args[1]); err != nil {
+			return 0, 0, i18n.Errorf("live.err.duration", args[1])
+		}
+	}
+
+	if interval < liveMinInterval {
+		return 0, 0, i18n.Errorf("live.err.min_interval", liveMinInterval)
+	}
+	if duration < interval || duration > liveMaxDuration {
+		return 0, 0, i18n.Errorf("live.err.duration_range", interval, liveMaxDuration)
+	}
+	return interval, duration, nil
+}
+
+// liveSample là số liệu hệ thống tại một thời điểm
+type liveSample struct {
+	time   time.Time
+	info   *services.SystemInfo
+	sendBS float64 // Tốc độ gửi (byte/s), tính từ mẫu trước
+	recvBS float64
+}
+
+// takeLiveSample lấy số liệu hệ thống (mất khoảng 1 giây để đo CPU), tính tốc độ mạng so với mẫu trước
+func takeLiveSample(prev *liveSample) (*liveSample, error) {
+	info, err := services.GetSystemInfo()
+	if err != nil {
+		return nil, err
+	}
+	cur := &liveSample{time: time.Now(), info: info}
+
+	// Bộ đếm bị reset (interface khởi động lại) thì bỏ qua tốc độ của mẫu này
+	if prev != nil && info.Network.Sent >= prev.info.Network.Sent && info.Network.Recv >= prev.info.Network.Recv {
+		if secs := cur.time.Sub(prev.time).Seconds(); secs > 0 {
+			cur.sendBS = float64(info.Network.Sent-prev.info.Network.Sent) / secs
+			cur.recvBS = float64(info.Network.Recv-prev.info.Network.Recv) / secs
+		}
+	}
+	return cur, nil
+}
+
+// formatLive hiển thị số liệu kèm xu hướng so với mẫu trước, ⚠️ đánh dấu giá trị vượt ngưỡng cảnh báo
+func formatLive(p *i18n.Printer, cfg *config.Config, prev, cur *liveSample, interval, remaining time.Duration) string {
+	info := cur.info
+	var last *services.SystemInfo
+	if prev != nil {
+		last = prev.info
+	}
+
+	// value trả về giá trị của mẫu trước (nếu có) để so sánh
+	value := func(get func(*services.SystemInfo) float64) (float64, bool) {
+		if last == nil {
+			return 0, false
+		}
+		return get(last), true
+	}
+	over := func(v, threshold float64) string {
+		if v >= threshold {
+			return " ⚠️"
+		}
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(p.T("live.title", interval) + "\n\n")
+
+	cpuPrev, ok := value(func(i *services.SystemInfo) float64 { return i.CPU.UsagePercent })
+	sb.WriteString(fmt.Sprintf("🖥️ CPU: %s%s%s\n", p.Percent(info.CPU.UsagePercent, 1), trendArrow(cpuPrev, info.CPU.UsagePercent, 2, ok), over(info.CPU.UsagePercent, cfg.CPUUsageThreshold)))
+
+	tempPrev, ok := value(func(i *services.SystemInfo) float64 { return i.CPU.Temperature })
+	sb.WriteString(p.T("live.temperature", p.Number(info.CPU.Temperature, 1), trendArrow(tempPrev, info.CPU.Temperature, 0.5, ok), over(info.CPU.Temperature, cfg.CPUTempThreshold)) + "\n")
+
+	memPrev, ok := value(func(i *services.SystemInfo) float64 { return i.Memory.UsedPercent })
+	sb.WriteString(fmt.Sprintf("💾 RAM: %s%s%s\n", p.Percent(info.Memory.UsedPercent, 1), trendArrow(memPrev, info.Memory.UsedPercent, 0.5, ok), over(info.Memory.UsedPercent, cfg.MemoryThreshold)))
+
+	diskPrev, ok := value(func(i *services.SystemInfo) float64 { return i.Disk.UsedPercent })
+	sb.WriteString(fmt.Sprintf("💿 Disk: %s%s%s\n", p.Percent(info.Disk.UsedPercent, 1), trendArrow(diskPrev, info.Disk.UsedPercent, 0.1, ok), over(info.Disk.UsedPercent, cfg.DiskThreshold)))
+
+	if prev != nil {
+		// Tốc độ mạng cần hai mẫu, xu hướng so với tốc độ của mẫu trước (lệch 10%)
+		hasRate := prev.sendBS > 0 || prev.recvBS > 0
+		sb.WriteString(p.T("live.network",
+			p.Bytes(uint64(cur.sendBS)), trendArrow(prev.sendBS, cur.sendBS, prev.sendBS*0.1, hasRate),
+			p.Bytes(uint64(cur.recvBS)), trendArrow(prev.recvBS, cur.recvBS, prev.recvBS*0.1, hasRate)) + "\n")
+	}
+
+	if info.WiFi != nil && info.WiFi.Connected {
+		var signalPrev float64
+		ok := last != nil && last.WiFi != nil && last.WiFi.Connected
+		if ok {
+			signalPrev = last.WiFi.SignalDBm
+		}
+		weak := ""
+		if services.WeakWiFiSignal(info.WiFi.SignalDBm, cfg.WiFiSignalThreshold) {
+			weak = " ⚠️"
+		}
+		sb.WriteString(fmt.Sprintf("📶 Wi-Fi: %s dBm%s%s\n", p.Number(info.WiFi.SignalDBm, 0), trendArrow(signalPrev, info.WiFi.SignalDBm, 2, ok), weak))
+	} else if info.WiFi != nil {
+		sb.WriteString(p.T("live.wifi_disconnected") + "\n")
+	}
+
+	sb.WriteString("\n" + p.T("live.footer", p.Time(cur.time), remaining.Round(time.Second)))
+	return sb.String()
+}
+
+// trendArrow so sánh giá trị với mẫu trước: ↗ tăng, ↘ giảm, → thay đổi không quá epsilon
+func trendArrow(prev, cur, epsilon float64, hasPrev bool) string {
+	switch {
+	case !hasPrev:
+		return ""
+	case cur-prev > epsilon:
+		return " ↗"
+	case prev-cur > epsilon:
+		return " ↘"
+	default:
+		return " →"
+	}
+}
+
+// isNotModified kiểm tra lỗi Telegram trả về khi nội dung tin nhắn không đổi
+func isNotModified(err error) bool {
+	return strings.Contains(err.Error(), "message is not modified")
+}
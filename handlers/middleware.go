@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"pi-monitor/config"
+	"pi-monitor/i18n"
+	"pi-monitor/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Recover bắt panic trong handler để một lệnh lỗi không làm dừng bot
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					log.Printf("💥 Panic in /%s: %v\n%s", req.Name(), p, debug.Stack())
+					notifyError(req, req.printer().T("error.internal"))
+					err = fmt.Errorf("panic: %v", p)
+				}
+			}()
+			return next(ctx, req)
+		}
+	}
+}
+
+// Logging ghi log mỗi lệnh kèm người gửi, thời gian xử lý và lỗi (nếu có).
+// Lỗi đã ghi log không được trả tiếp cho router.
+func Logging() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) error {
+			start := time.Now()
+			err := next(ctx, req)
+			elapsed := time.Since(start).Round(time.Millisecond)
+
+			if err != nil {
+				log.Printf("❌ /%s from user %d (@%s) in chat %d failed after %v: %v", req.Name(), req.UserID(), req.UserName(), req.ChatID(), elapsed, err)
+			} else {
+				log.Printf("📨 /%s from user %d (@%s) in chat %d handled in %v", req.Name(), req.UserID(), req.UserName(), req.ChatID(), elapsed)
+			}
+			return nil
+		}
+	}
+}
+
+// Locale chọn ngôn ngữ trả lời người gửi và điền req.Printer: ngôn ngữ đã chọn bằng /lang,
+// rồi language_code Telegram gửi kèm, rồi language trong cấu hình. Không ghi gì xuống file (xem DetectLanguage).
+func Locale(langs *services.LanguageStore) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) error {
+			from := req.From()
+			lang := langs.ResolveCode(req.ChatID(), from.ID, from.LanguageCode, req.Config.DefaultLang())
+			req.Printer = i18n.NewPrinter(lang, req.Config.Location)
+			return next(ctx, req)
+		}
+	}
+}
+
+// DetectLanguage ghi nhận language_code của người gửi để dùng cho thông báo.
+// Đặt sau Auth để không lưu ngôn ngữ của người lạ.
+func DetectLanguage(langs *services.LanguageStore) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) error {
+			from := req.From()
+			langs.Detect(from.ID, from.LanguageCode)
+			return next(ctx, req)
+		}
+	}
+}
+
+// Auth kiểm tra người gửi có trong danh sách user và đủ quyền (roleFor) để chạy lệnh, điền req.Role
+func Auth(roleFor func(req *Request) config.Role) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) error {
+			userID := req.UserID()
+			p := req.printer()
+
+			req.Role = req.Config.RoleOf(userID)
+			if req.Role == config.RoleNone {
+				log.Printf("🚫 Unauthorized access attempt from user %d (@%s) in chat %d", userID, req.UserName(), req.ChatID())
+				if req.Callback != nil {
+					req.Bot.Request(tgbotapi.NewCallback(req.Callback.ID, p.Text("auth.unauthorized")))
+					return nil
+				}
+				msg := tgbotapi.NewMessage(req.ChatID(), p.T("auth.unauthorized")+"\n\n"+p.T("auth.user_id", userID))
+				return req.Reply(ctx, msg)
+			}
+
+			if required := roleFor(req); !req.Role.Allows(required) {
+				log.Printf("🚫 User %d (@%s, %s) denied /%s (requires %s)", userID, req.UserName(), req.Role, req.Name(), required)
+				if req.Callback != nil {
+					req.Bot.Request(tgbotapi.NewCallback(req.Callback.ID, p.Text("auth.role_required", required)))
+					return nil
+				}
+				msg := tgbotapi.NewMessage(req.ChatID(), p.T("auth.command_role_required", req.Name(), required, req.Role))
+				return req.Reply(ctx, msg)
+			}
+
+			return next(ctx, req)
+		}
+	}
+}
+
+// RateLimit giới hạn mỗi user tối đa burst lệnh liên tiếp, sau đó một lệnh mỗi khoảng every (token bucket)
+func RateLimit(every time.Duration, burst int) Middleware {
+	type bucket struct {
+		tokens float64
+		last   time.Time
+	}
+	var mu sync.Mutex
+	buckets := make(map[int64]*bucket)
+
+	allow := func(userID int64) (bool, time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := time.Now()
+		b, ok := buckets[userID]
+		if !ok {
+			b = &bucket{tokens: float64(burst), last: now}
+			buckets[userID] = b
+		}
+		b.tokens = min(float64(burst), b.tokens+float64(now.Sub(b.last))/float64(every))
+		b.last = now
+
+		if b.tokens < 1 {
+			return false, time.Duration((1 - b.tokens) * float64(every))
+		}
+		b.tokens--
+		return true, 0
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) error {
+			if ok, wait := allow(req.UserID()); !ok {
+				log.Printf("🐢 Rate limited user %d (@%s) on /%s", req.UserID(), req.UserName(), req.Name())
+				text := req.printer().T("error.rate_limited", wait.Round(time.Second)+time.Second)
+				if req.Callback != nil {
+					req.Bot.Request(tgbotapi.NewCallback(req.Callback.ID, i18n.PlainText(text)))
+					return nil
+				}
+				return req.Reply(ctx, tgbotapi.NewMessage(req.ChatID(), text))
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// Timeout giới hạn thời gian xử lý mỗi lệnh (mặc định def, riêng từng lệnh theo perCommand).
+// Hết thời gian thì báo cho người dùng; handler vẫn chạy nốt nhưng không gửi kết quả qua Request.Reply.
+func Timeout(def time.Duration, perCommand map[string]time.Duration) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, req *Request) error {
+			timeout := def
+			if d, ok := perCommand[req.Name()]; ok {
+				timeout = d
+			}
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				// Panic trong goroutine này không được Recover bắt, chuyển thành lỗi
+				defer func() {
+					if p := recover(); p != nil {
+						log.Printf("💥 Panic in /%s: %v\n%s", req.Name(), p, debug.Stack())
+						notifyError(req, req.printer().T("error.internal"))
+						done <- fmt.Errorf("panic: %v", p)
+					}
+				}()
+				done <- next(ctx, req)
+			}()
+
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				notifyError(req, req.printer().T("error.timeout", req.Name(), timeout))
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// notifyError báo lỗi cho người dùng (tin nhắn với lệnh, thông báo nhỏ với callback)
+func notifyError(req *Request, text string) {
+	if req.Callback != nil {
+		req.Bot.Request(tgbotapi.NewCallback(req.Callback.ID, i18n.PlainText(text)))
+		return
+	}
+	msg := tgbotapi.NewMessage(req.ChatID(), text)
+	if !req.Message.Chat.IsPrivate() {
+		msg.ReplyToMessageID = req.Message.MessageID
+	}
+	Send(req.Bot, msg)
+}
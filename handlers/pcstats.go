@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"pi-monitor/i18n"
+	"pi-monitor/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HandlePCStatsCommand xử lý lệnh /pcstats - thời gian sử dụng PC theo ngày/tuần
+func HandlePCStatsCommand(p *i18n.Printer, message *tgbotapi.Message, tracker *services.PowerTracker, enabled bool) tgbotapi.MessageConfig {
+	chatID := message.Chat.ID
+
+	if tracker == nil {
+		return tgbotapi.NewMessage(chatID, p.T("pcstats.not_configured"))
+	}
+
+	name := strings.TrimSpace(message.CommandArguments())
+	// Ngày được tính theo timezone trong cấu hình, không theo timezone của process (UTC trong Docker)
+	statuses := tracker.Status(time.Now().In(p.Location()))
+
+	var sb strings.Builder
+	sb.WriteString(p.T("pcstats.title"))
+
+	found := false
+	for _, st := range statuses {
+		if name != "" && !strings.EqualFold(st.Name, name) {
+			continue
+		}
+		found = true
+		sb.WriteString("\n" + formatPowerStatus(p, st))
+	}
+
+	if !found {
+		return tgbotapi.NewMessage(chatID, p.T("wake.not_found", name))
+	}
+
+	if !enabled {
+		sb.WriteString(p.T("pcstats.disabled"))
+	}
+
+	return tgbotapi.NewMessage(chatID, sb.String())
+}
+
+// formatPowerStatus format trạng thái và thời gian sử dụng 7 ngày của một PC
+func formatPowerStatus(p *i18n.Printer, st services.PowerStatus) string {
+	var sb strings.Builder
+
+	switch {
+	case !st.Known:
+		sb.WriteString(p.T("pcstats.unknown", st.Name))
+	case st.Online:
+		sb.WriteString(p.T("pcstats.online", st.Name, p.DayTime(st.Since), p.DurationShort(time.Since(st.Since))))
+	default:
+		sb.WriteString(p.T("pcstats.offline", st.Name, p.DayTime(st.Since)))
+	}
+
+	sb.WriteString(p.T("pcstats.today", p.DurationShort(st.Today)))
+	sb.WriteString(p.T("pcstats.week", p.DurationShort(st.Week)))
+
+	// Biểu đồ theo ngày, mỗi ô ~ 1 giờ (tối đa 12 ô)
+	for i, used := range st.PerDay {
+		prefix := "├"
+		if i == len(st.PerDay)-1 {
+			prefix = "└"
+		}
+		day := st.DayList[i]
+		bar := strings.Repeat("▇", int(used.Hours()+0.5))
+		if len([]rune(bar)) > 12 {
+			bar = string([]rune(bar)[:12])
+		}
+		sb.WriteString(fmt.Sprintf("%s <code>%s %s</code> %s %s\n", prefix, p.WeekdayShort(day.Weekday()), p.DateShort(day), bar, p.DurationShort(used)))
+	}
+
+	return sb.String()
+}
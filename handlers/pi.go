@@ -2,67 +2,102 @@ package handlers
 
 import (
 	"fmt"
+	"strings"
 
+	"pi-monitor/i18n"
 	"pi-monitor/services"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func HandlePiCommand(message *tgbotapi.Message) tgbotapi.MessageConfig {
+// HandlePiCommand xử lý lệnh /pi, kèm nút chuyển tin nhắn sang cập nhật liên tục (/live)
+func HandlePiCommand(p *i18n.Printer, message *tgbotapi.Message) tgbotapi.MessageConfig {
+	msg := systemInfoMessage(p, message.Chat.ID)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(LiveButton(p)))
+	return msg
+}
+
+// systemInfoMessage tạo tin nhắn thông tin hệ thống cho chat
+func systemInfoMessage(p *i18n.Printer, chatID int64) tgbotapi.MessageConfig {
 	info, err := services.GetSystemInfo()
 	if err != nil {
-		msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("❌ Lỗi khi lấy thông tin hệ thống: %v", err))
-		return msg
+		return tgbotapi.NewMessage(chatID, p.T("pi.error", err))
 	}
 
-	text := formatSystemInfo(info)
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
-	msg.ParseMode = "Markdown"
-	return msg
+	text := formatSystemInfo(p, info)
+	return tgbotapi.NewMessage(chatID, text)
 }
 
-func formatSystemInfo(info *services.SystemInfo) string {
-	return fmt.Sprintf(`🍓 *Raspberry Pi Status*
+func formatSystemInfo(p *i18n.Printer, info *services.SystemInfo) string {
+	return p.T("pi.status",
+		p.Percent(info.CPU.UsagePercent, 1),
+		p.Number(info.CPU.Temperature, 1),
+		info.CPU.Cores,
+		p.Number(info.CPU.Frequency, 0),
+		p.Bytes(info.Memory.Total),
+		p.Bytes(info.Memory.Used),
+		p.Percent(info.Memory.UsedPercent, 1),
+		p.Bytes(info.Memory.Available),
+		p.Bytes(info.Disk.Total),
+		p.Bytes(info.Disk.Used),
+		p.Percent(info.Disk.UsedPercent, 1),
+		p.Bytes(info.Disk.Free),
+		info.Network.IP,
+		p.Bytes(info.Network.Sent),
+		p.Bytes(info.Network.Recv),
+		i18n.HTML(formatWiFiInfo(p, info.WiFi)),
+		p.Duration(info.Uptime),
+		p.DateTime(info.Timestamp),
+	)
+}
 
-🖥️ *CPU*
-├ Sử dụng: %.1f%%
-├ Nhiệt độ: %.1f°C
-├ Cores: %d
-└ Tần số: %.0f MHz
+// formatWiFiInfo format thông tin Wi-Fi (HTML), trả về chuỗi rỗng nếu không có Wi-Fi
+func formatWiFiInfo(p *i18n.Printer, wifi *services.WiFiInfo) string {
+	if wifi == nil {
+		return ""
+	}
 
-💾 *RAM*
-├ Tổng: %s
-├ Đã dùng: %s (%.1f%%)
-└ Còn trống: %s
+	if !wifi.Connected {
+		return fmt.Sprintf("\n📶 <b>Wi-Fi</b> (%s)\n└ %s\n", i18n.Escape(wifi.Interface), p.T("pi.wifi.disconnected"))
+	}
 
-💿 *Disk*
-├ Tổng: %s
-├ Đã dùng: %s (%.1f%%)
-└ Còn trống: %s
+	var lines []string
+	if wifi.SSID != "" {
+		lines = append(lines, "SSID: "+i18n.Escape(wifi.SSID))
+	}
+	lines = append(lines, p.T("pi.wifi.signal", p.Number(wifi.SignalDBm, 0), signalLabel(p, wifi.SignalDBm)))
+	if wifi.LinkQuality > 0 {
+		lines = append(lines, p.T("pi.wifi.quality", p.Percent(wifi.LinkQuality, 0)))
+	}
+	if wifi.Bitrate != "" {
+		lines = append(lines, "Bitrate: "+i18n.Escape(wifi.Bitrate))
+	}
+	if wifi.Frequency > 0 {
+		lines = append(lines, p.T("pi.wifi.frequency", p.Number(wifi.Frequency, 0)))
+	}
 
-🌐 *Network*
-├ IP: %s
-├ Gửi: %s
-└ Nhận: %s
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n📶 <b>Wi-Fi</b> (%s)\n", i18n.Escape(wifi.Interface)))
+	for i, line := range lines {
+		if i == len(lines)-1 {
+			sb.WriteString("└ " + line + "\n")
+		} else {
+			sb.WriteString("├ " + line + "\n")
+		}
+	}
+	return sb.String()
+}
 
-⏱️ *Uptime*: %s
-🕐 *Cập nhật*: %s`,
-		info.CPU.UsagePercent,
-		info.CPU.Temperature,
-		info.CPU.Cores,
-		info.CPU.Frequency,
-		info.Memory.Total,
-		info.Memory.Used,
-		info.Memory.UsedPercent,
-		info.Memory.Available,
-		info.Disk.Total,
-		info.Disk.Used,
-		info.Disk.UsedPercent,
-		info.Disk.Free,
-		info.Network.IP,
-		info.Network.BytesSent,
-		info.Network.BytesRecv,
-		info.Uptime,
-		info.Timestamp,
-	)
+// signalLabel đánh giá cường độ tín hiệu Wi-Fi
+func signalLabel(p *i18n.Printer, dbm float64) string {
+	switch {
+	case dbm >= -55:
+		return p.T("pi.signal.excellent")
+	case dbm >= -67:
+		return p.T("pi.signal.good")
+	case dbm >= -75:
+		return p.T("pi.signal.fair")
+	default:
+		return p.T("pi.signal.weak")
+	}
 }
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"pi-monitor/config"
+	"pi-monitor/i18n"
+	"pi-monitor/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// powerCallbackPrefix là prefix của callback data cho nút xác nhận /sleep, /shutdown
+const powerCallbackPrefix = "power:"
+
+// powerActionLabels là tên hiển thị của các lệnh
+var powerActionLabels = map[services.AgentAction]string{
+	services.AgentSleep:    "💤 Sleep",
+	services.AgentShutdown: "⏻ Shutdown",
+}
+
+// HandlePowerCommand xử lý lệnh /sleep <name> và /shutdown <name> - hỏi xác nhận trước khi gửi lệnh
+func HandlePowerCommand(p *i18n.Printer, message *tgbotapi.Message, cfg *config.Config, action services.AgentAction) tgbotapi.MessageConfig {
+	chatID := message.Chat.ID
+
+	var agents []config.WOLTarget
+	for _, t := range cfg.WOLTargets {
+		if t.AgentURL != "" {
+			agents = append(agents, t)
+		}
+	}
+	if len(agents) == 0 {
+		return tgbotapi.NewMessage(chatID, p.T("power.not_configured"))
+	}
+
+	name := strings.TrimSpace(message.CommandArguments())
+	var target config.WOLTarget
+	switch {
+	case name == "" && len(agents) == 1:
+		target = agents[0]
+	case name == "":
+		return tgbotapi.NewMessage(chatID, p.T("power.syntax", action, targetNames(agents)))
+	default:
+		var ok bool
+		target, ok = cfg.FindWOLTarget(name)
+		if !ok {
+			return tgbotapi.NewMessage(chatID, p.T("wake.not_found_hint", name, targetNames(agents)))
+		}
+		if target.AgentURL == "" {
+			return tgbotapi.NewMessage(chatID, p.T("power.no_agent", target.Name))
+		}
+	}
+
+	text := p.T("power.confirm", powerActionLabels[action], target.Name, target.AgentURL)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(p.T("power.button.confirm"), fmt.Sprintf("%s%s:%s", powerCallbackPrefix, action, target.Name)),
+		tgbotapi.NewInlineKeyboardButtonData(p.T("power.button.cancel"), powerCallbackPrefix+"cancel"),
+	))
+	return msg
+}
+
+// HandlePowerCallback gửi lệnh sleep/shutdown đến pc-agent sau khi người dùng xác nhận
+func HandlePowerCallback(p *i18n.Printer, query *tgbotapi.CallbackQuery, cfg *config.Config) tgbotapi.MessageConfig {
+	chatID := query.Message.Chat.ID
+	data := strings.TrimPrefix(query.Data, powerCallbackPrefix)
+
+	if data == "cancel" {
+		return tgbotapi.NewMessage(chatID, p.T("common.cancelled"))
+	}
+
+	parts := strings.SplitN(data, ":", 2)
+	if len(parts) != 2 {
+		return tgbotapi.NewMessage(chatID, p.T("common.invalid_button"))
+	}
+	action := services.AgentAction(parts[0])
+	if _, ok := powerActionLabels[action]; !ok {
+		return tgbotapi.NewMessage(chatID, p.T("common.invalid_button"))
+	}
+
+	target, ok := cfg.FindWOLTarget(parts[1])
+	if !ok || target.AgentURL == "" {
+		return tgbotapi.NewMessage(chatID, p.T("wake.not_found", parts[1]))
+	}
+
+	resp, err := services.NewAgentClient(target.AgentURL, target.AgentSecret).Send(action)
+	if err != nil {
+		text := p.T("power.failed", action, target.Name, p.Err(err))
+		return tgbotapi.NewMessage(chatID, text)
+	}
+
+	text := p.T("power.sent", action, target.Name, valueOrDash(resp.Hostname), resp.Message)
+	return tgbotapi.NewMessage(chatID, text)
+}
+
+// IsPowerCallback kiểm tra callback data có thuộc nút xác nhận /sleep, /shutdown không
+func IsPowerCallback(data string) bool {
+	return strings.HasPrefix(data, powerCallbackPrefix)
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"pi-monitor/config"
+	"pi-monitor/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Request là một lệnh (hoặc một lần bấm nút inline keyboard) cần xử lý
+type Request struct {
+	Bot      *tgbotapi.BotAPI
+	Config   *config.Config          // Cấu hình tại thời điểm nhận update
+	Message  *tgbotapi.Message       // Lệnh, nil với callback
+	Callback *tgbotapi.CallbackQuery // Nút inline keyboard, nil với lệnh
+	ThreadID int                     // Forum topic của lệnh (0 = chat chính)
+	Role     config.Role             // Quyền của người gửi, điền bởi middleware Auth
+	Printer  *i18n.Printer           // Ngôn ngữ trả lời người gửi, điền bởi middleware Locale
+}
+
+// UserID trả về ID người gửi
+func (r *Request) UserID() int64 {
+	if r.Callback != nil {
+		return r.Callback.From.ID
+	}
+	return r.Message.From.ID
+}
+
+// UserName trả về username người gửi
+func (r *Request) UserName() string {
+	if r.Callback != nil {
+		return r.Callback.From.UserName
+	}
+	return r.Message.From.UserName
+}
+
+// From trả về người gửi
+func (r *Request) From() *tgbotapi.User {
+	if r.Callback != nil {
+		return r.Callback.From
+	}
+	return r.Message.From
+}
+
+// printer trả về Printer của request, ngôn ngữ mặc định khi middleware Locale chưa chạy
+func (r *Request) printer() *i18n.Printer {
+	if r.Printer != nil {
+		return r.Printer
+	}
+	return i18n.NewPrinter(r.Config.DefaultLang(), r.Config.Location)
+}
+
+// ChatID trả về chat của lệnh (hoặc của tin nhắn chứa nút)
+func (r *Request) ChatID() int64 {
+	if r.Callback != nil {
+		if r.Callback.Message != nil {
+			return r.Callback.Message.Chat.ID
+		}
+		return r.Callback.From.ID
+	}
+	return r.Message.Chat.ID
+}
+
+// Name trả về tên lệnh (vd: pi) hoặc "callback"
+func (r *Request) Name() string {
+	if r.Callback != nil {
+		return "callback"
+	}
+	return r.Message.Command()
+}
+
+// Reply gửi tin nhắn trả lời. Trong group, tin nhắn trả lời vào lệnh để nằm đúng forum topic.
+// Không gửi nếu ctx đã hết hạn (middleware Timeout đã báo lỗi cho người dùng).
+func (r *Request) Reply(ctx context.Context, msg tgbotapi.MessageConfig) error {
+	_, err := r.Send(ctx, msg)
+	return err
+}
+
+// Send giống Reply nhưng trả về tin nhắn đã gửi (để sửa lại sau)
+func (r *Request) Send(ctx context.Context, msg tgbotapi.MessageConfig) (tgbotapi.Message, error) {
+	if err := ctx.Err(); err != nil {
+		return tgbotapi.Message{}, err
+	}
+	if r.Message != nil && !r.Message.Chat.IsPrivate() && msg.ReplyToMessageID == 0 {
+		msg.ReplyToMessageID = r.Message.MessageID
+	}
+	return Send(r.Bot, msg)
+}
+
+// HandlerFunc xử lý một Request
+type HandlerFunc func(ctx context.Context, req *Request) error
+
+// Middleware bọc HandlerFunc để thêm xử lý chung (auth, log, timeout...)
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Reply chuyển handler trả về MessageConfig thành HandlerFunc gửi tin nhắn đó
+func Reply(h func(req *Request) tgbotapi.MessageConfig) HandlerFunc {
+	return func(ctx context.Context, req *Request) error {
+		return req.Reply(ctx, h(req))
+	}
+}
+
+// Router chọn handler theo tên lệnh và chạy song song bằng một nhóm worker có giới hạn
+type Router struct {
+	routes     map[string]HandlerFunc
+	commands   []Command
+	callback   HandlerFunc
+	fallback   HandlerFunc
+	middleware []Middleware
+
+	queue chan *Request
+	wg    sync.WaitGroup
+}
+
+// NewRouter tạo router với workers worker và hàng đợi tối đa queueSize request
+func NewRouter(workers, queueSize int) *Router {
+	r := &Router{
+		routes: make(map[string]HandlerFunc),
+		queue:  make(chan *Request, queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		r.wg.Add(1)
+		go r.worker()
+	}
+	return r
+}
+
+// Use thêm middleware áp dụng cho tất cả lệnh và callback (middleware thêm trước bọc ngoài cùng)
+func (r *Router) Use(mw ...Middleware) {
+	r.middleware = append(r.middleware, mw...)
+}
+
+// Handle đăng ký handler cho lệnh /command
+func (r *Router) Handle(command string, h HandlerFunc) {
+	r.routes[command] = h
+}
+
+// HandleCallback đăng ký handler cho nút inline keyboard
+func (r *Router) HandleCallback(h HandlerFunc) {
+	r.callback = h
+}
+
+// NotFound đăng ký handler cho lệnh không có trong danh sách
+func (r *Router) NotFound(h HandlerFunc) {
+	r.fallback = h
+}
+
+// Dispatch đưa request vào hàng đợi. Trả về false nếu hàng đợi đầy.
+func (r *Router) Dispatch(req *Request) bool {
+	select {
+	case r.queue <- req:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close dừng nhận request mới và chờ các request đang xử lý xong
+func (r *Router) Close() {
+	close(r.queue)
+	r.wg.Wait()
+}
+
+// worker xử lý request từ hàng đợi
+func (r *Router) worker() {
+	defer r.wg.Done()
+	for req := range r.queue {
+		h := r.route(req)
+		if h == nil {
+			continue
+		}
+		for i := len(r.middleware) - 1; i >= 0; i-- {
+			h = r.middleware[i](h)
+		}
+		if err := h(context.Background(), req); err != nil {
+			log.Printf("❌ /%s from user %d failed: %v", req.Name(), req.UserID(), err)
+		}
+	}
+}
+
+// route chọn handler cho request
+func (r *Router) route(req *Request) HandlerFunc {
+	if req.Callback != nil {
+		return r.callback
+	}
+	if h, ok := r.routes[req.Message.Command()]; ok {
+		return h
+	}
+	return r.fallback
+}
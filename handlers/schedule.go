@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"pi-monitor/config"
+	"pi-monitor/i18n"
+	"pi-monitor/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HandleScheduleCommand xử lý lệnh /schedule add|list|rm.
+// Admin thấy và xoá được mọi job; người khác chỉ thấy job của chat hiện tại và chỉ xoá job do mình tạo.
+func HandleScheduleCommand(p *i18n.Printer, message *tgbotapi.Message, scheduler *services.Scheduler, cfg *config.Config) tgbotapi.MessageConfig {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+	isAdmin := cfg.RoleOf(message.From.ID).Allows(config.RoleAdmin)
+
+	if len(args) == 0 {
+		return tgbotapi.NewMessage(chatID, p.T("schedule.usage"))
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "add":
+		spec, command, err := parseScheduleArgs(args[1:])
+		if err == nil {
+			err = validateJobCommand(command, cfg)
+		}
+		if err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("common.error", p.Err(err))+"\n\n"+p.T("schedule.usage"))
+		}
+
+		job, err := scheduler.Add(spec, command, chatID, message.From.ID)
+		if err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("common.error", p.Err(err)))
+		}
+
+		text := p.T("schedule.added",
+			job.ID, job.Spec, job.Command, p.DateTimeShort(job.Next), job.Next.In(p.Location()).Format("MST"),
+		)
+		return tgbotapi.NewMessage(chatID, text)
+
+	case "list", "ls":
+		return tgbotapi.NewMessage(chatID, formatJobList(p, scheduler, func(job services.ScheduledJob) bool {
+			return isAdmin || job.ChatID == chatID
+		}))
+
+	case "rm", "remove", "del":
+		if len(args) < 2 {
+			return tgbotapi.NewMessage(chatID, p.T("schedule.rm_syntax"))
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(args[1], "#"))
+		if err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("schedule.invalid_id"))
+		}
+		if job, ok := scheduler.Get(id); ok && !isAdmin && job.CreatedBy != message.From.ID {
+			return tgbotapi.NewMessage(chatID, p.T("common.error", p.Err(i18n.Errorf("schedule.err.not_owner", id))))
+		}
+		if err := scheduler.Remove(id); err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("common.error", p.Err(err)))
+		}
+		return tgbotapi.NewMessage(chatID, p.T("schedule.removed", id))
+
+	default:
+		return tgbotapi.NewMessage(chatID, p.T("schedule.usage"))
+	}
+}
+
+// parseScheduleArgs tách biểu thức cron và lệnh từ tham số của /schedule add
+func parseScheduleArgs(args []string) (spec, command string, err error) {
+	n := 5
+	if len(args) > 0 && strings.HasPrefix(args[0], "@") {
+		n = 1
+		if args[0] == "@every" {
+			n = 2
+		}
+	}
+	if len(args) <= n {
+		return "", "", i18n.Errorf("schedule.err.missing_spec")
+	}
+
+	spec = strings.Join(args[:n], " ")
+	if err := services.ParseCronSpec(spec); err != nil {
+		return "", "", i18n.Errorf("schedule.err.cron", err)
+	}
+
+	command = strings.TrimPrefix(strings.Join(args[n:], " "), "/")
+	return spec, command, nil
+}
+
+// validateJobCommand kiểm tra lệnh có thể chạy theo lịch
+func validateJobCommand(command string, cfg *config.Config) error {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return i18n.Errorf("schedule.err.missing_command")
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "wake":
+		if len(fields) < 2 {
+			if len(cfg.WOLTargets) == 1 {
+				return nil
+			}
+			return i18n.Errorf("schedule.err.need_target")
+		}
+		if _, ok := cfg.FindWOLTarget(fields[1]); !ok {
+			return i18n.Errorf("schedule.err.target_not_found", fields[1])
+		}
+		return nil
+	case "status", "pi", "disk":
+		return nil
+	default:
+		return i18n.Errorf("schedule.err.unsupported", fields[0])
+	}
+}
+
+// RunScheduledJob thực hiện job và gửi kết quả đến chat đã tạo job.
+// Job của người đã bị xoá hoặc hạ quyền dưới operator được bỏ qua (lỗi hiện trong /schedule list).
+func RunScheduledJob(p *i18n.Printer, bot *tgbotapi.BotAPI, cfg *config.Config, job services.ScheduledJob, watcher *WakeWatcher) error {
+	if !cfg.RoleOf(job.CreatedBy).Allows(config.RoleOperator) {
+		return i18n.Errorf("schedule.err.creator_role", job.CreatedBy)
+	}
+
+	header := p.T("schedule.job_header", job.ID, job.Spec)
+	fields := strings.Fields(job.Command)
+	if len(fields) == 0 {
+		return i18n.Errorf("schedule.err.empty")
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "wake":
+		var target config.WOLTarget
+		var ok bool
+		if len(fields) >= 2 {
+			target, ok = cfg.FindWOLTarget(fields[1])
+		} else if len(cfg.WOLTargets) == 1 {
+			target, ok = cfg.WOLTargets[0], true
+		}
+		if !ok {
+			err := i18n.Errorf("schedule.err.job_target", job.Command)
+			sendJobText(bot, job.ChatID, header+p.T("common.error", p.Err(err)))
+			return err
+		}
+
+		sendJobText(bot, job.ChatID, header+p.T("schedule.waking", target.Name))
+		return wakeTarget(p, bot, job.ChatID, target, cfg, watcher)
+
+	case "status", "pi":
+		msg := systemInfoMessage(p, job.ChatID)
+		msg.Text = header + msg.Text
+		return sendJob(bot, msg)
+
+	case "disk":
+		info, err := services.GetSystemInfo()
+		if err != nil {
+			sendJobText(bot, job.ChatID, header+p.T("pi.error", err))
+			return err
+		}
+
+		status := p.T("schedule.disk_ok")
+		if info.Disk.UsedPercent > cfg.DiskThreshold {
+			status = p.T("schedule.disk_over", p.Percent(cfg.DiskThreshold, 0))
+		}
+		text := header + p.T("schedule.disk",
+			p.Bytes(info.Disk.Total), p.Bytes(info.Disk.Used), p.Percent(info.Disk.UsedPercent, 1), p.Bytes(info.Disk.Free), i18n.HTML(status),
+		)
+		return sendJobText(bot, job.ChatID, text)
+
+	default:
+		err := i18n.Errorf("schedule.err.job_unsupported", fields[0])
+		sendJobText(bot, job.ChatID, header+p.T("common.error", p.Err(err)))
+		return err
+	}
+}
+
+// formatJobList format danh sách job, chỉ gồm các job visible trả về true
+func formatJobList(p *i18n.Printer, scheduler *services.Scheduler, visible func(services.ScheduledJob) bool) string {
+	var jobs []services.ScheduledJob
+	for _, job := range scheduler.List() {
+		if visible(job) {
+			jobs = append(jobs, job)
+		}
+	}
+	if len(jobs) == 0 {
+		return p.T("schedule.list_empty")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(p.T("schedule.list_title", scheduler.Location()))
+	for _, job := range jobs {
+		sb.WriteString(fmt.Sprintf("\n<b>#%d</b> <code>%s</code> → <code>%s</code>\n", job.ID, i18n.Escape(job.Spec), i18n.Escape(job.Command)))
+		sb.WriteString(p.T("schedule.next", p.DayTime(job.Next)))
+		switch {
+		case job.LastRun.IsZero():
+			sb.WriteString(p.T("schedule.never_run"))
+		case job.LastError != "":
+			sb.WriteString(p.T("schedule.last_failed", p.DayTime(job.LastRun), job.LastError))
+		default:
+			sb.WriteString(p.T("schedule.last_ok", p.DayTime(job.LastRun)))
+		}
+	}
+	return sb.String()
+}
+
+// sendJobText gửi kết quả job (HTML)
+func sendJobText(bot *tgbotapi.BotAPI, chatID int64, text string) error {
+	msg := tgbotapi.NewMessage(chatID, text)
+	return sendJob(bot, msg)
+}
+
+// sendJob gửi tin nhắn kết quả job
+func sendJob(bot *tgbotapi.BotAPI, msg tgbotapi.MessageConfig) error {
+	if _, err := Send(bot, msg); err != nil {
+		return i18n.Errorf("schedule.err.send", err)
+	}
+	return nil
+}
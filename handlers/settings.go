@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"pi-monitor/config"
+	"pi-monitor/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// settingsCallbackPrefix là prefix của callback data cho menu cài đặt.
+// Dạng: set:menu, set:k:<key> (xem một giá trị), set:a:<key>:<delta> (tăng/giảm), set:r:<key> (về mặc định)
+const settingsCallbackPrefix = "set:"
+
+// HandleSetCommand xử lý lệnh /set, /set <key> <giá trị> và /set <key> reset
+func HandleSetCommand(p *i18n.Printer, message *tgbotapi.Message, cfg *config.Config, overrides *config.Overrides, update ConfigUpdater) tgbotapi.MessageConfig {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+
+	if len(args) == 0 {
+		msg := tgbotapi.NewMessage(chatID, formatSettings(p, cfg, overrides)+"\n\n"+p.T("set.usage"))
+		msg.ReplyMarkup = settingsMenuKeyboard(p)
+		return msg
+	}
+
+	s, ok := config.FindSetting(args[0])
+	if !ok {
+		return tgbotapi.NewMessage(chatID, p.T("set.unknown", args[0], i18n.HTML(settingKeys())))
+	}
+	if len(args) < 2 {
+		msg := tgbotapi.NewMessage(chatID, formatSetting(p, s, cfg, overrides, ""))
+		msg.ReplyMarkup = settingKeyboard(p, s)
+		return msg
+	}
+
+	var next *config.Config
+	var err error
+	if strings.EqualFold(args[1], "reset") {
+		next, err = update(func(c *config.Config) (*config.Config, error) { return overrides.Reset(c, s) })
+	} else {
+		var v float64
+		v, err = s.Parse(strings.Join(args[1:], ""))
+		if err == nil {
+			next, err = update(func(c *config.Config) (*config.Config, error) { return overrides.Set(c, s, v) })
+		}
+	}
+	if err != nil {
+		return tgbotapi.NewMessage(chatID, p.T("common.error", p.Err(err)))
+	}
+
+	text := p.T("set.updated", settingLabel(p, s), settingValue(p, s, s.Get(next)))
+	if strings.EqualFold(args[1], "reset") {
+		text = p.T("set.reset", settingLabel(p, s), settingValue(p, s, s.Get(next)))
+	}
+	return tgbotapi.NewMessage(chatID, text)
+}
+
+// HandleSettingsCallback xử lý khi người dùng bấm nút trong menu cài đặt, trả về tin nhắn đã sửa
+func HandleSettingsCallback(p *i18n.Printer, query *tgbotapi.CallbackQuery, cfg *config.Config, overrides *config.Overrides, update ConfigUpdater) tgbotapi.EditMessageTextConfig {
+	chatID := query.Message.Chat.ID
+	messageID := query.Message.MessageID
+	parts := strings.Split(strings.TrimPrefix(query.Data, settingsCallbackPrefix), ":")
+
+	if parts[0] == "menu" || len(parts) < 2 {
+		return tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, formatSettings(p, cfg, overrides), settingsMenuKeyboard(p))
+	}
+
+	s, ok := config.FindSetting(parts[1])
+	if !ok {
+		return tgbotapi.NewEditMessageText(chatID, messageID, p.T("common.invalid_button"))
+	}
+
+	var note string
+	switch parts[0] {
+	case "a":
+		if len(parts) != 3 {
+			return tgbotapi.NewEditMessageText(chatID, messageID, p.T("common.invalid_button"))
+		}
+		delta, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil {
+			return tgbotapi.NewEditMessageText(chatID, messageID, p.T("common.invalid_button"))
+		}
+		next, err := update(func(c *config.Config) (*config.Config, error) {
+			v := math.Max(s.Min, math.Min(s.Max, s.Get(c)+delta))
+			return overrides.Set(c, s, v)
+		})
+		if err != nil {
+			note = p.T("common.error", p.Err(err))
+		} else {
+			cfg = next
+		}
+	case "r":
+		next, err := update(func(c *config.Config) (*config.Config, error) { return overrides.Reset(c, s) })
+		if err != nil {
+			note = p.T("common.error", p.Err(err))
+		} else {
+			cfg = next
+			note = p.T("set.reset_note")
+		}
+	}
+
+	return tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, formatSetting(p, s, cfg, overrides, note), settingKeyboard(p, s))
+}
+
+// IsSettingsCallback kiểm tra callback data có thuộc menu cài đặt không
+func IsSettingsCallback(data string) bool {
+	return strings.HasPrefix(data, settingsCallbackPrefix)
+}
+
+// SettingsButton là nút mở menu cài đặt (dùng trong /alert)
+func SettingsButton(p *i18n.Printer) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData(p.T("set.button"), settingsCallbackPrefix+"menu")
+}
+
+// settingLabel trả về tên hiển thị của giá trị
+func settingLabel(p *i18n.Printer, s config.Setting) string {
+	return p.T("setting." + s.Key)
+}
+
+// settingValue format giá trị kèm đơn vị, số lần thì thêm "lần" theo ngôn ngữ
+func settingValue(p *i18n.Printer, s config.Setting, v float64) string {
+	if s.Count {
+		return p.Plural(int64(v), "unit.time")
+	}
+	return s.Format(v)
+}
+
+// formatSettings liệt kê các giá trị hiện tại, ✏️ đánh dấu giá trị đã đổi bằng /set
+func formatSettings(p *i18n.Printer, cfg *config.Config, overrides *config.Overrides) string {
+	var sb strings.Builder
+	sb.WriteString(p.T("set.title") + "\n")
+	for _, s := range config.Settings {
+		mark := ""
+		if overrides.IsSet(s.Key) {
+			mark = " ✏️"
+		}
+		sb.WriteString(fmt.Sprintf("\n%s: <code>%s</code>%s", settingLabel(p, s), settingValue(p, s, s.Get(cfg)), mark))
+	}
+	sb.WriteString("\n\n" + p.T("set.overridden_note"))
+	return sb.String()
+}
+
+// formatSetting hiển thị chi tiết một giá trị
+func formatSetting(p *i18n.Printer, s config.Setting, cfg *config.Config, overrides *config.Overrides, note string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s <code>%s</code>\n\n", settingLabel(p, s), s.Key))
+	sb.WriteString(p.T("set.current", settingValue(p, s, s.Get(cfg))) + "\n")
+	if def, ok := overrides.Default(s); ok {
+		sb.WriteString(p.T("set.configured", settingValue(p, s, def)) + "\n")
+	}
+	sb.WriteString(p.T("set.range", settingValue(p, s, s.Min), settingValue(p, s, s.Max)))
+	if note != "" {
+		sb.WriteString("\n\n" + note)
+	}
+	return sb.String()
+}
+
+// settingsMenuKeyboard tạo inline keyboard gồm các giá trị, 2 nút mỗi hàng
+func settingsMenuKeyboard(p *i18n.Printer) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(config.Settings); i += 2 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, s := range config.Settings[i:min(i+2, len(config.Settings))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(settingLabel(p, s), settingsCallbackPrefix+"k:"+s.Key))
+		}
+		rows = append(rows, row)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// settingKeyboard tạo các nút tăng/giảm (±Step, ±5×Step) cho một giá trị
+func settingKeyboard(p *i18n.Printer, s config.Setting) tgbotapi.InlineKeyboardMarkup {
+	adjust := func(delta float64) tgbotapi.InlineKeyboardButton {
+		label := s.Format(math.Abs(delta))
+		if delta < 0 {
+			label = "−" + label
+		} else {
+			label = "+" + label
+		}
+		return tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("%sa:%s:%s", settingsCallbackPrefix, s.Key, strconv.FormatFloat(delta, 'f', -1, 64)))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(adjust(-5*s.Step), adjust(-s.Step), adjust(s.Step), adjust(5*s.Step)),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(p.T("set.button.default"), settingsCallbackPrefix+"r:"+s.Key),
+			tgbotapi.NewInlineKeyboardButtonData(p.T("common.back"), settingsCallbackPrefix+"menu"),
+		),
+	)
+}
+
+// settingKeys liệt kê key của các cài đặt (HTML)
+func settingKeys() string {
+	keys := make([]string, len(config.Settings))
+	for i, s := range config.Settings {
+		keys[i] = "<code>" + s.Key + "</code>"
+	}
+	return strings.Join(keys, ", ")
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"pi-monitor/config"
+	"pi-monitor/i18n"
+	"pi-monitor/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// HandleSubscribeCommand xử lý lệnh /subscribe cho chat hiện tại (hoặc topic threadID) và channel/group khác
+func HandleSubscribeCommand(p *i18n.Printer, bot *tgbotapi.BotAPI, message *tgbotapi.Message, threadID int, cfg *config.Config, subs *services.SubscriptionStore) tgbotapi.MessageConfig {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+	here := services.Destination{ChatID: chatID, ThreadID: threadID}
+
+	if len(args) == 0 {
+		text := formatSubscription(p, here, message.Chat.IsPrivate(), subs) + "\n\n" + p.T("subscribe.usage")
+		return tgbotapi.NewMessage(chatID, text)
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list", "ls":
+		return tgbotapi.NewMessage(chatID, formatSubscriptionList(p, cfg, subs))
+
+	case "chat":
+		if len(args) < 2 {
+			return tgbotapi.NewMessage(chatID, p.T("subscribe.usage"))
+		}
+		sub, err := parseSubscription(args[2:])
+		if err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("common.error", p.Err(err)))
+		}
+
+		// Kiểm tra bot có trong chat và gửi được tin nhắn không
+		chat, err := bot.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: chatConfig(args[1])})
+		if err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("subscribe.chat_not_found", args[1], err))
+		}
+		confirm := tgbotapi.NewMessage(chat.ID, p.T("subscribe.confirm", i18n.HTML(formatTypes(p, sub))))
+		if _, err := Send(bot, confirm); err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("subscribe.cannot_send", chatTitle(&chat), err))
+		}
+
+		sub.ChatID = chat.ID
+		sub.Title = chatTitle(&chat)
+		sub.AddedBy = message.From.ID
+		if err := subs.Set(sub); err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("subscribe.save_failed", err))
+		}
+		return tgbotapi.NewMessage(chatID, p.T("subscribe.subscribed", sub.Title, sub.ChatID, i18n.HTML(formatTypes(p, sub))))
+
+	default:
+		sub, err := parseSubscription(args)
+		if err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("subscribe.invalid", p.Err(err)))
+		}
+		sub.ChatID = chatID
+		sub.ThreadID = threadID
+		sub.Title = chatTitle(message.Chat)
+		sub.AddedBy = message.From.ID
+		if err := subs.Set(sub); err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("subscribe.save_failed", err))
+		}
+		return tgbotapi.NewMessage(chatID, p.T("subscribe.updated")+"\n\n"+formatTypes(p, sub))
+	}
+}
+
+// HandleUnsubscribeCommand xử lý lệnh /unsubscribe [chat_id].
+// Trong chat riêng, huỷ đăng ký nghĩa là không nhận thông báo nào.
+func HandleUnsubscribeCommand(p *i18n.Printer, message *tgbotapi.Message, threadID int, subs *services.SubscriptionStore) tgbotapi.MessageConfig {
+	chatID := message.Chat.ID
+	dest := services.Destination{ChatID: chatID, ThreadID: threadID}
+
+	if arg := strings.TrimSpace(message.CommandArguments()); arg != "" {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("unsubscribe.syntax"))
+		}
+		dest = services.Destination{ChatID: id}
+		// Chat có topic: huỷ đăng ký đầu tiên tìm được của chat đó
+		for _, sub := range subs.List() {
+			if sub.ChatID == id {
+				dest = sub.Destination()
+				break
+			}
+		}
+	} else if message.Chat.IsPrivate() {
+		sub := services.Subscription{ChatID: chatID, MinSeverity: services.SeverityInfo, AddedBy: message.From.ID}
+		if err := subs.Set(sub); err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("subscribe.save_failed", err))
+		}
+		return tgbotapi.NewMessage(chatID, p.T("unsubscribe.all_off"))
+	}
+
+	if err := subs.Remove(dest); err != nil {
+		return tgbotapi.NewMessage(chatID, p.T("common.error", p.Err(err)))
+	}
+	return tgbotapi.NewMessage(chatID, p.T("unsubscribe.done"))
+}
+
+// parseSubscription đọc danh sách loại thông báo và min=<mức độ>, không có loại nào = tất cả
+func parseSubscription(args []string) (services.Subscription, error) {
+	sub := services.Subscription{MinSeverity: services.SeverityInfo}
+
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(strings.ToLower(arg), "min="):
+			sev, err := services.ParseSeverity(arg[len("min="):])
+			if err != nil {
+				return sub, err
+			}
+			sub.MinSeverity = sev
+		case strings.EqualFold(arg, "all"):
+			sub.Types = append([]services.NotifyType(nil), services.NotifyTypes...)
+		default:
+			t, err := services.ParseNotifyType(arg)
+			if err != nil {
+				return sub, err
+			}
+			sub.Types = append(sub.Types, t)
+		}
+	}
+
+	if len(sub.Types) == 0 {
+		sub.Types = append([]services.NotifyType(nil), services.NotifyTypes...)
+	}
+	return sub, nil
+}
+
+// formatSubscription hiển thị đăng ký của một chat
+func formatSubscription(p *i18n.Printer, dest services.Destination, private bool, subs *services.SubscriptionStore) string {
+	sub, ok := subs.Get(dest)
+	switch {
+	case ok:
+		return p.T("subscribe.receiving") + "\n\n" + formatTypes(p, sub)
+	case private:
+		return p.T("subscribe.receiving_default")
+	default:
+		return p.T("subscribe.not_subscribed")
+	}
+}
+
+// formatSubscriptionList liệt kê group/channel đã đăng ký và người dùng đã đổi đăng ký
+func formatSubscriptionList(p *i18n.Printer, cfg *config.Config, subs *services.SubscriptionStore) string {
+	list := subs.List()
+	if len(list) == 0 {
+		return p.T("subscribe.list_empty")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(p.T("subscribe.list_title") + "\n")
+	for _, sub := range list {
+		name := i18n.Escape(sub.Title)
+		if sub.ChatID > 0 {
+			name = p.T("subscribe.private_chat")
+			if !cfg.IsUserAllowed(sub.ChatID) {
+				name += " " + p.T("subscribe.no_longer_allowed")
+			}
+		}
+		topic := ""
+		if sub.ThreadID != 0 {
+			topic = fmt.Sprintf(" · topic %d", sub.ThreadID)
+		}
+		sb.WriteString(fmt.Sprintf("\n<b>%s</b> <code>%d</code>%s\n%s\n", name, sub.ChatID, topic, formatTypes(p, sub)))
+	}
+	return sb.String()
+}
+
+// formatTypes hiển thị các loại thông báo và mức độ tối thiểu của đăng ký
+func formatTypes(p *i18n.Printer, sub services.Subscription) string {
+	if len(sub.Types) == 0 {
+		return p.T("subscribe.none")
+	}
+	labels := make([]string, len(sub.Types))
+	for i, t := range sub.Types {
+		labels[i] = p.T("notify." + string(t))
+	}
+	return p.T("subscribe.types", strings.Join(labels, ", "), sub.MinSeverity)
+}
+
+// chatConfig tạo ChatConfig từ chat ID (-100123...) hoặc @username
+func chatConfig(s string) tgbotapi.ChatConfig {
+	if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return tgbotapi.ChatConfig{ChatID: id}
+	}
+	if !strings.HasPrefix(s, "@") {
+		s = "@" + s
+	}
+	return tgbotapi.ChatConfig{SuperGroupUsername: s}
+}
+
+// chatTitle trả về tên hiển thị của chat
+func chatTitle(chat *tgbotapi.Chat) string {
+	switch {
+	case chat.Title != "":
+		return chat.Title
+	case chat.UserName != "":
+		return "@" + chat.UserName
+	default:
+		return strings.TrimSpace(chat.FirstName + " " + chat.LastName)
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"pi-monitor/config"
+	"pi-monitor/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ConfigUpdater áp dụng thay đổi lên cấu hình đang chạy (dùng cho /set, /user).
+// change nhận cấu hình hiện tại và trả về cấu hình mới (hoặc lỗi nếu không đổi được).
+type ConfigUpdater func(change func(*config.Config) (*config.Config, error)) (*config.Config, error)
+
+// roleIcons là biểu tượng hiển thị của các role
+var roleIcons = map[config.Role]string{
+	config.RoleAdmin:    "👑",
+	config.RoleOperator: "🛠️",
+	config.RoleViewer:   "👀",
+}
+
+// HandleUserCommand xử lý lệnh /user list|add|rm|role
+func HandleUserCommand(p *i18n.Printer, message *tgbotapi.Message, cfg *config.Config, users *config.UserStore, update ConfigUpdater) tgbotapi.MessageConfig {
+	chatID := message.Chat.ID
+	args := strings.Fields(message.CommandArguments())
+
+	if len(args) == 0 {
+		return tgbotapi.NewMessage(chatID, formatUserList(p, cfg)+"\n\n"+p.T("user.usage"))
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "list", "ls":
+		return tgbotapi.NewMessage(chatID, formatUserList(p, cfg))
+
+	case "add", "role":
+		if len(args) < 2 || (strings.ToLower(args[0]) == "role" && len(args) < 3) {
+			return tgbotapi.NewMessage(chatID, p.T("user.usage"))
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("user.invalid_id_hint"))
+		}
+		role := config.RoleViewer
+		if len(args) >= 3 {
+			if role, err = config.ParseRole(args[2]); err != nil {
+				return tgbotapi.NewMessage(chatID, p.T("common.error", p.Err(err)))
+			}
+		}
+
+		old := cfg.RoleOf(id)
+		if _, err := update(func(c *config.Config) (*config.Config, error) { return users.SetRole(c, id, role) }); err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("common.error", p.Err(err)))
+		}
+
+		text := p.T("user.added", id, roleIcons[role], role)
+		if old != config.RoleNone {
+			text = p.T("user.role_changed", id, old, roleIcons[role], role)
+		}
+		return tgbotapi.NewMessage(chatID, text)
+
+	case "rm", "remove", "del":
+		if len(args) < 2 {
+			return tgbotapi.NewMessage(chatID, p.T("user.rm_syntax"))
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("user.invalid_id"))
+		}
+		if _, err := update(func(c *config.Config) (*config.Config, error) { return users.Remove(c, id) }); err != nil {
+			return tgbotapi.NewMessage(chatID, p.T("common.error", p.Err(err)))
+		}
+		return tgbotapi.NewMessage(chatID, p.T("user.removed", id))
+
+	default:
+		return tgbotapi.NewMessage(chatID, p.T("user.usage"))
+	}
+}
+
+// formatUserList liệt kê người dùng theo role, ✏️ đánh dấu quyền đã đổi bằng /user
+func formatUserList(p *i18n.Printer, cfg *config.Config) string {
+	var sb strings.Builder
+	sb.WriteString(p.T("user.title") + "\n")
+
+	for _, role := range []config.Role{config.RoleAdmin, config.RoleOperator, config.RoleViewer} {
+		var ids []string
+		for _, id := range cfg.Users() {
+			if cfg.RoleOf(id) != role {
+				continue
+			}
+			item := fmt.Sprintf("<code>%d</code>", id)
+			if _, ok := cfg.UserRoles[id]; ok {
+				item += " ✏️"
+			}
+			ids = append(ids, item)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n%s <b>%s</b> (%d)\n%s\n", roleIcons[role], role, len(ids), strings.Join(ids, ", ")))
+	}
+
+	sb.WriteString("\n" + p.T("user.overridden_note"))
+	return sb.String()
+}
@@ -1,62 +1,315 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
 	"pi-monitor/config"
+	"pi-monitor/i18n"
 	"pi-monitor/services"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// wakeCallbackPrefix là prefix của callback data cho nút bấm trong /wake
+const wakeCallbackPrefix = "wake:"
+
+// wakePollInterval là khoảng thời gian giữa các lần kiểm tra PC đã online chưa
+const wakePollInterval = 5 * time.Second
+
+// WakeWatcher theo dõi ở background các PC vừa được gửi magic packet đến khi online,
+// tất cả dừng theo dõi khi ctx bị huỷ (bot dừng)
+type WakeWatcher struct {
+	ctx context.Context
+	wg  sync.WaitGroup
+}
+
+// NewWakeWatcher tạo WakeWatcher, dừng theo dõi khi ctx bị huỷ
+func NewWakeWatcher(ctx context.Context) *WakeWatcher {
+	return &WakeWatcher{ctx: ctx}
+}
+
+// Wait chờ tất cả PC đang theo dõi dừng cập nhật tin nhắn
+func (w *WakeWatcher) Wait() {
+	w.wg.Wait()
+}
+
+// watch theo dõi PC ở background và cập nhật tiến trình vào tin nhắn messageID
+func (w *WakeWatcher) watch(bot *tgbotapi.BotAPI, chatID int64, messageID int, p *wakeProgress, cfg *config.Config) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		watchWake(w.ctx, bot, chatID, messageID, p, cfg)
+	}()
+}
+
 // HandleWakeCommand xử lý lệnh /wake - gửi magic packet Wake-on-LAN đến PC
-func HandleWakeCommand(message *tgbotapi.Message, cfg *config.Config) tgbotapi.MessageConfig {
+//
+//	/wake        - hiển thị danh sách PC (inline keyboard) kèm trạng thái
+//	/wake <name> - bật PC theo tên
+//
+// Handler tự gửi tin nhắn vì khi chờ PC online, tin nhắn được cập nhật tiến trình ở background.
+func HandleWakeCommand(p *i18n.Printer, bot *tgbotapi.BotAPI, message *tgbotapi.Message, cfg *config.Config, watcher *WakeWatcher) {
 	chatID := message.Chat.ID
 
 	// Kiểm tra cấu hình WOL
-	if cfg.WOLMACAddress == "" {
-		msg := tgbotapi.NewMessage(chatID, "⚠️ *Chưa cấu hình Wake-on-LAN*\n\nVui lòng thiết lập biến môi trường:\n`WOL_MAC_ADDRESS=AA:BB:CC:DD:EE:FF`\n`WOL_HOST=192.168.1.100` _(tuỳ chọn, để kiểm tra trạng thái)_")
-		msg.ParseMode = "Markdown"
-		return msg
+	if len(cfg.WOLTargets) == 0 {
+		msg := tgbotapi.NewMessage(chatID, p.T("wake.not_configured"))
+		send(bot, msg)
+		return
+	}
+
+	name := strings.TrimSpace(message.CommandArguments())
+	if name == "" {
+		// Chỉ có một PC -> bật luôn như trước
+		if len(cfg.WOLTargets) == 1 {
+			wakeTarget(p, bot, chatID, cfg.WOLTargets[0], cfg, watcher)
+			return
+		}
+		send(bot, wakeTargetMenu(p, chatID, cfg.WOLTargets))
+		return
 	}
 
+	target, ok := cfg.FindWOLTarget(name)
+	if !ok {
+		send(bot, tgbotapi.NewMessage(chatID, p.T("wake.not_found_hint", name, targetNames(cfg.WOLTargets))))
+		return
+	}
+	wakeTarget(p, bot, chatID, target, cfg, watcher)
+}
+
+// HandleWakeCallback xử lý khi người dùng bấm nút chọn PC trong menu /wake
+func HandleWakeCallback(p *i18n.Printer, bot *tgbotapi.BotAPI, query *tgbotapi.CallbackQuery, cfg *config.Config, watcher *WakeWatcher) {
+	chatID := query.Message.Chat.ID
+	name := strings.TrimPrefix(query.Data, wakeCallbackPrefix)
+
+	target, ok := cfg.FindWOLTarget(name)
+	if !ok {
+		send(bot, tgbotapi.NewMessage(chatID, p.T("wake.not_found", name)))
+		return
+	}
+	wakeTarget(p, bot, chatID, target, cfg, watcher)
+}
+
+// IsWakeCallback kiểm tra callback data có thuộc menu /wake không
+func IsWakeCallback(data string) bool {
+	return strings.HasPrefix(data, wakeCallbackPrefix)
+}
+
+// wakeTargetMenu tạo inline keyboard gồm các PC kèm trạng thái online hiện tại
+func wakeTargetMenu(p *i18n.Printer, chatID int64, targets []config.WOLTarget) tgbotapi.MessageConfig {
+	// Kiểm tra song song để không phải chờ từng PC
+	results := make([]services.ReachabilityResult, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t config.WOLTarget) {
+			defer wg.Done()
+			results[i] = checkTarget(t)
+		}(i, t)
+	}
+	wg.Wait()
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, t := range targets {
+		label := "⚫ " + t.Name
+		if results[i].Online {
+			label = fmt.Sprintf("🟢 %s (%s)", t.Name, results[i])
+		} else if t.Host == "" {
+			label = "❔ " + t.Name
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, wakeCallbackPrefix+t.Name),
+		))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, p.T("wake.menu"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return msg
+}
+
+// wakeTarget gửi magic packet đến một PC (nếu PC chưa bật).
+// Nếu PC có Host và bật chờ (WOL_WAIT_TIMEOUT > 0), tiến trình được cập nhật vào tin nhắn ở background (watcher).
+// Trả về lỗi gửi magic packet hoặc gửi tin nhắn (đã báo/log, dùng cho job chạy theo lịch).
+func wakeTarget(p *i18n.Printer, bot *tgbotapi.BotAPI, chatID int64, target config.WOLTarget, cfg *config.Config, watcher *WakeWatcher) error {
 	// Kiểm tra xem PC có đang bật không
-	if cfg.WOLHost != "" && services.IsPCOnline(cfg.WOLHost) {
-		text := fmt.Sprintf(
-			"✅ *PC đã đang bật!*\n\n🖥️ Host: `%s`\n📡 MAC: `%s`\n\n_Không cần gửi magic packet._",
-			cfg.WOLHost,
-			cfg.WOLMACAddress,
+	if result := checkTarget(target); result.Online {
+		text := p.T("wake.already_on",
+			target.Name,
+			valueOrDash(target.Host),
+			target.MAC,
+			result,
 		)
-		msg := tgbotapi.NewMessage(chatID, text)
-		msg.ParseMode = "Markdown"
-		return msg
+		return send(bot, tgbotapi.NewMessage(chatID, text))
 	}
 
 	// PC chưa bật (hoặc không thể kiểm tra) -> gửi magic packet
-	err := services.SendMagicPacket(cfg.WOLMACAddress, cfg.WOLBroadcast)
+	broadcast, err := services.SendMagicPacket(target.MAC, targetWakeOptions(target))
 	if err != nil {
-		text := fmt.Sprintf("❌ *Gửi magic packet thất bại!*\n\n`%v`", err)
-		msg := tgbotapi.NewMessage(chatID, text)
-		msg.ParseMode = "Markdown"
-		return msg
+		send(bot, wakeErrorMessage(p, chatID, err))
+		return err
+	}
+
+	// Có thể kiểm tra trạng thái -> theo dõi đến khi PC online
+	if target.Host != "" && cfg.WOLWaitTimeout > 0 {
+		progress := &wakeProgress{printer: p, target: target, broadcast: broadcast, start: time.Now()}
+		progress.step("📦", "wake.step.sent", cfg.WOLRetries)
+		progress.status = p.T("wake.waiting")
+
+		msg := tgbotapi.NewMessage(chatID, progress.text())
+		sent, err := Send(bot, msg)
+		if err != nil {
+			log.Printf("Error sending message: %v", err)
+			return err
+		}
+
+		watcher.watch(bot, chatID, sent.MessageID, progress, cfg)
+		return nil
 	}
 
 	var text string
-	if cfg.WOLHost != "" {
-		text = fmt.Sprintf(
-			"🚀 *Đã gửi lệnh khởi động PC thành công!*\n\n🖥️ Host: `%s`\n📡 MAC: `%s`\n📦 Broadcast: `%s`\n\n⏳ _PC sẽ khởi động trong vài giây..._",
-			cfg.WOLHost,
-			cfg.WOLMACAddress,
-			cfg.WOLBroadcast,
+	if target.Host != "" {
+		text = p.T("wake.sent_host",
+			target.Name,
+			target.Host,
+			target.MAC,
+			broadcast,
 		)
 	} else {
-		text = fmt.Sprintf(
-			"🚀 *Đã gửi magic packet Wake-on-LAN thành công!*\n\n📡 MAC: `%s`\n📦 Broadcast: `%s`\n\n⏳ _PC sẽ khởi động trong vài giây..._",
-			cfg.WOLMACAddress,
-			cfg.WOLBroadcast,
+		text = p.T("wake.sent",
+			target.Name,
+			target.MAC,
+			broadcast,
 		)
 	}
+	return send(bot, tgbotapi.NewMessage(chatID, text))
+}
 
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "Markdown"
-	return msg
+// wakeProgress lưu tiến trình bật PC để hiển thị trong tin nhắn
+type wakeProgress struct {
+	printer   *i18n.Printer
+	target    config.WOLTarget
+	broadcast string
+	start     time.Time
+	steps     []string
+	status    string // Dòng trạng thái cuối cùng (đang chờ / thành công / thất bại)
+}
+
+// step thêm một bước (key trong catalog i18n) vào tiến trình, kèm thời gian tính từ lúc bắt đầu
+func (p *wakeProgress) step(icon, key string, args ...interface{}) {
+	elapsed := time.Since(p.start).Round(time.Second)
+	p.steps = append(p.steps, fmt.Sprintf("%s <code>%3.0fs</code> %s", icon, elapsed.Seconds(), p.printer.T(key, args...)))
+}
+
+// text trả về nội dung tin nhắn tiến trình
+func (p *wakeProgress) text() string {
+	var sb strings.Builder
+	sb.WriteString(p.printer.T("wake.progress", p.target.Name, p.target.Host, p.target.MAC, p.broadcast))
+	for _, s := range p.steps {
+		sb.WriteString(s + "\n")
+	}
+	if p.status != "" {
+		sb.WriteString("\n" + p.status)
+	}
+	return sb.String()
+}
+
+// watchWake kiểm tra định kỳ PC đã online chưa, gửi lại magic packet nếu cần và cập nhật tin nhắn, dừng khi ctx bị huỷ
+func watchWake(ctx context.Context, bot *tgbotapi.BotAPI, chatID int64, messageID int, p *wakeProgress, cfg *config.Config) {
+	update := func() {
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, p.text())
+		if _, err := Send(bot, edit); err != nil {
+			log.Printf("Error updating wake progress: %v", err)
+		}
+	}
+
+	sent := 1
+	lastSent := p.start
+	ticker := time.NewTicker(wakePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.status = p.printer.T("wake.stopped")
+			update()
+			log.Printf("🔌 Stopped watching %s: bot is shutting down", p.target.Name)
+			return
+		case <-ticker.C:
+		}
+		elapsed := time.Since(p.start)
+
+		if result := checkTarget(p.target); result.Online {
+			p.step("✅", "wake.step.online", result)
+			p.status = p.printer.T("wake.online", p.target.Name, time.Since(p.start).Seconds())
+			update()
+			log.Printf("🔌 %s online after %v", p.target.Name, time.Since(p.start).Round(time.Second))
+			return
+		}
+
+		if elapsed >= cfg.WOLWaitTimeout {
+			p.status = p.printer.T("wake.timeout", p.target.Name, cfg.WOLWaitTimeout.Seconds())
+			update()
+			log.Printf("🔌 %s did not come online within %v", p.target.Name, cfg.WOLWaitTimeout)
+			return
+		}
+
+		// Gửi lại magic packet nếu chờ quá lâu
+		if sent < cfg.WOLRetries && time.Since(lastSent) >= cfg.WOLRetryInterval {
+			sent++
+			lastSent = time.Now()
+			if _, err := services.SendMagicPacket(p.target.MAC, targetWakeOptions(p.target)); err != nil {
+				p.step("⚠️", "wake.step.resend_failed", p.printer.Err(err))
+			} else {
+				p.step("📦", "wake.step.resent", sent, cfg.WOLRetries)
+			}
+		}
+
+		p.status = p.printer.T("wake.waiting_elapsed", elapsed.Seconds(), cfg.WOLWaitTimeout.Seconds())
+		update()
+	}
+}
+
+// checkTarget kiểm tra PC có đang online không theo các phương thức đã cấu hình
+func checkTarget(target config.WOLTarget) services.ReachabilityResult {
+	return services.NewReachabilityChecker(target.CheckMethods, target.Ports).Check(target.Host, target.MAC)
+}
+
+// wakeErrorMessage tạo tin nhắn báo lỗi khi gửi magic packet
+func wakeErrorMessage(p *i18n.Printer, chatID int64, err error) tgbotapi.MessageConfig {
+	text := p.T("wake.failed", p.Err(err))
+	return tgbotapi.NewMessage(chatID, text)
+}
+
+// send gửi tin nhắn, ghi log và trả về lỗi nếu có
+func send(bot *tgbotapi.BotAPI, msg tgbotapi.Chattable) error {
+	_, err := Send(bot, msg)
+	if err != nil {
+		log.Printf("Error sending message: %v", err)
+	}
+	return err
+}
+
+// targetWakeOptions trả về cách gửi magic packet đến PC
+func targetWakeOptions(target config.WOLTarget) services.WakeOptions {
+	return services.WakeOptions{
+		Broadcast: target.Broadcast,
+		Interface: target.Interface,
+		Ports:     target.SendPorts,
+		Password:  target.Password,
+		Mode:      target.Mode,
+	}
+}
+
+// targetNames trả về danh sách tên PC, phân cách bởi dấu phẩy
+func targetNames(targets []config.WOLTarget) string {
+	names := make([]string, len(targets))
+	for i, t := range targets {
+		names[i] = t.Name
+	}
+	return strings.Join(names, ", ")
 }
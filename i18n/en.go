@@ -0,0 +1,390 @@
+package i18n
+
+// en là nội dung tin nhắn tiếng Anh
+var en = map[string]string{
+
+	// Ngôn ngữ, định dạng
+	"lang.name":              "English",
+	"format.decimal":         ".",
+	"format.thousands":       ",",
+	"format.datetime":        "Jan 2, 2006 15:04:05",
+	"format.datetime_short":  "Jan 2, 2006 15:04",
+	"format.date_short":      "Jan 2",
+	"format.day_time":        "Jan 2 15:04",
+	"unit.day.one":           "%d day",
+	"unit.day.other":         "%d days",
+	"unit.hour.one":          "%d hour",
+	"unit.hour.other":        "%d hours",
+	"unit.minute.one":        "%d minute",
+	"unit.minute.other":      "%d minutes",
+	"unit.time.one":          "%d time",
+	"unit.time.other":        "%d times",
+	"duration.short.days":    "%dd%dh",
+	"duration.short.hours":   "%dh%02dm",
+	"duration.short.minutes": "%dm",
+	"weekday.short.0":        "Sun",
+	"weekday.short.1":        "Mon",
+	"weekday.short.2":        "Tue",
+	"weekday.short.3":        "Wed",
+	"weekday.short.4":        "Thu",
+	"weekday.short.5":        "Fri",
+	"weekday.short.6":        "Sat",
+	"common.invalid_button":  "❓ Invalid button",
+	"common.cancelled":       "❌ Cancelled.",
+	"common.error":           "❌ %v",
+	"common.time_footer":     "⏰ <i>Time: %s</i>",
+
+	// Cài đặt, người dùng (lỗi của package config)
+	"setting.err.duration": "%q is not a duration (e.g. 30s, 5m)",
+	"setting.err.number":   "%q is not a number",
+	"setting.err.range":    "%s must be between %s → %s",
+	"setting.err.integer":  "%s must be a whole number",
+	"setting.err.save":     "cannot save settings: %v",
+	"user.err.role":        "invalid role %q (admin, operator, viewer)",
+	"user.err.not_found":   "user %d is not in the list",
+	"user.err.last_admin":  "there must be at least one admin",
+	"user.err.save":        "cannot save the user list: %v",
+
+	// Xử lý lệnh: quyền, giới hạn, lỗi
+	"auth.unauthorized":          "🚫 You are not allowed to use this bot.",
+	"auth.user_id":               "🆔 Your User ID: <code>%d</code>",
+	"auth.role_required":         "🔒 Requires %s role",
+	"auth.command_role_required": "🔒 /%s requires the <b>%s</b> role (you: %s).",
+	"error.internal":             "❌ Internal error while handling the command, please try again.",
+	"error.rate_limited":         "🐢 You are sending commands too fast, try again in %v.",
+	"error.timeout":              "⏱️ /%s took longer than %v and was cancelled.",
+	"error.busy":                 "⏳ The bot is busy, try again later.",
+	"error.unknown_command":      "❓ Unknown command. Use /help to see the list of commands.",
+	"error.source":               "%s: %v",
+
+	// Danh sách lệnh (/help, menu lệnh của Telegram)
+	"help.title":            "📖 <b>Commands:</b>",
+	"help.role":             "👤 Your role: <b>%s</b>",
+	"help.syntax":           "⚠️ Usage: %s\n📖 %s",
+	"cmd.pi":                "System info (CPU, RAM, disk, network)",
+	"cmd.live":              "Self-updating live dashboard",
+	"cmd.live.usage":        "<interval> <duration>",
+	"cmd.wake":              "Wake a PC with Wake-on-LAN",
+	"cmd.wake.usage":        "<name>",
+	"cmd.sleep":             "Put a PC to sleep via pc-agent",
+	"cmd.sleep.usage":       "<name>",
+	"cmd.shutdown":          "Shut down a PC via pc-agent",
+	"cmd.shutdown.usage":    "<name>",
+	"cmd.pcstats":           "PC usage time by day/week",
+	"cmd.pcstats.usage":     "<name>",
+	"cmd.ip":                "Public and LAN IP of the Pi",
+	"cmd.devices":           "Devices on the LAN",
+	"cmd.devices.usage":     "<scan|name|history>",
+	"cmd.schedule":          "Scheduled wake-ups and reports",
+	"cmd.schedule.usage":    "<add|list|rm>",
+	"cmd.id":                "Show your user ID",
+	"cmd.alert":             "Alert status and settings",
+	"cmd.set":               "Change alert thresholds and intervals",
+	"cmd.set.usage":         "<key> <value>",
+	"cmd.user":              "Manage users and roles",
+	"cmd.user.usage":        "<add|role|rm>",
+	"cmd.subscribe":         "Choose notifications, subscribe groups/channels",
+	"cmd.unsubscribe":       "Stop receiving notifications",
+	"cmd.unsubscribe.usage": "<chat id>",
+	"cmd.lang":              "Choose language",
+	"cmd.lang.usage":        "<vi|en|auto>",
+	"cmd.help":              "Show help",
+	"cmd.start":             "Start",
+
+	// Cài đặt cảnh báo (/set)
+	"set.usage":               "<code>/set</code> - settings menu\n<code>/set &lt;key&gt; &lt;value&gt;</code> - change a value (e.g. <code>/set cpu_temp 75</code>, <code>/set interval 1m</code>)\n<code>/set &lt;key&gt; reset</code> - back to the configured value",
+	"set.unknown":             "❓ No setting <code>%s</code>. Available: %s",
+	"set.updated":             "✅ <b>Updated</b> %s: <code>%s</code>",
+	"set.reset":               "↩️ <b>Reset to default</b> %s: <code>%s</code>",
+	"set.reset_note":          "↩️ Back to the configured value",
+	"set.title":               "⚙️ <b>Alert settings</b>",
+	"set.overridden_note":     "<i>✏️ changed with /set, takes precedence over the configuration</i>",
+	"set.current":             "├ Current: <code>%s</code>",
+	"set.configured":          "├ Configured: <code>%s</code>",
+	"set.range":               "└ Range: <code>%s</code> → <code>%s</code>",
+	"set.button":              "⚙️ Settings",
+	"set.button.default":      "↩️ Default",
+	"common.back":             "⬅️ Back",
+	"setting.cpu_temp":        "🌡️ CPU temperature",
+	"setting.cpu_usage":       "📈 CPU usage",
+	"setting.memory":          "💾 RAM usage",
+	"setting.disk":            "💿 Disk usage",
+	"setting.wifi_signal":     "📶 Wi-Fi signal",
+	"setting.wifi_reconnects": "🔁 Wi-Fi reconnects",
+	"setting.wifi_window":     "🕑 Reconnect counting window",
+	"setting.interval":        "⏱️ Check every",
+	"setting.cooldown":        "🔕 Repeat alerts after",
+
+	// Người dùng (/user)
+	"user.usage":           "👥 <b>User management</b>\n\n<code>/user list</code> - list users\n<code>/user add &lt;id&gt; [role]</code> - add a user (default: viewer)\n<code>/user role &lt;id&gt; &lt;role&gt;</code> - change role\n<code>/user rm &lt;id&gt;</code> - remove a user\n\n<b>Roles:</b>\n├ <code>viewer</code> - view info (/pi, /ip, /devices, /alert...)\n├ <code>operator</code> - also /wake, /schedule, naming devices\n└ <code>admin</code> - also /sleep, /shutdown, /set, /user",
+	"user.invalid_id_hint": "⚠️ Invalid user ID (use /id to see a user ID)",
+	"user.invalid_id":      "⚠️ Invalid user ID",
+	"user.added":           "✅ <b>Added user</b> <code>%d</code>: %s %s",
+	"user.role_changed":    "✅ <b>Changed role of user</b> <code>%d</code>: %s → %s %s",
+	"user.rm_syntax":       "⚠️ Usage: /user rm &lt;id&gt;",
+	"user.removed":         "🗑️ Removed user <code>%d</code>",
+	"user.title":           "👥 <b>Users</b>",
+	"user.overridden_note": "<i>✏️ changed with /user, takes precedence over the configuration</i>",
+
+	// Đăng ký nhận thông báo (/subscribe, /unsubscribe)
+	"subscribe.usage":             "🔔 <b>Notification subscriptions</b>\n\n<code>/subscribe</code> - show this chat's subscription\n<code>/subscribe all</code> - receive all notifications\n<code>/subscribe system power min=warning</code> - only some types, from a severity\n<code>/subscribe chat &lt;@channel|chat_id&gt; [types...]</code> - subscribe another channel/group\n<code>/subscribe list</code> - subscribed chats\n<code>/unsubscribe [chat_id]</code> - unsubscribe\n\n<b>Types:</b> <code>system</code>, <code>devices</code>, <code>power</code>, <code>ip</code>, <code>config</code>\n<b>Severities:</b> <code>info</code>, <code>warning</code>, <code>critical</code>\n\n<i>In groups with topics, the subscription is per topic the command is sent from. Private chats receive all notifications by default.</i>",
+	"subscribe.chat_not_found":    "❌ Chat %s not found: %v\n\nAdd the bot to the group/channel (channels need posting rights) and try again.",
+	"subscribe.confirm":           "🔔 This chat will receive notifications from Pi Monitor: %s",
+	"subscribe.cannot_send":       "❌ The bot cannot send messages to %s: %v",
+	"subscribe.save_failed":       "❌ Cannot save the subscription: %v",
+	"subscribe.subscribed":        "✅ <b>Subscribed</b> %s (<code>%d</code>)\n\n%s",
+	"subscribe.invalid":           "❌ %v\n\nSend /subscribe for help.",
+	"subscribe.updated":           "✅ <b>Subscription updated</b>",
+	"subscribe.receiving":         "🔔 <b>This chat receives:</b>",
+	"subscribe.receiving_default": "🔔 <b>This chat receives:</b> all notifications (default)",
+	"subscribe.not_subscribed":    "🔕 <b>This chat is not subscribed to notifications</b>",
+	"subscribe.list_empty":        "🔔 No chats subscribed. Users receive all notifications in private chats.",
+	"subscribe.list_title":        "🔔 <b>Subscribed chats</b>",
+	"subscribe.private_chat":      "👤 private chat",
+	"subscribe.no_longer_allowed": "(no longer allowed)",
+	"subscribe.none":              "🔕 no notifications",
+	"subscribe.types":             "%s (from %s)",
+	"unsubscribe.syntax":          "⚠️ Usage: /unsubscribe &lt;chat_id&gt; (see /subscribe list)",
+	"unsubscribe.all_off":         "🔕 All notifications turned off. Use /subscribe all to turn them back on.",
+	"unsubscribe.done":            "🔕 Unsubscribed from notifications.",
+	"notify.system":               "🚨 System alerts",
+	"notify.devices":              "📡 Unknown devices",
+	"notify.power":                "🖥️ PC on/off",
+	"notify.ip":                   "🌍 Public IP",
+	"notify.config":               "🔄 Configuration",
+	"notify.err.type":             "invalid notification type %q (system, devices, power, ip, config)",
+	"notify.err.severity":         "invalid severity %q (info, warning, critical)",
+	"notify.err.not_subscribed":   "chat %d is not subscribed to notifications",
+
+	// Cảnh báo hệ thống
+	"alert.title":          "🚨 <b>RASPBERRY PI SYSTEM ALERT</b>",
+	"alert.cpu_temp":       "🌡️ <b>CPU temperature too high!</b>\n├ Current: <b>%s°C</b>\n└ Threshold: %s°C",
+	"alert.cpu_usage":      "📈 <b>CPU overloaded!</b>\n├ Current: <b>%s</b>\n└ Threshold: %s",
+	"alert.memory":         "💾 <b>RAM almost full!</b>\n├ Used: <b>%s</b> (%s/%s)\n└ Threshold: %s",
+	"alert.disk":           "💿 <b>Disk almost full!</b>\n├ Used: <b>%s</b> (%s/%s)\n└ Threshold: %s",
+	"alert.wifi_reconnect": "📶 <b>Wi-Fi keeps reconnecting!</b>\n├ Interface: %s\n├ Reconnects: <b>%s</b> in %s\n└ Threshold: %s",
+	"alert.wifi_signal":    "📶 <b>Weak Wi-Fi signal!</b>\n├ SSID: %s\n├ Current: <b>%s dBm</b>\n└ Threshold: %s dBm",
+
+	// Bảng số liệu cập nhật liên tục (/live)
+	"live.reason.timeout":     "time is up",
+	"live.reason.stopped":     "stopped by button",
+	"live.reason.replaced":    "a new /live was opened in this chat",
+	"live.reason.shutdown":    "the bot is shutting down",
+	"live.finished":           "⏹️ <i>Updates stopped: %s. Send /live to continue.</i>",
+	"live.usage":              "❌ %s\n\nUsage: <code>/live [interval] [duration]</code>, e.g. <code>/live 10s 15m</code> (default %v for %v)",
+	"live.loading":            "⏳ Collecting data...",
+	"live.started":            "📈 Updating every %v for %v",
+	"live.stopped":            "⏹️ Updates stopped",
+	"live.button.start":       "📈 Live updates",
+	"live.button.stop":        "⏹️ Stop",
+	"live.err.too_many_args":  "too many arguments",
+	"live.err.interval":       "invalid interval %q",
+	"live.err.duration":       "invalid duration %q",
+	"live.err.min_interval":   "minimum interval is %v",
+	"live.err.duration_range": "duration must be between %v and %v",
+	"live.title":              "📈 <b>Pi Live</b> (every %v)",
+	"live.temperature":        "🌡️ Temperature: %s°C%s%s",
+	"live.network":            "🌐 Network: 📤 %s/s%s · 📥 %s/s%s",
+	"live.wifi_disconnected":  "📶 Wi-Fi: ❌ not connected",
+	"live.footer":             "🕐 %s · %s left",
+
+	// Thông tin hệ thống (/pi)
+	"pi.error":             "❌ Error getting system info: %v",
+	"pi.status":            "🍓 <b>Raspberry Pi Status</b>\n\n🖥️ <b>CPU</b>\n├ Usage: %s\n├ Temperature: %s°C\n├ Cores: %d\n└ Frequency: %s MHz\n\n💾 <b>RAM</b>\n├ Total: %s\n├ Used: %s (%s)\n└ Available: %s\n\n💿 <b>Disk</b>\n├ Total: %s\n├ Used: %s (%s)\n└ Free: %s\n\n🌐 <b>Network</b>\n├ IP: %s\n├ Sent: %s\n└ Received: %s\n%s\n⏱️ <b>Uptime</b>: %s\n🕐 <b>Updated</b>: %s",
+	"pi.wifi.disconnected": "❌ Not connected",
+	"pi.wifi.signal":       "Signal: %s dBm (%s)",
+	"pi.wifi.quality":      "Quality: %s",
+	"pi.wifi.frequency":    "Frequency: %s MHz",
+	"pi.signal.excellent":  "excellent",
+	"pi.signal.good":       "good",
+	"pi.signal.fair":       "fair",
+	"pi.signal.weak":       "weak",
+
+	// Wake-on-LAN, pc-agent (lỗi của package services)
+	"wol.err.mac":             "invalid MAC address: %s",
+	"wol.err.parse_mac":       "cannot parse MAC address: %v",
+	"wol.err.secureon":        "invalid SecureOn password (expected AA:BB:CC:DD:EE:FF or a.b.c.d)",
+	"wol.err.interface":       "interface %s not found: %v",
+	"wol.err.interface_addrs": "cannot read addresses of %s: %v",
+	"wol.err.no_ipv4":         "interface %s has no IPv4 address",
+	"wol.err.mode":            "invalid send mode: %s (udp, raw, all)",
+	"wol.err.raw_interface":   "raw mode requires an interface",
+	"wol.err.udp_listen":      "cannot open UDP connection: %v",
+	"wol.err.broadcast":       "invalid broadcast address: %v",
+	"wol.err.send":            "cannot send magic packet to %s: %v",
+	"wol.err.not_ethernet":    "interface %s is not Ethernet",
+	"wol.err.raw_socket":      "cannot open raw socket (requires root/CAP_NET_RAW): %v",
+	"wol.err.send_frame":      "cannot send Ethernet frame via %s: %v",
+	"wol.err.bind_linux_only": "SO_BINDTODEVICE is only supported on Linux",
+	"wol.err.raw_linux_only":  "raw mode is only supported on Linux",
+	"agent.err.no_secret":     "agent secret is not configured",
+	"agent.err.url":           "invalid agent URL: %v",
+	"agent.err.connect":       "cannot connect to agent: %v",
+	"agent.err.response":      "invalid agent response (HTTP %d): %v",
+	"agent.err.rejected":      "agent rejected the request (HTTP %d): %s",
+
+	// Kiểm tra PC online (lỗi của package services)
+	"reach.err.no_target":   "no host or MAC configured",
+	"reach.err.no_host":     "no host configured",
+	"reach.err.no_mac":      "no MAC configured",
+	"reach.err.probe":       "%s: %v",
+	"reach.err.icmp_socket": "cannot open ICMP socket: %v",
+	"reach.err.arp_missing": "MAC not in the ARP table",
+	"reach.err.arp_state":   "ARP entry is %s",
+	"reach.err.resolve":     "cannot resolve %s",
+
+	// IP public, thiết bị, lịch (lỗi của package services)
+	"publicip.err.ipv4":             "cannot get public IPv4: %v",
+	"publicip.err.ipv6":             "cannot get public IPv6: %v",
+	"publicip.err.no_endpoint":      "no endpoint configured",
+	"publicip.err.not_ip":           "response is not an IP address: %q",
+	"publicip.err.family":           "address %s is not IPv%s",
+	"publicip.err.stun_short":       "STUN response too short",
+	"publicip.err.stun_type":        "invalid STUN response: type 0x%04x",
+	"publicip.err.stun_transaction": "transaction ID mismatch",
+	"publicip.err.stun_truncated":   "STUN response truncated",
+	"publicip.err.stun_no_address":  "no address in STUN response",
+	"devices.err.arp":               "cannot read ARP table: %v",
+	"devices.err.oui":               "cannot read OUI file: %v",
+	"schedule.err.cron":             "invalid cron expression: %v",
+	"schedule.err.not_found":        "job #%d not found",
+	"schedule.err.save":             "cannot save schedules: %v",
+
+	// Lịch chạy tự động (/schedule)
+	"schedule.usage":                "⏰ <b>Scheduled jobs</b>\n\n<code>/schedule add &lt;cron&gt; &lt;command&gt;</code> - add a job\n<code>/schedule list</code> - list jobs\n<code>/schedule rm &lt;id&gt;</code> - remove a job\n\n<b>Cron:</b> <code>minute hour day month weekday</code> or <code>@daily</code>, <code>@every 1h</code>\n<b>Commands:</b> <code>wake &lt;name&gt;</code>, <code>status</code>, <code>disk</code>\n\n<i>Examples:</i>\n<code>/schedule add 30 8 * * 1-5 wake office-pc</code>\n<code>/schedule add 0 21 * * * status</code>\n<code>/schedule add 0 9 * * 0 disk</code>",
+	"schedule.added":                "✅ <b>Added job #%d</b>\n\n├ Schedule: <code>%s</code>\n├ Command: <code>%s</code>\n└ Next run: %s %s",
+	"schedule.rm_syntax":            "⚠️ Usage: /schedule rm &lt;id&gt;",
+	"schedule.invalid_id":           "⚠️ Invalid ID",
+	"schedule.removed":              "🗑️ Removed job #%d",
+	"schedule.err.missing_spec":     "missing cron expression or command",
+	"schedule.err.missing_command":  "missing command",
+	"schedule.err.need_target":      "PC name required: wake <name>",
+	"schedule.err.target_not_found": "PC \"%s\" not found",
+	"schedule.err.unsupported":      "command \"%s\" cannot be scheduled",
+	"schedule.err.empty":            "empty command",
+	"schedule.err.job_target":       "no PC found for command \"%s\"",
+	"schedule.err.job_unsupported":  "command \"%s\" is not supported",
+	"schedule.err.send":             "cannot send result: %v",
+	"schedule.err.not_owner":        "job #%d was created by another user, only its creator or an admin can remove it",
+	"schedule.err.creator_role":     "the job creator (user %d) no longer has operator permission, skipped",
+	"schedule.job_header":           "⏰ <b>Job #%d</b> · <code>%s</code>\n\n",
+	"schedule.waking":               "🔌 Waking PC <b>%s</b>...",
+	"schedule.disk_ok":              "✅ Disk usage is fine",
+	"schedule.disk_over":            "⚠️ <b>Above %s - time to clean up the disk!</b>",
+	"schedule.disk":                 "💿 <b>Disk check</b>\n├ Total: %s\n├ Used: %s (%s)\n└ Free: %s\n\n%s",
+	"schedule.list_empty":           "⏰ <b>Scheduled jobs</b>\n\n<i>No jobs yet. Use /schedule add to add one.</i>",
+	"schedule.list_title":           "⏰ <b>Scheduled jobs</b> (%s)\n",
+	"schedule.next":                 "├ Next: %s\n",
+	"schedule.never_run":            "└ Never run\n",
+	"schedule.last_failed":          "└ ❌ %s: %s\n",
+	"schedule.last_ok":              "└ ✅ %s\n",
+
+	// Bật PC (/wake)
+	"wake.not_configured":     "⚠️ <b>Wake-on-LAN is not configured</b>\n\nPlease set the environment variables:\n<code>WOL_MAC_ADDRESS=AA:BB:CC:DD:EE:FF</code>\n<code>WOL_HOST=192.168.1.100</code> <i>(optional, for status checks)</i>\n\nOr several PCs:\n<code>WOL_TARGETS=office,gaming</code>\n<code>WOL_OFFICE_MAC=AA:BB:CC:DD:EE:FF</code>",
+	"wake.not_found_hint":     "❓ PC \"%s\" not found. Available: %s",
+	"wake.not_found":          "❓ PC \"%s\" not found",
+	"wake.menu":               "🖥️ <b>Choose a PC to wake:</b>\n\n<i>🟢 online · ⚫ offline · ❔ cannot be checked</i>",
+	"wake.already_on":         "✅ <b>PC %s is already on!</b>\n\n🖥️ Host: <code>%s</code>\n📡 MAC: <code>%s</code>\n📶 Reply: %s\n\n<i>No magic packet needed.</i>",
+	"wake.sent_host":          "🚀 <b>Wake command sent to PC %s!</b>\n\n🖥️ Host: <code>%s</code>\n📡 MAC: <code>%s</code>\n📦 Broadcast: <code>%s</code>\n\n⏳ <i>The PC will boot in a few seconds...</i>",
+	"wake.sent":               "🚀 <b>Wake-on-LAN magic packet sent to %s!</b>\n\n📡 MAC: <code>%s</code>\n📦 Broadcast: <code>%s</code>\n\n⏳ <i>The PC will boot in a few seconds...</i>",
+	"wake.progress":           "🚀 <b>Waking PC %s</b>\n\n🖥️ Host: <code>%s</code>\n📡 MAC: <code>%s</code>\n📦 Broadcast: <code>%s</code>\n\n",
+	"wake.step.sent":          "Magic packet sent (1/%d)",
+	"wake.step.online":        "PC is online (%s)",
+	"wake.step.resend_failed": "Resending magic packet failed: %s",
+	"wake.step.resent":        "Magic packet resent (%d/%d)",
+	"wake.waiting":            "⏳ <i>Waiting for the PC to come online...</i>",
+	"wake.waiting_elapsed":    "⏳ <i>Waiting for the PC to come online... %.0fs / %.0fs</i>",
+	"wake.stopped":            "⏹️ <i>Stopped watching: the bot is shutting down.</i>",
+	"wake.online":             "✅ <b>PC %s came online after %.0fs!</b>",
+	"wake.timeout":            "❌ <b>PC %s did not come online within %.0fs</b>\n<i>Check the power supply, network cable and the WOL setting in the BIOS.</i>",
+	"wake.failed":             "❌ <b>Failed to send the magic packet!</b>\n\n<code>%s</code>",
+
+	// Tắt, ngủ PC (/sleep, /shutdown)
+	"power.not_configured": "⚠️ <b>pc-agent is not configured</b>\n\nRun <code>pc-agent</code> on the PC and set:\n<code>WOL_AGENT_URL=http://192.168.1.100:9770</code>\n<code>WOL_AGENT_SECRET=&lt;secret&gt;</code>\n\nOr with several PCs: <code>WOL_&lt;NAME&gt;_AGENT_URL</code>, <code>WOL_&lt;NAME&gt;_AGENT_SECRET</code>",
+	"power.syntax":         "⚠️ Usage: /%s &lt;name&gt;. Available: %s",
+	"power.no_agent":       "⚠️ PC %s has no pc-agent configured (WOL_&lt;NAME&gt;_AGENT_URL)",
+	"power.confirm":        "%s <b>PC %s?</b>\n\n🖥️ Agent: <code>%s</code>\n\n<i>Press confirm to send the command.</i>",
+	"power.button.confirm": "✅ Confirm",
+	"power.button.cancel":  "❌ Cancel",
+	"power.failed":         "❌ <b>Failed to send %s to %s!</b>\n\n<code>%s</code>",
+	"power.sent":           "✅ <b>Sent %s to PC %s</b>\n\n🖥️ Hostname: <code>%s</code>\n💬 %s",
+
+	// Thiết bị trong mạng LAN (/devices)
+	"devices.scan_error":     "❌ Error scanning the LAN: %s",
+	"devices.name_syntax":    "⚠️ Usage: /devices name &lt;MAC&gt; &lt;name&gt;",
+	"devices.name_error":     "❌ Cannot set name: %s",
+	"devices.name_cleared":   "✅ Cleared the name of %s",
+	"devices.named":          "✅ Named %s as %s",
+	"devices.history_syntax": "⚠️ Usage: /devices history &lt;MAC|IP|name&gt;",
+	"devices.not_found":      "❓ Device not found",
+	"devices.syntax":         "⚠️ Usage: /devices [scan | name &lt;MAC&gt; &lt;name&gt; | history &lt;MAC&gt;]",
+	"devices.list_empty":     "📡 <b>LAN devices</b>\n\n<i>No devices in the ARP table yet.</i>",
+	"devices.list_title":     "📡 <b>LAN devices</b> (%d/%d online)\n",
+	"devices.vendor":         "├ Vendor: %s\n",
+	"devices.last_seen":      "└ Last seen: %s\n",
+	"devices.history_title":  "📜 <b>History: %s</b>\n\n",
+	"devices.first_seen":     "└ First seen: %s\n",
+	"devices.history_empty":  "\n<i>No history yet.</i>",
+	"devices.vendor.random":  "Random MAC",
+	"devices.new.title":      "📡 <b>New device detected on the LAN!</b>\n",
+	"devices.new.item":       "\n├ IP: <code>%s</code>\n├ MAC: <code>%s</code>\n└ Vendor: %s\n",
+	"devices.new.hint":       "\n<i>Set a name with /devices name &lt;MAC&gt; &lt;name&gt;</i>",
+
+	// Thông báo: PC bật/tắt, IP public, khởi động
+	"power.event.online":     "🟢 <b>PC %s just turned on</b>\n\n├ Reply: %s\n└ Was off for: %s",
+	"power.event.offline":    "⚫ <b>PC %s just turned off</b>\n\n└ Session length: %s",
+	"publicip.changed.title": "🌍 <b>Public IP changed!</b>\n\n",
+	"publicip.changed.ipv4":  "├ Old IPv4: <code>%s</code>\n├ New IPv4: <code>%s</code>\n",
+	"publicip.changed.ipv6":  "├ Old IPv6: <code>%s</code>\n├ New IPv6: <code>%s</code>\n",
+	"publicip.changed.since": "└ Old IP seen since: %s",
+	"startup.reboot":         "🔁 <b>The Pi just rebooted!</b>\n\n",
+	"startup.crash":          "💥 <b>Bot restarted after stopping unexpectedly!</b>\n\n",
+	"startup.restart":        "🟢 <b>Bot restarted</b>\n\n",
+	"startup.first":          "🟢 <b>Bot started!</b>\n\n",
+	"startup.pi_uptime":      "├ Pi uptime before reboot: %s\n",
+	"startup.previous_run":   "├ Previous run: %s\n",
+	"startup.last_seen":      "├ Last active: %s\n",
+	"startup.downtime":       "└ Down for: %s\n",
+	"startup.unclean":        "\n⚠️ <i>The bot did not stop cleanly before the Pi rebooted (power loss?)</i>\n",
+	"startup.help_hint":      "<i>Use /help to see the list of commands.</i>",
+
+	// IP public (/ip), thời gian dùng PC (/pcstats)
+	"ip.error":               "❌ Error getting public IP: %s",
+	"ip.last_known":          "⚠️ <b>Cannot get the current public IP</b>\n\nLast known value:\n├ IPv4: <code>%s</code>\n├ IPv6: <code>%s</code>\n└ Updated: %s",
+	"ip.current":             "🌍 <b>Public IP</b>\n\n├ IPv4: <code>%s</code>\n├ IPv6: <code>%s</code>\n└ LAN: <code>%s</code>",
+	"pcstats.not_configured": "⚠️ No PC is configured for Wake-on-LAN.",
+	"pcstats.title":          "📊 <b>PC usage time</b>\n",
+	"pcstats.disabled":       "\n<i>Tracking is off, set WOL_TRACK_ENABLED=true to enable it.</i>",
+	"pcstats.unknown":        "🖥️ <b>%s</b> — ❔ no data yet\n",
+	"pcstats.online":         "🖥️ <b>%s</b> — 🟢 on since %s (%s)\n",
+	"pcstats.offline":        "🖥️ <b>%s</b> — ⚫ off since %s\n",
+	"pcstats.today":          "├ Today: <b>%s</b>\n",
+	"pcstats.week":           "├ 7 days: <b>%s</b>\n",
+
+	// Ngôn ngữ (/lang)
+	"lang.title":         "🌐 <b>Language</b>\n\nCurrent: <b>%s</b> (%s)\n\n<i>Choose a language for this chat, or Auto to follow each person's Telegram app language.</i>",
+	"lang.source.chosen": "chosen",
+	"lang.source.auto":   "automatic",
+	"lang.button.auto":   "🔄 Auto",
+	"lang.usage":         "Usage: <code>/lang &lt;vi|en|auto&gt;</code>",
+	"lang.changed":       "✅ Switched to <b>%s</b>",
+	"lang.auto":          "✅ Language follows the Telegram app (currently: <b>%s</b>)",
+	"lang.err.unknown":   "language %q is not supported",
+	"lang.err.save":      "cannot save language: %v",
+
+	// Lệnh chung, trạng thái cảnh báo, reload cấu hình, dừng bot
+	"id.role":                 "👤 Role: %s",
+	"start.welcome":           "👋 Hello! Use /pi to see Raspberry Pi system info.",
+	"callback.processing":     "⏳ Processing...",
+	"callback.sending":        "⏳ Sending command...",
+	"alert.status.enabled":    "🚨 <b>Alert status</b>\n\n✅ <b>Status:</b> Active\n⏱️ <b>Check every:</b> %v\n🔕 <b>Repeat after:</b> %v\n👥 <b>Sent to:</b> %d users\n\n📊 <b>Thresholds:</b>\n├ 🌡️ CPU temperature: &gt; %.0f°C\n├ 📈 CPU usage: &gt; %.0f%%\n├ 💾 RAM usage: &gt; %.0f%%\n├ 💿 Disk usage: &gt; %.0f%%\n└ 📶 Wi-Fi signal: &lt; %.0f dBm\n\n<i>You will be alerted when the system exceeds a threshold. Change thresholds with /set or the button below.</i>",
+	"alert.status.disabled":   "🚨 <b>Alert status</b>\n\n❌ <b>Status:</b> Disabled\n\n<i>Set ALERT_ENABLED=true and configure users (ADMIN_USERS) to enable</i>",
+	"config.rejected":         "❌ <b>New configuration rejected</b> (%s)\n\n<pre>%s</pre>\n<i>The bot keeps the previous configuration.</i>",
+	"config.reloaded":         "🔄 <b>Configuration reloaded</b> (%s)\n",
+	"config.applied":          "\n✅ <b>Applied:</b>\n",
+	"config.restart_required": "\n⏸️ <b>Restart required to apply:</b>\n",
+	"shutdown.notice":         "🔴 <b>Bot is stopping</b>\n\n🤖 Bot: @%s\n🕐 Time: <code>%s</code>",
+
+	// Hàng đợi thông báo
+	"outbox.delayed": "⏳ <i>Delayed notification, created at %s</i>",
+}
@@ -0,0 +1,41 @@
+package i18n
+
+// Error là lỗi có nội dung trong catalog, hiển thị theo ngôn ngữ của người dùng bằng Printer.Err.
+// Error() trả về nội dung theo ngôn ngữ mặc định (dùng khi ghi log, in ra console).
+type Error struct {
+	Key  string
+	Args []interface{} // Tham số của nội dung, tham số là error cũng được dịch
+}
+
+// Errorf tạo lỗi với nội dung là key trong catalog
+func Errorf(key string, args ...interface{}) error {
+	return &Error{Key: key, Args: args}
+}
+
+// Error trả về nội dung lỗi theo ngôn ngữ mặc định
+func (e *Error) Error() string {
+	return NewPrinter(Default, nil).Err(e)
+}
+
+// Unwrap trả về lỗi gốc (tham số đầu tiên là error) để dùng được errors.Is/As
+func (e *Error) Unwrap() error {
+	for _, a := range e.Args {
+		if err, ok := a.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// localizedArgs dịch các tham số là error theo ngôn ngữ của p
+func (e *Error) localizedArgs(p *Printer) []interface{} {
+	args := make([]interface{}, len(e.Args))
+	for i, a := range e.Args {
+		if err, ok := a.(error); ok {
+			args[i] = p.Err(err)
+		} else {
+			args[i] = a
+		}
+	}
+	return args
+}
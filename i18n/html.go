@@ -0,0 +1,56 @@
+package i18n
+
+import (
+	"fmt"
+	"html"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Nội dung tin nhắn trong catalog là HTML theo parse mode HTML của Telegram:
+// chỉ dùng <b>, <i>, <code>, <pre>; ký tự &, <, > trong nội dung phải escape.
+
+// HTML là đoạn HTML đã escape (vd: kết quả của T), T chèn nguyên văn thay vì escape lại
+type HTML string
+
+// htmlEscaper escape các ký tự Telegram yêu cầu trong parse mode HTML
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Escape escape chuỗi (tên, hostname, nội dung lỗi...) để chèn vào tin nhắn HTML
+func Escape(s string) string {
+	return htmlEscaper.Replace(s)
+}
+
+// htmlTag khớp các thẻ HTML trong tin nhắn
+var htmlTag = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+
+// PlainText bỏ thẻ và escape của tin nhắn HTML, dùng khi Telegram không parse được HTML
+func PlainText(s string) string {
+	return html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+}
+
+// escapeArg escape tham số của T. Số và giá trị không chứa ký tự đặc biệt giữ nguyên để format (%d, %.1f...) vẫn đúng.
+func escapeArg(a interface{}) interface{} {
+	var s string
+	switch v := a.(type) {
+	case HTML:
+		return string(v)
+	case string:
+		s = v
+	case error:
+		s = v.Error()
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		if rv := reflect.ValueOf(a); rv.Kind() != reflect.String {
+			return a
+		} else {
+			s = rv.String()
+		}
+	}
+	if e := Escape(s); e != s {
+		return e
+	}
+	return a
+}
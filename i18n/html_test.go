@@ -0,0 +1,193 @@
+package i18n
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stringer là fmt.Stringer giả (vd: giá trị hiển thị của thiết bị)
+type stringer string
+
+func (s stringer) String() string { return string(s) }
+
+// name là kiểu chuỗi có tên, được escape như string
+type name string
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"plain text", "plain text"},
+		{"a < b > c", "a &lt; b &gt; c"},
+		{"Tom & Jerry", "Tom &amp; Jerry"},
+		{"<b>bold</b>", "&lt;b&gt;bold&lt;/b&gt;"},
+		{`<a href="x">link</a>`, `&lt;a href="x"&gt;link&lt;/a&gt;`},
+		// Nội dung đã escape vẫn là text của người dùng, phải escape lại để hiển thị đúng nguyên văn
+		{"&amp;", "&amp;amp;"},
+		{"&lt;b&gt;", "&amp;lt;b&amp;gt;"},
+		// Ký tự đặc biệt của Markdown không có ý nghĩa trong HTML
+		{"*bold* _it_ `code` [x](y) ~s~", "*bold* _it_ `code` [x](y) ~s~"},
+		{"PC_01 *Gaming*", "PC_01 *Gaming*"},
+		{"Wi-Fi 🚀 Nhà", "Wi-Fi 🚀 Nhà"},
+	}
+
+	for _, tt := range tests {
+		if got := Escape(tt.in); got != tt.want {
+			t.Errorf("Escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"<b>PC</b> is <i>on</i>", "PC is on"},
+		{"<code>a &lt; b &amp;&amp; c &gt; d</code>", "a < b && c > d"},
+		{"&amp;lt;b&amp;gt;", "&lt;b&gt;"},
+		{"<pre>x</pre>\n<b>y</b>", "x\ny"},
+		// Dấu < không phải thẻ giữ nguyên
+		{"5 < 6 and 7 > 3", "5 < 6 and 7 > 3"},
+		{"*bold* _it_", "*bold* _it_"},
+	}
+
+	for _, tt := range tests {
+		if got := PlainText(tt.in); got != tt.want {
+			t.Errorf("PlainText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// Escape rồi PlainText phải trả lại đúng chuỗi gốc
+func TestPlainTextEscapeRoundTrip(t *testing.T) {
+	for _, s := range []string{"<b>x</b>", "a & b", "&amp;", "<<>>", "*_`[", `"quoted" 'single'`} {
+		if got := PlainText(Escape(s)); got != s {
+			t.Errorf("PlainText(Escape(%q)) = %q", s, got)
+		}
+	}
+}
+
+func TestTEscapesArgs(t *testing.T) {
+	p := NewPrinter(English, time.UTC)
+
+	tests := []struct {
+		name string
+		key  string
+		args []interface{}
+		want string
+	}{
+		{
+			name: "device name injection",
+			key:  "devices.new.item",
+			args: []interface{}{"192.168.1.5", "aa:bb:cc:dd:ee:ff", "<b>Evil</b> & Co"},
+			want: "\n├ IP: <code>192.168.1.5</code>\n├ MAC: <code>aa:bb:cc:dd:ee:ff</code>\n└ Vendor: &lt;b&gt;Evil&lt;/b&gt; &amp; Co\n",
+		},
+		{
+			name: "PC name injection",
+			key:  "wake.online",
+			args: []interface{}{"</b><i>pc", 12.0},
+			want: "✅ <b>PC &lt;/b&gt;&lt;i&gt;pc came online after 12s!</b>",
+		},
+		{
+			name: "SSID injection",
+			key:  "alert.wifi_signal",
+			args: []interface{}{"<script>&amp;", "-80", "-70"},
+			want: "📶 <b>Weak Wi-Fi signal!</b>\n├ SSID: &lt;script&gt;&amp;amp;\n├ Current: <b>-80 dBm</b>\n└ Threshold: -70 dBm",
+		},
+		{
+			name: "markdown metacharacters",
+			key:  "wake.not_found",
+			args: []interface{}{"*pc_1*[x]`"},
+			want: "❓ PC \"*pc_1*[x]`\" not found",
+		},
+		{
+			name: "error arg",
+			key:  "wake.failed",
+			args: []interface{}{errors.New("dial udp <nil>: a & b")},
+			want: "❌ <b>Failed to send the magic packet!</b>\n\n<code>dial udp &lt;nil&gt;: a &amp; b</code>",
+		},
+		{
+			name: "stringer arg",
+			key:  "wake.not_found",
+			args: []interface{}{stringer("<pc>")},
+			want: "❓ PC \"&lt;pc&gt;\" not found",
+		},
+		{
+			name: "named string arg",
+			key:  "wake.not_found",
+			args: []interface{}{name("a&b")},
+			want: "❓ PC \"a&amp;b\" not found",
+		},
+		{
+			name: "HTML arg kept",
+			key:  "wake.not_found",
+			args: []interface{}{HTML("<b>pc</b>")},
+			want: "❓ PC \"<b>pc</b>\" not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.T(tt.key, tt.args...); got != tt.want {
+				t.Errorf("T(%q) =\n%q\nwant\n%q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+// Tham số không phải chuỗi giữ nguyên để verb số (%d, %.1f) vẫn đúng
+func TestTKeepsNumericArgs(t *testing.T) {
+	p := NewPrinter(English, time.UTC)
+	if got, want := p.T("wake.online", "pc", 12.4), "✅ <b>PC pc came online after 12s!</b>"; got != want {
+		t.Errorf("T() = %q, want %q", got, want)
+	}
+}
+
+// Text không escape tham số (dùng cho nút bấm, trả lời callback)
+func TestTextDoesNotEscape(t *testing.T) {
+	p := NewPrinter(English, time.UTC)
+	if got := p.Text("wake.not_found", "<b>pc</b>"); !strings.Contains(got, "<b>pc</b>") {
+		t.Errorf("Text() = %q, want raw arg", got)
+	}
+}
+
+// Mọi tin nhắn trong catalog phải là HTML hợp lệ với Telegram: chỉ dùng thẻ được hỗ trợ và không có & chưa escape.
+// Mô tả lệnh (cmd.*) và nội dung lỗi (*.err.*) là text thường, được escape khi chèn vào tin nhắn.
+func TestCatalogHTML(t *testing.T) {
+	allowed := map[string]bool{"b": true, "i": true, "u": true, "s": true, "code": true, "pre": true, "a": true}
+	for lang, catalog := range catalogs {
+		for key, msg := range catalog {
+			if strings.HasPrefix(key, "cmd.") || strings.Contains(key, ".err.") {
+				continue
+			}
+			for _, tag := range htmlTag.FindAllString(msg, -1) {
+				tagName := strings.Trim(strings.Fields(strings.Trim(tag, "</>"))[0], "/")
+				if !allowed[tagName] {
+					t.Errorf("%s/%s: unsupported tag %s", lang, key, tag)
+				}
+			}
+			for i := strings.Index(msg, "&"); i >= 0; i = nextIndex(msg, "&", i) {
+				rest := msg[i:]
+				if !strings.HasPrefix(rest, "&amp;") && !strings.HasPrefix(rest, "&lt;") && !strings.HasPrefix(rest, "&gt;") && !strings.HasPrefix(rest, "&quot;") {
+					t.Errorf("%s/%s: unescaped & in %q", lang, key, msg)
+					break
+				}
+			}
+		}
+	}
+}
+
+// nextIndex tìm sub trong s sau vị trí i
+func nextIndex(s, sub string, i int) int {
+	j := strings.Index(s[i+1:], sub)
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
@@ -0,0 +1,247 @@
+// Package i18n chứa nội dung tin nhắn của bot theo ngôn ngữ (message catalog)
+// và cách format số, ngày giờ, khoảng thời gian theo ngôn ngữ đó.
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Lang là ngôn ngữ của tin nhắn (mã ngôn ngữ của Telegram, vd: vi, en)
+type Lang string
+
+const (
+	Vietnamese Lang = "vi"
+	English    Lang = "en"
+
+	Default = Vietnamese // Ngôn ngữ khi không chọn và không nhận ra language_code
+)
+
+// Supported là các ngôn ngữ có catalog, theo thứ tự hiện trong /lang
+var Supported = []Lang{Vietnamese, English}
+
+// catalogs là nội dung tin nhắn theo ngôn ngữ, key thiếu trong một ngôn ngữ thì dùng ngôn ngữ mặc định
+var catalogs = map[Lang]map[string]string{
+	Vietnamese: vi,
+	English:    en,
+}
+
+// Parse đọc ngôn ngữ từ mã ngôn ngữ (vd: en, en-US, vi). Trả về false nếu chưa hỗ trợ.
+func Parse(code string) (Lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	for _, l := range Supported {
+		if Lang(code) == l {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// Name trả về tên ngôn ngữ bằng chính ngôn ngữ đó (vd: Tiếng Việt, English)
+func (l Lang) Name() string {
+	return NewPrinter(l, time.UTC).T("lang.name")
+}
+
+// Printer tạo nội dung tin nhắn theo một ngôn ngữ và timezone
+type Printer struct {
+	lang Lang
+	loc  *time.Location
+}
+
+// NewPrinter tạo Printer cho ngôn ngữ lang, ngày giờ hiển thị theo loc (nil = UTC)
+func NewPrinter(lang Lang, loc *time.Location) *Printer {
+	if _, ok := catalogs[lang]; !ok {
+		lang = Default
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Printer{lang: lang, loc: loc}
+}
+
+// Lang trả về ngôn ngữ của Printer
+func (p *Printer) Lang() Lang {
+	return p.lang
+}
+
+// Location trả về timezone dùng khi format ngày giờ
+func (p *Printer) Location() *time.Location {
+	return p.loc
+}
+
+// T trả về nội dung tin nhắn (HTML) của key, format bằng args nếu có.
+// Tham số là chuỗi, error hoặc fmt.Stringer được escape; đoạn HTML đã tạo sẵn (vd: kết quả T khác) truyền bằng kiểu HTML.
+// Key không có trong catalog thì trả về chính key.
+func (p *Printer) T(key string, args ...interface{}) string {
+	escaped := make([]interface{}, len(args))
+	for i, a := range args {
+		escaped[i] = escapeArg(a)
+	}
+	return p.Text(key, escaped...)
+}
+
+// Text trả về nội dung dạng text thường của key (nút bấm, trả lời callback, mô tả lệnh, nội dung lỗi), tham số không được escape
+func (p *Printer) Text(key string, args ...interface{}) string {
+	s, ok := catalogs[p.lang][key]
+	if !ok {
+		if s, ok = catalogs[Default][key]; !ok {
+			s = key
+		}
+	}
+	if len(args) == 0 {
+		return s
+	}
+	return fmt.Sprintf(s, args...)
+}
+
+// Has kiểm tra key có trong catalog không
+func (p *Printer) Has(key string) bool {
+	_, ok := catalogs[Default][key]
+	return ok
+}
+
+// Err trả về nội dung lỗi (text thường) theo ngôn ngữ nếu lỗi là *Error (hoặc errors.Join của các lỗi).
+// Lỗi khác (lỗi hệ thống, lỗi bọc bằng fmt.Errorf) giữ nguyên nội dung.
+func (p *Printer) Err(err error) string {
+	switch e := err.(type) {
+	case *Error:
+		return p.Text(e.Key, e.localizedArgs(p)...)
+	case interface{ Unwrap() []error }:
+		errs := e.Unwrap()
+		lines := make([]string, len(errs))
+		for i, err := range errs {
+			lines[i] = p.Err(err)
+		}
+		return strings.Join(lines, "\n")
+	}
+	return err.Error()
+}
+
+// Number format số thực với prec chữ số thập phân theo ngôn ngữ (vi: 1.234,5; en: 1,234.5)
+func (p *Printer) Number(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if frac != "" {
+		frac = p.T("format.decimal") + frac
+	}
+	return sign + groupThousands(whole, p.T("format.thousands")) + frac
+}
+
+// Percent format phần trăm với prec chữ số thập phân (vd: 42,5%)
+func (p *Printer) Percent(v float64, prec int) string {
+	return p.Number(v, prec) + "%"
+}
+
+// Bytes format số byte dạng dễ đọc (vd: 1,5 MB)
+func (p *Printer) Bytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%s %cB", p.Number(float64(b)/float64(div), 1), "KMGTPE"[exp])
+}
+
+// Duration format khoảng thời gian đầy đủ (vi: 2 ngày 3 giờ 5 phút; en: 2 days 3 hours 5 minutes)
+func (p *Printer) Duration(d time.Duration) string {
+	minutes := int64(d / time.Minute)
+	days, hours := minutes/(24*60), minutes/60%24
+	minutes %= 60
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, p.Plural(days, "unit.day"))
+	}
+	if days > 0 || hours > 0 {
+		parts = append(parts, p.Plural(hours, "unit.hour"))
+	}
+	parts = append(parts, p.Plural(minutes, "unit.minute"))
+	return strings.Join(parts, " ")
+}
+
+// DurationShort format khoảng thời gian ngắn gọn (vd: 2h15m, 45m, 3d4h)
+func (p *Printer) DurationShort(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	switch {
+	case days > 0:
+		return p.T("duration.short.days", days, hours)
+	case hours > 0:
+		return p.T("duration.short.hours", hours, minutes)
+	default:
+		return p.T("duration.short.minutes", minutes)
+	}
+}
+
+// DateTime format ngày giờ đầy đủ theo timezone của Printer
+func (p *Printer) DateTime(t time.Time) string {
+	return t.In(p.loc).Format(p.T("format.datetime"))
+}
+
+// DateTimeShort format ngày giờ không có giây
+func (p *Printer) DateTimeShort(t time.Time) string {
+	return t.In(p.loc).Format(p.T("format.datetime_short"))
+}
+
+// DayTime format ngày giờ không có năm (vd: 24/12 15:04)
+func (p *Printer) DayTime(t time.Time) string {
+	return t.In(p.loc).Format(p.T("format.day_time"))
+}
+
+// DateShort format ngày không có năm (vd: 24/12)
+func (p *Printer) DateShort(t time.Time) string {
+	return t.In(p.loc).Format(p.T("format.date_short"))
+}
+
+// Time format giờ phút giây theo timezone của Printer (vd: 15:04:05)
+func (p *Printer) Time(t time.Time) string {
+	return t.In(p.loc).Format("15:04:05")
+}
+
+// WeekdayShort trả về tên viết tắt của thứ trong tuần (vd: T2, Mon)
+func (p *Printer) WeekdayShort(d time.Weekday) string {
+	return p.T(fmt.Sprintf("weekday.short.%d", d))
+}
+
+// Plural format n bằng key.one hoặc key.other tuỳ theo n (vd: unit.time → 1 time, 3 times)
+func (p *Printer) Plural(n int64, key string) string {
+	if n == 1 {
+		return p.T(key+".one", n)
+	}
+	return p.T(key+".other", n)
+}
+
+// groupThousands chèn dấu phân cách hàng nghìn vào chuỗi chữ số
+func groupThousands(digits, sep string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	var sb strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		sb.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if sb.Len() > 0 {
+			sb.WriteString(sep)
+		}
+		sb.WriteString(digits[i : i+3])
+	}
+	return sb.String()
+}
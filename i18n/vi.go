@@ -0,0 +1,390 @@
+package i18n
+
+// vi là nội dung tin nhắn tiếng Việt (ngôn ngữ mặc định, đầy đủ tất cả key)
+var vi = map[string]string{
+
+	// Ngôn ngữ, định dạng
+	"lang.name":              "Tiếng Việt",
+	"format.decimal":         ",",
+	"format.thousands":       ".",
+	"format.datetime":        "02/01/2006 15:04:05",
+	"format.datetime_short":  "02/01/2006 15:04",
+	"format.date_short":      "02/01",
+	"format.day_time":        "02/01 15:04",
+	"unit.day.one":           "%d ngày",
+	"unit.day.other":         "%d ngày",
+	"unit.hour.one":          "%d giờ",
+	"unit.hour.other":        "%d giờ",
+	"unit.minute.one":        "%d phút",
+	"unit.minute.other":      "%d phút",
+	"unit.time.one":          "%d lần",
+	"unit.time.other":        "%d lần",
+	"duration.short.days":    "%dd%dh",
+	"duration.short.hours":   "%dh%02dm",
+	"duration.short.minutes": "%dm",
+	"weekday.short.0":        "CN",
+	"weekday.short.1":        "T2",
+	"weekday.short.2":        "T3",
+	"weekday.short.3":        "T4",
+	"weekday.short.4":        "T5",
+	"weekday.short.5":        "T6",
+	"weekday.short.6":        "T7",
+	"common.invalid_button":  "❓ Nút không hợp lệ",
+	"common.cancelled":       "❌ Đã huỷ.",
+	"common.error":           "❌ %v",
+	"common.time_footer":     "⏰ <i>Thời gian: %s</i>",
+
+	// Cài đặt, người dùng (lỗi của package config)
+	"setting.err.duration": "%q không phải khoảng thời gian (vd: 30s, 5m)",
+	"setting.err.number":   "%q không phải số",
+	"setting.err.range":    "%s phải trong khoảng %s → %s",
+	"setting.err.integer":  "%s phải là số nguyên",
+	"setting.err.save":     "không lưu được cài đặt: %v",
+	"user.err.role":        "role không hợp lệ %q (admin, operator, viewer)",
+	"user.err.not_found":   "user %d không có trong danh sách",
+	"user.err.last_admin":  "phải còn ít nhất một admin",
+	"user.err.save":        "không lưu được danh sách user: %v",
+
+	// Xử lý lệnh: quyền, giới hạn, lỗi
+	"auth.unauthorized":          "🚫 Bạn không có quyền sử dụng bot này.",
+	"auth.user_id":               "🆔 Your User ID: <code>%d</code>",
+	"auth.role_required":         "🔒 Cần quyền %s",
+	"auth.command_role_required": "🔒 Lệnh /%s cần quyền <b>%s</b> (bạn: %s).",
+	"error.internal":             "❌ Lỗi nội bộ khi xử lý lệnh, vui lòng thử lại.",
+	"error.rate_limited":         "🐢 Bạn gửi lệnh quá nhanh, thử lại sau %v.",
+	"error.timeout":              "⏱️ Lệnh /%s chạy quá %v, đã huỷ.",
+	"error.busy":                 "⏳ Bot đang bận, thử lại sau.",
+	"error.unknown_command":      "❓ Lệnh không hợp lệ. Sử dụng /help để xem danh sách lệnh.",
+	"error.source":               "%s: %v",
+
+	// Danh sách lệnh (/help, menu lệnh của Telegram)
+	"help.title":            "📖 <b>Danh sách lệnh:</b>",
+	"help.role":             "👤 Quyền của bạn: <b>%s</b>",
+	"help.syntax":           "⚠️ Cú pháp: %s\n📖 %s",
+	"cmd.pi":                "Xem thông tin hệ thống (CPU, RAM, Disk, Network)",
+	"cmd.live":              "Bảng số liệu tự cập nhật",
+	"cmd.live.usage":        "<chu kỳ> <thời gian>",
+	"cmd.wake":              "Bật PC qua Wake-on-LAN",
+	"cmd.wake.usage":        "<tên>",
+	"cmd.sleep":             "Cho PC ngủ qua pc-agent",
+	"cmd.sleep.usage":       "<tên>",
+	"cmd.shutdown":          "Tắt PC qua pc-agent",
+	"cmd.shutdown.usage":    "<tên>",
+	"cmd.pcstats":           "Thời gian sử dụng PC theo ngày/tuần",
+	"cmd.pcstats.usage":     "<tên>",
+	"cmd.ip":                "Xem IP public của Pi",
+	"cmd.devices":           "Thiết bị trong mạng LAN",
+	"cmd.devices.usage":     "<scan|name|history>",
+	"cmd.schedule":          "Lịch bật PC, báo cáo tự động",
+	"cmd.schedule.usage":    "<add|list|rm>",
+	"cmd.id":                "Xem User ID của bạn",
+	"cmd.alert":             "Xem trạng thái cảnh báo",
+	"cmd.set":               "Đổi ngưỡng cảnh báo, chu kỳ kiểm tra",
+	"cmd.set.usage":         "<key> <giá trị>",
+	"cmd.user":              "Quản lý người dùng và quyền",
+	"cmd.user.usage":        "<add|role|rm>",
+	"cmd.subscribe":         "Chọn loại thông báo, đăng ký group/channel",
+	"cmd.unsubscribe":       "Huỷ đăng ký nhận thông báo",
+	"cmd.unsubscribe.usage": "<chat id>",
+	"cmd.lang":              "Chọn ngôn ngữ",
+	"cmd.lang.usage":        "<vi|en|auto>",
+	"cmd.help":              "Hiển thị trợ giúp",
+	"cmd.start":             "Bắt đầu",
+
+	// Cài đặt cảnh báo (/set)
+	"set.usage":               "<code>/set</code> - menu cài đặt\n<code>/set &lt;key&gt; &lt;giá trị&gt;</code> - đổi giá trị (vd: <code>/set cpu_temp 75</code>, <code>/set interval 1m</code>)\n<code>/set &lt;key&gt; reset</code> - về giá trị trong cấu hình",
+	"set.unknown":             "❓ Không có cài đặt <code>%s</code>. Có: %s",
+	"set.updated":             "✅ <b>Đã cập nhật</b> %s: <code>%s</code>",
+	"set.reset":               "↩️ <b>Đã về mặc định</b> %s: <code>%s</code>",
+	"set.reset_note":          "↩️ Đã về giá trị trong cấu hình",
+	"set.title":               "⚙️ <b>Cài đặt cảnh báo</b>",
+	"set.overridden_note":     "<i>✏️ đã đổi bằng /set, ưu tiên hơn cấu hình</i>",
+	"set.current":             "├ Hiện tại: <code>%s</code>",
+	"set.configured":          "├ Cấu hình: <code>%s</code>",
+	"set.range":               "└ Khoảng: <code>%s</code> → <code>%s</code>",
+	"set.button":              "⚙️ Cài đặt",
+	"set.button.default":      "↩️ Mặc định",
+	"common.back":             "⬅️ Quay lại",
+	"setting.cpu_temp":        "🌡️ Nhiệt độ CPU",
+	"setting.cpu_usage":       "📈 Sử dụng CPU",
+	"setting.memory":          "💾 Sử dụng RAM",
+	"setting.disk":            "💿 Sử dụng Disk",
+	"setting.wifi_signal":     "📶 Tín hiệu Wi-Fi",
+	"setting.wifi_reconnects": "🔁 Wi-Fi kết nối lại",
+	"setting.wifi_window":     "🕑 Cửa sổ đếm kết nối lại",
+	"setting.interval":        "⏱️ Kiểm tra mỗi",
+	"setting.cooldown":        "🔕 Cảnh báo lại sau",
+
+	// Người dùng (/user)
+	"user.usage":           "👥 <b>Quản lý người dùng</b>\n\n<code>/user list</code> - danh sách người dùng\n<code>/user add &lt;id&gt; [role]</code> - thêm người dùng (mặc định: viewer)\n<code>/user role &lt;id&gt; &lt;role&gt;</code> - đổi quyền\n<code>/user rm &lt;id&gt;</code> - xoá người dùng\n\n<b>Role:</b>\n├ <code>viewer</code> - xem thông tin (/pi, /ip, /devices, /alert...)\n├ <code>operator</code> - thêm /wake, /schedule, đặt tên thiết bị\n└ <code>admin</code> - thêm /sleep, /shutdown, /set, /user",
+	"user.invalid_id_hint": "⚠️ User ID không hợp lệ (dùng /id để xem User ID)",
+	"user.invalid_id":      "⚠️ User ID không hợp lệ",
+	"user.added":           "✅ <b>Đã thêm user</b> <code>%d</code>: %s %s",
+	"user.role_changed":    "✅ <b>Đã đổi quyền user</b> <code>%d</code>: %s → %s %s",
+	"user.rm_syntax":       "⚠️ Cú pháp: /user rm &lt;id&gt;",
+	"user.removed":         "🗑️ Đã xoá user <code>%d</code>",
+	"user.title":           "👥 <b>Người dùng</b>",
+	"user.overridden_note": "<i>✏️ đã đổi bằng /user, ưu tiên hơn cấu hình</i>",
+
+	// Đăng ký nhận thông báo (/subscribe, /unsubscribe)
+	"subscribe.usage":             "🔔 <b>Đăng ký nhận thông báo</b>\n\n<code>/subscribe</code> - xem đăng ký của chat này\n<code>/subscribe all</code> - nhận tất cả thông báo\n<code>/subscribe system power min=warning</code> - chỉ nhận một số loại, từ mức độ\n<code>/subscribe chat &lt;@channel|chat_id&gt; [loại...]</code> - đăng ký channel/group khác\n<code>/subscribe list</code> - các chat đã đăng ký\n<code>/unsubscribe [chat_id]</code> - huỷ đăng ký\n\n<b>Loại:</b> <code>system</code>, <code>devices</code>, <code>power</code>, <code>ip</code>, <code>config</code>\n<b>Mức độ:</b> <code>info</code>, <code>warning</code>, <code>critical</code>\n\n<i>Trong group có topic, đăng ký theo topic đang gửi lệnh. Chat riêng mặc định nhận tất cả thông báo.</i>",
+	"subscribe.chat_not_found":    "❌ Không tìm thấy chat %s: %v\n\nThêm bot vào group/channel (channel cần quyền đăng bài) rồi thử lại.",
+	"subscribe.confirm":           "🔔 Chat này sẽ nhận thông báo từ Pi Monitor: %s",
+	"subscribe.cannot_send":       "❌ Bot không gửi được tin nhắn vào %s: %v",
+	"subscribe.save_failed":       "❌ Không lưu được đăng ký: %v",
+	"subscribe.subscribed":        "✅ <b>Đã đăng ký</b> %s (<code>%d</code>)\n\n%s",
+	"subscribe.invalid":           "❌ %v\n\nGõ /subscribe để xem hướng dẫn.",
+	"subscribe.updated":           "✅ <b>Đã cập nhật đăng ký</b>",
+	"subscribe.receiving":         "🔔 <b>Chat này đang nhận:</b>",
+	"subscribe.receiving_default": "🔔 <b>Chat này đang nhận:</b> tất cả thông báo (mặc định)",
+	"subscribe.not_subscribed":    "🔕 <b>Chat này chưa đăng ký nhận thông báo</b>",
+	"subscribe.list_empty":        "🔔 Chưa có chat nào đăng ký. Người dùng nhận tất cả thông báo qua chat riêng.",
+	"subscribe.list_title":        "🔔 <b>Các chat đã đăng ký</b>",
+	"subscribe.private_chat":      "👤 chat riêng",
+	"subscribe.no_longer_allowed": "(không còn quyền)",
+	"subscribe.none":              "🔕 không nhận thông báo nào",
+	"subscribe.types":             "%s (từ mức %s)",
+	"unsubscribe.syntax":          "⚠️ Cú pháp: /unsubscribe &lt;chat_id&gt; (xem bằng /subscribe list)",
+	"unsubscribe.all_off":         "🔕 Đã tắt tất cả thông báo. Dùng /subscribe all để bật lại.",
+	"unsubscribe.done":            "🔕 Đã huỷ đăng ký nhận thông báo.",
+	"notify.system":               "🚨 Cảnh báo hệ thống",
+	"notify.devices":              "📡 Thiết bị lạ",
+	"notify.power":                "🖥️ PC bật/tắt",
+	"notify.ip":                   "🌍 IP public",
+	"notify.config":               "🔄 Cấu hình",
+	"notify.err.type":             "loại thông báo không hợp lệ %q (system, devices, power, ip, config)",
+	"notify.err.severity":         "mức độ không hợp lệ %q (info, warning, critical)",
+	"notify.err.not_subscribed":   "chat %d chưa đăng ký nhận thông báo",
+
+	// Cảnh báo hệ thống
+	"alert.title":          "🚨 <b>CẢNH BÁO HỆ THỐNG RASPBERRY PI</b>",
+	"alert.cpu_temp":       "🌡️ <b>Nhiệt độ CPU quá cao!</b>\n├ Hiện tại: <b>%s°C</b>\n└ Ngưỡng: %s°C",
+	"alert.cpu_usage":      "📈 <b>CPU đang quá tải!</b>\n├ Hiện tại: <b>%s</b>\n└ Ngưỡng: %s",
+	"alert.memory":         "💾 <b>RAM sắp hết!</b>\n├ Đã dùng: <b>%s</b> (%s/%s)\n└ Ngưỡng: %s",
+	"alert.disk":           "💿 <b>Ổ đĩa sắp đầy!</b>\n├ Đã dùng: <b>%s</b> (%s/%s)\n└ Ngưỡng: %s",
+	"alert.wifi_reconnect": "📶 <b>Wi-Fi kết nối lại liên tục!</b>\n├ Interface: %s\n├ Kết nối lại: <b>%s</b> trong %s\n└ Ngưỡng: %s",
+	"alert.wifi_signal":    "📶 <b>Tín hiệu Wi-Fi yếu!</b>\n├ SSID: %s\n├ Hiện tại: <b>%s dBm</b>\n└ Ngưỡng: %s dBm",
+
+	// Bảng số liệu cập nhật liên tục (/live)
+	"live.reason.timeout":     "hết thời gian",
+	"live.reason.stopped":     "đã bấm dừng",
+	"live.reason.replaced":    "đã mở /live mới trong chat này",
+	"live.reason.shutdown":    "bot đang dừng",
+	"live.finished":           "⏹️ <i>Đã dừng cập nhật: %s. Gõ /live để xem tiếp.</i>",
+	"live.usage":              "❌ %s\n\nCú pháp: <code>/live [chu kỳ] [thời gian]</code>, vd: <code>/live 10s 15m</code> (mặc định %v trong %v)",
+	"live.loading":            "⏳ Đang lấy số liệu...",
+	"live.started":            "📈 Cập nhật mỗi %v trong %v",
+	"live.stopped":            "⏹️ Đã dừng cập nhật",
+	"live.button.start":       "📈 Cập nhật liên tục",
+	"live.button.stop":        "⏹️ Dừng",
+	"live.err.too_many_args":  "quá nhiều tham số",
+	"live.err.interval":       "chu kỳ không hợp lệ %q",
+	"live.err.duration":       "thời gian không hợp lệ %q",
+	"live.err.min_interval":   "chu kỳ tối thiểu %v",
+	"live.err.duration_range": "thời gian phải từ %v đến %v",
+	"live.title":              "📈 <b>Pi Live</b> (mỗi %v)",
+	"live.temperature":        "🌡️ Nhiệt độ: %s°C%s%s",
+	"live.network":            "🌐 Mạng: 📤 %s/s%s · 📥 %s/s%s",
+	"live.wifi_disconnected":  "📶 Wi-Fi: ❌ không có kết nối",
+	"live.footer":             "🕐 %s · còn %s",
+
+	// Thông tin hệ thống (/pi)
+	"pi.error":             "❌ Lỗi khi lấy thông tin hệ thống: %v",
+	"pi.status":            "🍓 <b>Raspberry Pi Status</b>\n\n🖥️ <b>CPU</b>\n├ Sử dụng: %s\n├ Nhiệt độ: %s°C\n├ Cores: %d\n└ Tần số: %s MHz\n\n💾 <b>RAM</b>\n├ Tổng: %s\n├ Đã dùng: %s (%s)\n└ Còn trống: %s\n\n💿 <b>Disk</b>\n├ Tổng: %s\n├ Đã dùng: %s (%s)\n└ Còn trống: %s\n\n🌐 <b>Network</b>\n├ IP: %s\n├ Gửi: %s\n└ Nhận: %s\n%s\n⏱️ <b>Uptime</b>: %s\n🕐 <b>Cập nhật</b>: %s",
+	"pi.wifi.disconnected": "❌ Không có kết nối",
+	"pi.wifi.signal":       "Tín hiệu: %s dBm (%s)",
+	"pi.wifi.quality":      "Chất lượng: %s",
+	"pi.wifi.frequency":    "Tần số: %s MHz",
+	"pi.signal.excellent":  "rất tốt",
+	"pi.signal.good":       "tốt",
+	"pi.signal.fair":       "trung bình",
+	"pi.signal.weak":       "yếu",
+
+	// Wake-on-LAN, pc-agent (lỗi của package services)
+	"wol.err.mac":             "địa chỉ MAC không hợp lệ: %s",
+	"wol.err.parse_mac":       "không thể parse MAC address: %v",
+	"wol.err.secureon":        "SecureOn password không hợp lệ (cần dạng AA:BB:CC:DD:EE:FF hoặc a.b.c.d)",
+	"wol.err.interface":       "không tìm thấy interface %s: %v",
+	"wol.err.interface_addrs": "không thể đọc địa chỉ của %s: %v",
+	"wol.err.no_ipv4":         "interface %s không có địa chỉ IPv4",
+	"wol.err.mode":            "chế độ gửi không hợp lệ: %s (udp, raw, all)",
+	"wol.err.raw_interface":   "chế độ raw cần cấu hình interface",
+	"wol.err.udp_listen":      "không thể mở kết nối UDP: %v",
+	"wol.err.broadcast":       "địa chỉ broadcast không hợp lệ: %v",
+	"wol.err.send":            "không thể gửi magic packet đến %s: %v",
+	"wol.err.not_ethernet":    "interface %s không phải Ethernet",
+	"wol.err.raw_socket":      "không mở được raw socket (cần root/CAP_NET_RAW): %v",
+	"wol.err.send_frame":      "không thể gửi Ethernet frame qua %s: %v",
+	"wol.err.bind_linux_only": "SO_BINDTODEVICE chỉ hỗ trợ trên Linux",
+	"wol.err.raw_linux_only":  "chế độ raw chỉ hỗ trợ trên Linux",
+	"agent.err.no_secret":     "chưa cấu hình secret cho agent",
+	"agent.err.url":           "URL agent không hợp lệ: %v",
+	"agent.err.connect":       "không kết nối được agent: %v",
+	"agent.err.response":      "phản hồi agent không hợp lệ (HTTP %d): %v",
+	"agent.err.rejected":      "agent từ chối (HTTP %d): %s",
+
+	// Kiểm tra PC online (lỗi của package services)
+	"reach.err.no_target":   "chưa cấu hình host hoặc MAC",
+	"reach.err.no_host":     "chưa cấu hình host",
+	"reach.err.no_mac":      "chưa cấu hình MAC",
+	"reach.err.probe":       "%s: %v",
+	"reach.err.icmp_socket": "không mở được ICMP socket: %v",
+	"reach.err.arp_missing": "MAC không có trong bảng ARP",
+	"reach.err.arp_state":   "ARP entry ở trạng thái %s",
+	"reach.err.resolve":     "không phân giải được %s",
+
+	// IP public, thiết bị, lịch (lỗi của package services)
+	"publicip.err.ipv4":             "không thể lấy IPv4 public: %v",
+	"publicip.err.ipv6":             "không thể lấy IPv6 public: %v",
+	"publicip.err.no_endpoint":      "chưa cấu hình endpoint nào",
+	"publicip.err.not_ip":           "phản hồi không phải địa chỉ IP: %q",
+	"publicip.err.family":           "địa chỉ %s không phải IPv%s",
+	"publicip.err.stun_short":       "phản hồi STUN quá ngắn",
+	"publicip.err.stun_type":        "phản hồi STUN không hợp lệ: type 0x%04x",
+	"publicip.err.stun_transaction": "transaction ID không khớp",
+	"publicip.err.stun_truncated":   "phản hồi STUN bị cắt",
+	"publicip.err.stun_no_address":  "không tìm thấy địa chỉ trong phản hồi STUN",
+	"devices.err.arp":               "không thể đọc bảng ARP: %v",
+	"devices.err.oui":               "không thể đọc file OUI: %v",
+	"schedule.err.cron":             "biểu thức cron không hợp lệ: %v",
+	"schedule.err.not_found":        "không tìm thấy job #%d",
+	"schedule.err.save":             "không lưu được lịch: %v",
+
+	// Lịch chạy tự động (/schedule)
+	"schedule.usage":                "⏰ <b>Lịch chạy tự động</b>\n\n<code>/schedule add &lt;cron&gt; &lt;lệnh&gt;</code> - thêm job\n<code>/schedule list</code> - danh sách job\n<code>/schedule rm &lt;id&gt;</code> - xoá job\n\n<b>Cron:</b> <code>phút giờ ngày tháng thứ</code> hoặc <code>@daily</code>, <code>@every 1h</code>\n<b>Lệnh:</b> <code>wake &lt;tên&gt;</code>, <code>status</code>, <code>disk</code>\n\n<i>Ví dụ:</i>\n<code>/schedule add 30 8 * * 1-5 wake office-pc</code>\n<code>/schedule add 0 21 * * * status</code>\n<code>/schedule add 0 9 * * 0 disk</code>",
+	"schedule.added":                "✅ <b>Đã thêm job #%d</b>\n\n├ Lịch: <code>%s</code>\n├ Lệnh: <code>%s</code>\n└ Lần chạy tới: %s %s",
+	"schedule.rm_syntax":            "⚠️ Cú pháp: /schedule rm &lt;id&gt;",
+	"schedule.invalid_id":           "⚠️ ID không hợp lệ",
+	"schedule.removed":              "🗑️ Đã xoá job #%d",
+	"schedule.err.missing_spec":     "thiếu biểu thức cron hoặc lệnh",
+	"schedule.err.missing_command":  "thiếu lệnh",
+	"schedule.err.need_target":      "cần tên PC: wake <tên>",
+	"schedule.err.target_not_found": "không tìm thấy PC \"%s\"",
+	"schedule.err.unsupported":      "lệnh \"%s\" không hỗ trợ chạy theo lịch",
+	"schedule.err.empty":            "lệnh rỗng",
+	"schedule.err.job_target":       "không tìm thấy PC cho lệnh \"%s\"",
+	"schedule.err.job_unsupported":  "lệnh \"%s\" không hỗ trợ",
+	"schedule.err.send":             "không gửi được kết quả: %v",
+	"schedule.err.not_owner":        "job #%d do người khác tạo, chỉ người tạo hoặc admin được xoá",
+	"schedule.err.creator_role":     "người tạo job (user %d) không còn quyền operator, bỏ qua",
+	"schedule.job_header":           "⏰ <b>Job #%d</b> · <code>%s</code>\n\n",
+	"schedule.waking":               "🔌 Đang bật PC <b>%s</b>...",
+	"schedule.disk_ok":              "✅ Dung lượng ổn",
+	"schedule.disk_over":            "⚠️ <b>Vượt ngưỡng %s - nên dọn dẹp ổ đĩa!</b>",
+	"schedule.disk":                 "💿 <b>Kiểm tra ổ đĩa</b>\n├ Tổng: %s\n├ Đã dùng: %s (%s)\n└ Còn trống: %s\n\n%s",
+	"schedule.list_empty":           "⏰ <b>Lịch chạy tự động</b>\n\n<i>Chưa có job nào. Dùng /schedule add để thêm.</i>",
+	"schedule.list_title":           "⏰ <b>Lịch chạy tự động</b> (%s)\n",
+	"schedule.next":                 "├ Lần tới: %s\n",
+	"schedule.never_run":            "└ Chưa chạy lần nào\n",
+	"schedule.last_failed":          "└ ❌ %s: %s\n",
+	"schedule.last_ok":              "└ ✅ %s\n",
+
+	// Bật PC (/wake)
+	"wake.not_configured":     "⚠️ <b>Chưa cấu hình Wake-on-LAN</b>\n\nVui lòng thiết lập biến môi trường:\n<code>WOL_MAC_ADDRESS=AA:BB:CC:DD:EE:FF</code>\n<code>WOL_HOST=192.168.1.100</code> <i>(tuỳ chọn, để kiểm tra trạng thái)</i>\n\nHoặc nhiều PC:\n<code>WOL_TARGETS=office,gaming</code>\n<code>WOL_OFFICE_MAC=AA:BB:CC:DD:EE:FF</code>",
+	"wake.not_found_hint":     "❓ Không tìm thấy PC \"%s\". Có: %s",
+	"wake.not_found":          "❓ Không tìm thấy PC \"%s\"",
+	"wake.menu":               "🖥️ <b>Chọn PC cần bật:</b>\n\n<i>🟢 online · ⚫ offline · ❔ không kiểm tra được</i>",
+	"wake.already_on":         "✅ <b>PC %s đã đang bật!</b>\n\n🖥️ Host: <code>%s</code>\n📡 MAC: <code>%s</code>\n📶 Phản hồi: %s\n\n<i>Không cần gửi magic packet.</i>",
+	"wake.sent_host":          "🚀 <b>Đã gửi lệnh khởi động PC %s thành công!</b>\n\n🖥️ Host: <code>%s</code>\n📡 MAC: <code>%s</code>\n📦 Broadcast: <code>%s</code>\n\n⏳ <i>PC sẽ khởi động trong vài giây...</i>",
+	"wake.sent":               "🚀 <b>Đã gửi magic packet Wake-on-LAN đến %s thành công!</b>\n\n📡 MAC: <code>%s</code>\n📦 Broadcast: <code>%s</code>\n\n⏳ <i>PC sẽ khởi động trong vài giây...</i>",
+	"wake.progress":           "🚀 <b>Đang bật PC %s</b>\n\n🖥️ Host: <code>%s</code>\n📡 MAC: <code>%s</code>\n📦 Broadcast: <code>%s</code>\n\n",
+	"wake.step.sent":          "Đã gửi magic packet (1/%d)",
+	"wake.step.online":        "PC đã online (%s)",
+	"wake.step.resend_failed": "Gửi lại magic packet thất bại: %s",
+	"wake.step.resent":        "Gửi lại magic packet (%d/%d)",
+	"wake.waiting":            "⏳ <i>Đang chờ PC online...</i>",
+	"wake.waiting_elapsed":    "⏳ <i>Đang chờ PC online... %.0fs / %.0fs</i>",
+	"wake.stopped":            "⏹️ <i>Ngừng theo dõi: bot đang dừng.</i>",
+	"wake.online":             "✅ <b>PC %s đã online sau %.0fs!</b>",
+	"wake.timeout":            "❌ <b>PC %s không online sau %.0fs</b>\n<i>Kiểm tra nguồn điện, cáp mạng và cấu hình WOL trong BIOS.</i>",
+	"wake.failed":             "❌ <b>Gửi magic packet thất bại!</b>\n\n<code>%s</code>",
+
+	// Tắt, ngủ PC (/sleep, /shutdown)
+	"power.not_configured": "⚠️ <b>Chưa cấu hình pc-agent</b>\n\nChạy <code>pc-agent</code> trên PC và thiết lập:\n<code>WOL_AGENT_URL=http://192.168.1.100:9770</code>\n<code>WOL_AGENT_SECRET=&lt;secret&gt;</code>\n\nHoặc với nhiều PC: <code>WOL_&lt;TÊN&gt;_AGENT_URL</code>, <code>WOL_&lt;TÊN&gt;_AGENT_SECRET</code>",
+	"power.syntax":         "⚠️ Cú pháp: /%s &lt;tên&gt;. Có: %s",
+	"power.no_agent":       "⚠️ PC %s chưa cấu hình pc-agent (WOL_&lt;TÊN&gt;_AGENT_URL)",
+	"power.confirm":        "%s <b>PC %s?</b>\n\n🖥️ Agent: <code>%s</code>\n\n<i>Bấm xác nhận để gửi lệnh.</i>",
+	"power.button.confirm": "✅ Xác nhận",
+	"power.button.cancel":  "❌ Huỷ",
+	"power.failed":         "❌ <b>Gửi lệnh %s đến %s thất bại!</b>\n\n<code>%s</code>",
+	"power.sent":           "✅ <b>Đã gửi lệnh %s đến PC %s</b>\n\n🖥️ Hostname: <code>%s</code>\n💬 %s",
+
+	// Thiết bị trong mạng LAN (/devices)
+	"devices.scan_error":     "❌ Lỗi khi quét mạng LAN: %s",
+	"devices.name_syntax":    "⚠️ Cú pháp: /devices name &lt;MAC&gt; &lt;tên&gt;",
+	"devices.name_error":     "❌ Không thể đặt tên: %s",
+	"devices.name_cleared":   "✅ Đã xoá tên của %s",
+	"devices.named":          "✅ Đã đặt tên %s cho %s",
+	"devices.history_syntax": "⚠️ Cú pháp: /devices history &lt;MAC|IP|tên&gt;",
+	"devices.not_found":      "❓ Không tìm thấy thiết bị",
+	"devices.syntax":         "⚠️ Cú pháp: /devices [scan | name &lt;MAC&gt; &lt;tên&gt; | history &lt;MAC&gt;]",
+	"devices.list_empty":     "📡 <b>Thiết bị trong mạng LAN</b>\n\n<i>Chưa thấy thiết bị nào trong bảng ARP.</i>",
+	"devices.list_title":     "📡 <b>Thiết bị trong mạng LAN</b> (%d/%d online)\n",
+	"devices.vendor":         "├ Hãng: %s\n",
+	"devices.last_seen":      "└ Thấy lần cuối: %s\n",
+	"devices.history_title":  "📜 <b>Lịch sử: %s</b>\n\n",
+	"devices.first_seen":     "└ Thấy lần đầu: %s\n",
+	"devices.history_empty":  "\n<i>Chưa có lịch sử.</i>",
+	"devices.vendor.random":  "MAC ngẫu nhiên",
+	"devices.new.title":      "📡 <b>Phát hiện thiết bị mới trong mạng LAN!</b>\n",
+	"devices.new.item":       "\n├ IP: <code>%s</code>\n├ MAC: <code>%s</code>\n└ Hãng: %s\n",
+	"devices.new.hint":       "\n<i>Đặt tên bằng /devices name &lt;MAC&gt; &lt;tên&gt;</i>",
+
+	// Thông báo: PC bật/tắt, IP public, khởi động
+	"power.event.online":     "🟢 <b>PC %s vừa bật</b>\n\n├ Phản hồi: %s\n└ Đã tắt: %s",
+	"power.event.offline":    "⚫ <b>PC %s vừa tắt</b>\n\n└ Phiên sử dụng: %s",
+	"publicip.changed.title": "🌍 <b>IP public đã thay đổi!</b>\n\n",
+	"publicip.changed.ipv4":  "├ IPv4 cũ: <code>%s</code>\n├ IPv4 mới: <code>%s</code>\n",
+	"publicip.changed.ipv6":  "├ IPv6 cũ: <code>%s</code>\n├ IPv6 mới: <code>%s</code>\n",
+	"publicip.changed.since": "└ IP cũ ghi nhận từ: %s",
+	"startup.reboot":         "🔁 <b>Pi vừa khởi động lại!</b>\n\n",
+	"startup.crash":          "💥 <b>Bot khởi động lại sau khi bị dừng đột ngột!</b>\n\n",
+	"startup.restart":        "🟢 <b>Bot đã khởi động lại</b>\n\n",
+	"startup.first":          "🟢 <b>Bot đã khởi động!</b>\n\n",
+	"startup.pi_uptime":      "├ Pi đã chạy trước khi khởi động lại: %s\n",
+	"startup.previous_run":   "├ Lần chạy trước: %s\n",
+	"startup.last_seen":      "├ Hoạt động lần cuối: %s\n",
+	"startup.downtime":       "└ Không hoạt động: %s\n",
+	"startup.unclean":        "\n⚠️ <i>Bot không dừng bình thường trước khi Pi khởi động lại (mất điện?)</i>\n",
+	"startup.help_hint":      "<i>Sử dụng /help để xem danh sách lệnh.</i>",
+
+	// IP public (/ip), thời gian dùng PC (/pcstats)
+	"ip.error":               "❌ Lỗi khi lấy IP public: %s",
+	"ip.last_known":          "⚠️ <b>Không thể lấy IP public hiện tại</b>\n\nGiá trị gần nhất:\n├ IPv4: <code>%s</code>\n├ IPv6: <code>%s</code>\n└ Cập nhật: %s",
+	"ip.current":             "🌍 <b>IP Public</b>\n\n├ IPv4: <code>%s</code>\n├ IPv6: <code>%s</code>\n└ LAN: <code>%s</code>",
+	"pcstats.not_configured": "⚠️ Chưa cấu hình PC nào cho Wake-on-LAN.",
+	"pcstats.title":          "📊 <b>Thời gian sử dụng PC</b>\n",
+	"pcstats.disabled":       "\n<i>Theo dõi đang tắt, đặt WOL_TRACK_ENABLED=true để bật.</i>",
+	"pcstats.unknown":        "🖥️ <b>%s</b> — ❔ chưa có dữ liệu\n",
+	"pcstats.online":         "🖥️ <b>%s</b> — 🟢 bật từ %s (%s)\n",
+	"pcstats.offline":        "🖥️ <b>%s</b> — ⚫ tắt từ %s\n",
+	"pcstats.today":          "├ Hôm nay: <b>%s</b>\n",
+	"pcstats.week":           "├ 7 ngày: <b>%s</b>\n",
+
+	// Ngôn ngữ (/lang)
+	"lang.title":         "🌐 <b>Ngôn ngữ</b>\n\nĐang dùng: <b>%s</b> (%s)\n\n<i>Chọn ngôn ngữ cho chat này, hoặc Tự động để theo ngôn ngữ ứng dụng Telegram của từng người.</i>",
+	"lang.source.chosen": "đã chọn",
+	"lang.source.auto":   "tự động",
+	"lang.button.auto":   "🔄 Tự động",
+	"lang.usage":         "Cú pháp: <code>/lang &lt;vi|en|auto&gt;</code>",
+	"lang.changed":       "✅ Đã chuyển sang <b>%s</b>",
+	"lang.auto":          "✅ Ngôn ngữ tự động theo ứng dụng Telegram (hiện tại: <b>%s</b>)",
+	"lang.err.unknown":   "chưa hỗ trợ ngôn ngữ %q",
+	"lang.err.save":      "không lưu được ngôn ngữ: %v",
+
+	// Lệnh chung, trạng thái cảnh báo, reload cấu hình, dừng bot
+	"id.role":                 "👤 Quyền: %s",
+	"start.welcome":           "👋 Xin chào! Sử dụng lệnh /pi để xem thông tin hệ thống Raspberry Pi.",
+	"callback.processing":     "⏳ Đang xử lý...",
+	"callback.sending":        "⏳ Đang gửi lệnh...",
+	"alert.status.enabled":    "🚨 <b>Trạng thái cảnh báo</b>\n\n✅ <b>Trạng thái:</b> Đang hoạt động\n⏱️ <b>Kiểm tra mỗi:</b> %v\n🔕 <b>Cảnh báo lại sau:</b> %v\n👥 <b>Gửi đến:</b> %d người dùng\n\n📊 <b>Ngưỡng cảnh báo:</b>\n├ 🌡️ Nhiệt độ CPU: &gt; %.0f°C\n├ 📈 Sử dụng CPU: &gt; %.0f%%\n├ 💾 Sử dụng RAM: &gt; %.0f%%\n├ 💿 Sử dụng Disk: &gt; %.0f%%\n└ 📶 Tín hiệu Wi-Fi: &lt; %.0f dBm\n\n<i>Bạn sẽ nhận cảnh báo khi hệ thống vượt ngưỡng. Đổi ngưỡng bằng /set hoặc nút bên dưới.</i>",
+	"alert.status.disabled":   "🚨 <b>Trạng thái cảnh báo</b>\n\n❌ <b>Trạng thái:</b> Đã tắt\n\n<i>Đặt ALERT_ENABLED=true và cấu hình người dùng (ADMIN_USERS) để bật</i>",
+	"config.rejected":         "❌ <b>Cấu hình mới bị từ chối</b> (%s)\n\n<pre>%s</pre>\n<i>Bot vẫn dùng cấu hình cũ.</i>",
+	"config.reloaded":         "🔄 <b>Đã reload cấu hình</b> (%s)\n",
+	"config.applied":          "\n✅ <b>Đã áp dụng:</b>\n",
+	"config.restart_required": "\n⏸️ <b>Cần khởi động lại để áp dụng:</b>\n",
+	"shutdown.notice":         "🔴 <b>Bot đang dừng</b>\n\n🤖 Bot: @%s\n🕐 Thời gian: <code>%s</code>",
+
+	// Hàng đợi thông báo
+	"outbox.delayed": "⏳ <i>Thông báo gửi trễ, tạo lúc %s</i>",
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"pi-monitor/config"
+	"pi-monitor/i18n"
+)
+
+// exitAfterShutdownTimeout chờ tín hiệu dừng (ctx bị huỷ) rồi buộc thoát nếu bot chưa dừng xong sau shutdown.timeout.
+// Tín hiệu thứ hai (Ctrl+C lần nữa) thoát ngay.
+func exitAfterShutdownTimeout(ctx context.Context, stop context.CancelFunc, store *config.Holder) {
+	<-ctx.Done()
+	stop()
+
+	timeout := store.Get().ShutdownTimeout
+	log.Printf("🛑 Shutting down (timeout: %v)", timeout)
+
+	<-time.After(timeout)
+	log.Printf("⏱️ Shutdown did not finish within %v, exiting", timeout)
+	os.Exit(1)
+}
+
+// formatShutdownNotice format thông báo bot đang dừng
+func formatShutdownNotice(p *i18n.Printer, botName string) string {
+	return p.T("shutdown.notice", botName, p.DateTime(time.Now()))
+}
@@ -1,24 +1,78 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"pi-monitor/config"
 	"pi-monitor/handlers"
+	"pi-monitor/i18n"
 	"pi-monitor/services"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
+// configWatchInterval là khoảng thời gian kiểm tra file cấu hình có thay đổi không
+const configWatchInterval = 5 * time.Second
+
+// runStateInterval là khoảng thời gian ghi lại bot còn chạy, dùng để báo thời điểm hoạt động cuối khi crash
+const runStateInterval = time.Minute
+
+// outboxFlushTimeout là thời gian tối đa gửi nốt thông báo trong hàng đợi khi dừng bot
+const outboxFlushTimeout = 5 * time.Second
+
+// Giới hạn xử lý lệnh
+const (
+	commandWorkers   = 8                // Số lệnh xử lý song song
+	commandQueueSize = 64               // Số lệnh chờ tối đa, quá thì báo bận
+	commandTimeout   = 30 * time.Second // Thời gian tối đa cho mỗi lệnh
+	commandRateEvery = 2 * time.Second  // Mỗi user được thêm một lệnh sau mỗi khoảng này...
+	commandRateBurst = 10               // ...và tối đa 10 lệnh liên tiếp
+)
+
+// commandTimeouts là thời gian tối đa riêng của các lệnh chạy lâu
+var commandTimeouts = map[string]time.Duration{
+	"devices": 2 * time.Minute, // /devices scan quét cả subnet
+	"wake":    time.Minute,     // kiểm tra online từng PC trước khi hiện menu
+}
+
 func main() {
-	cfg := config.Load()
+	// pi-monitor config check [--config <file>]
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:]))
+	}
+
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "File cấu hình YAML (biến môi trường ghi đè giá trị trong file)")
+	flag.Parse()
 
-	if cfg.BotToken == "" {
-		log.Fatal("TELEGRAM_BOT_TOKEN environment variable is required")
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("❌ Invalid configuration, run `pi-monitor config check` for details:\n%v", err)
 	}
 
+	services.WiFiInterface = cfg.WiFiInterface
+
+	// Giá trị đã đổi bằng /set được ưu tiên hơn biến môi trường và file cấu hình
+	overrides := config.LoadOverrides(filepath.Join(cfg.DataDir, "settings.json"))
+	// Quyền đổi bằng /user được ưu tiên hơn danh sách user trong cấu hình
+	users := config.LoadUsers(filepath.Join(cfg.DataDir, "users.json"))
+	overlay := func(c *config.Config) *config.Config {
+		return users.Apply(overrides.Apply(c))
+	}
+	cfg = overlay(cfg)
+
+	// Cấu hình hiện tại, được thay khi reload (SIGHUP hoặc file cấu hình thay đổi)
+	store := config.NewHolder(cfg)
+
 	bot, err := tgbotapi.NewBotAPI(cfg.BotToken)
 	if err != nil {
 		log.Fatalf("Failed to create bot: %v", err)
@@ -26,161 +80,707 @@ func main() {
 
 	log.Printf("🤖 Bot authorized on account %s", bot.Self.UserName)
 
-	if len(cfg.AllowedUsers) > 0 {
-		log.Printf("🔒 Whitelist enabled: %d users allowed", len(cfg.AllowedUsers))
-	} else {
-		log.Printf("⚠️  Whitelist disabled: all users can use this bot")
+	if n := len(cfg.Users()); n > 0 {
+		log.Printf("🔒 Access control enabled: %d users allowed", n)
+	}
+
+	// Dừng bot khi nhận SIGINT/SIGTERM (Ctrl+C, docker stop)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go exitAfterShutdownTimeout(ctx, stop, store)
+
+	// Các vòng lặp nền (monitoring, theo dõi file cấu hình), chờ dừng hết trước khi lưu trạng thái
+	var background sync.WaitGroup
+	goBackground := func(f func()) {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			f()
+		}()
+	}
+
+	// Trạng thái lần chạy để phân biệt Pi khởi động lại, bot crash và khởi động lại bình thường
+	runs, startup := services.NewRunTracker(filepath.Join(cfg.DataDir, "run_state.json"))
+	goBackground(func() {
+		services.StartRunTracking(ctx, runs, runStateInterval)
+	})
+
+	// Đăng ký nhận thông báo của group/channel/forum topic (/subscribe)
+	subs := services.NewSubscriptionStore(filepath.Join(cfg.DataDir, "subscriptions.json"))
+	// Ngôn ngữ chọn bằng /lang và language_code Telegram của từng user
+	langs := services.NewLanguageStore(filepath.Join(cfg.DataDir, "languages.json"))
+	// Hàng đợi thông báo: gửi lại khi lỗi mạng, giữ qua các lần khởi động lại
+	outbox := services.NewOutbox(filepath.Join(cfg.DataDir, "outbox.json"), func(m services.OutboxMessage) error {
+		return deliverNotification(bot, store.Get(), m)
+	})
+	goBackground(func() {
+		outbox.Run(ctx)
+	})
+	// Nội dung thông báo tạo theo ngôn ngữ của từng nơi nhận. key định danh nội dung (không gồm thời gian)
+	// để hàng đợi bỏ thông báo trùng, rỗng = không bỏ.
+	notify := func(t services.NotifyType, sev services.Severity, key string, text func(p *i18n.Printer) string) {
+		notifySubscribers(outbox, store.Get(), subs, langs, t, sev, key, text)
+	}
+
+	// Heartbeat đến dịch vụ giám sát bên ngoài (dead man's switch): báo khi Pi mất điện hoặc bot dừng,
+	// lỗi của vòng lặp monitoring được báo qua <url>/fail
+	var onCheck func(error)
+	if cfg.HeartbeatURL != "" {
+		heartbeat, err := services.NewHeartbeat(cfg.HeartbeatURL)
+		if err != nil {
+			log.Fatalf("❌ Invalid heartbeat URL: %v", err)
+		}
+		onCheck = heartbeat.Report
+		goBackground(func() {
+			services.StartHeartbeat(ctx, heartbeat, cfg.HeartbeatInterval)
+		})
+	}
+
+	// Các monitor chạy khi được bật, kể cả khi chưa có user: notifySubscribers chọn người nhận lúc gửi
+	// nên user thêm sau (reload, /user add) vẫn nhận được thông báo
+	if len(cfg.Users()) == 0 {
+		log.Printf("⚠️  No users configured: all commands are rejected and notifications are sent once users are added (set ADMIN_USERS or ALLOWED_USERS)")
 	}
 
 	// Start alert monitoring if enabled
-	if cfg.AlertEnabled && len(cfg.AllowedUsers) > 0 {
-		thresholds := services.AlertThresholds{
-			CPUTemperature: cfg.CPUTempThreshold,
-			CPUUsage:       cfg.CPUUsageThreshold,
-			MemoryUsage:    cfg.MemoryThreshold,
-			DiskUsage:      cfg.DiskThreshold,
-		}
-		checker := services.NewAlertChecker(thresholds)
-
-		go services.StartMonitoring(checker, cfg.AlertInterval, func(alerts []services.Alert) {
-			message := services.FormatAlerts(alerts)
-
-			// Gửi alert đến tất cả allowed users
-			for _, userID := range cfg.AllowedUsers {
-				msg := tgbotapi.NewMessage(userID, message)
-				msg.ParseMode = "Markdown"
-
-				if _, err := bot.Send(msg); err != nil {
-					log.Printf("❌ Error sending alert to %d: %v", userID, err)
-				} else {
-					log.Printf("✅ Alert sent to user %d", userID)
-				}
-			}
+	var checker *services.AlertChecker
+	if cfg.AlertEnabled {
+		checker = services.NewAlertChecker(alertThresholds(cfg))
+		checker.SetCooldown(cfg.AlertCooldown)
+
+		goBackground(func() {
+			services.StartMonitoring(ctx, checker, cfg.AlertInterval, func(alerts []services.Alert) {
+				notify(services.NotifySystem, services.AlertsSeverity(alerts), services.AlertsKey(alerts), func(p *i18n.Printer) string {
+					return services.FormatAlerts(p, alerts)
+				})
+			}, onCheck)
 		})
 
-		log.Printf("🚨 Alert monitoring enabled (Users: %d, Interval: %v)", len(cfg.AllowedUsers), cfg.AlertInterval)
-	} else if cfg.AlertEnabled && len(cfg.AllowedUsers) == 0 {
-		log.Printf("⚠️  Alert enabled but ALLOWED_USERS not set - alerts disabled")
+		log.Printf("🚨 Alert monitoring enabled (Users: %d, Interval: %v)", len(cfg.Users()), cfg.AlertInterval)
 	} else {
 		log.Printf("ℹ️  Alert monitoring disabled (set ALERT_ENABLED=true to enable)")
+		// Heartbeat vẫn cần vòng lặp kiểm tra để báo lỗi đọc số liệu qua <url>/fail, chỉ không gửi alert
+		if onCheck != nil {
+			goBackground(func() {
+				services.StartMonitoring(ctx, services.NewAlertChecker(alertThresholds(cfg)), cfg.AlertInterval, nil, onCheck)
+			})
+		}
 	}
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
+	// Public IP monitor - luôn tạo để dùng cho lệnh /ip, chỉ chạy nền khi được bật
+	publicIP := services.NewPublicIPMonitor(
+		cfg.PublicIPEndpoints,
+		cfg.PublicIPSTUN,
+		cfg.PublicIPv6,
+		filepath.Join(cfg.DataDir, "public_ip.json"),
+	)
+	if cfg.PublicIPEnabled {
+		goBackground(func() {
+			services.StartPublicIPMonitoring(ctx, publicIP, cfg.PublicIPInterval, func(old, current services.PublicIP) {
+				notify(services.NotifyIP, services.SeverityInfo, current.IPv4+"/"+current.IPv6, func(p *i18n.Printer) string {
+					return services.FormatPublicIPChange(p, old, current)
+				})
+			})
+		})
+	}
 
-	// Gửi thông báo khởi động đến tất cả allowed users
-	if len(cfg.AllowedUsers) > 0 {
-		startupMsg := fmt.Sprintf(
-			"🟢 *Bot đã khởi động lại!*\n\n🤖 Bot: @%s\n🕐 Thời gian: `%s`\n\n_Sử dụng /help để xem danh sách lệnh._",
-			bot.Self.UserName,
-			formatTime(),
-		)
-		for _, userID := range cfg.AllowedUsers {
-			msg := tgbotapi.NewMessage(userID, startupMsg)
-			msg.ParseMode = "Markdown"
-			if _, err := bot.Send(msg); err != nil {
-				log.Printf("⚠️ Không thể gửi thông báo khởi động đến %d: %v", userID, err)
-			} else {
-				log.Printf("✅ Đã gửi thông báo khởi động đến user %d", userID)
-			}
+	// LAN device registry - dùng cho lệnh /devices và cảnh báo thiết bị lạ
+	if cfg.OUIFile != "" {
+		if err := services.LoadOUIFile(cfg.OUIFile); err != nil {
+			log.Printf("⚠️ %v", err)
 		}
 	}
+	devices := services.NewDeviceRegistry(filepath.Join(cfg.DataDir, "devices.json"), cfg.DevicesOfflineAfter)
+	if cfg.DevicesEnabled {
+		goBackground(func() {
+			services.StartDeviceMonitoring(ctx, devices, cfg.DevicesInterval, cfg.DevicesSweep, func(newDevices []services.Device) {
+				notify(services.NotifyDevices, services.SeverityWarning, services.DevicesKey(newDevices), func(p *i18n.Printer) string {
+					return services.FormatNewDevices(p, newDevices)
+				})
+			})
+		})
+	}
 
-	updates := bot.GetUpdatesChan(u)
+	// Theo dõi trạng thái bật/tắt của các PC Wake-on-LAN
+	var power *services.PowerTracker
+	if len(cfg.WOLTargets) > 0 {
+		power = services.NewPowerTracker(powerHosts(cfg), filepath.Join(cfg.DataDir, "pc_usage.json"), cfg.WOLTrackOfflineChecks)
 
-	for update := range updates {
-		if update.Message == nil {
-			continue
+		if cfg.WOLTrackEnabled {
+			goBackground(func() {
+				services.StartPowerTracking(ctx, power, cfg.WOLTrackInterval, func(event services.PowerEvent) {
+					notify(services.NotifyPower, services.SeverityInfo, fmt.Sprintf("%s/%v", event.Name, event.Online), func(p *i18n.Printer) string {
+						return services.FormatPowerEvent(p, event)
+					})
+				})
+			})
 		}
+	}
 
-		if !update.Message.IsCommand() {
-			continue
+	// Tin nhắn tiến trình bật PC (/wake, job theo lịch) cập nhật ở background, dừng hết khi bot dừng
+	wakes := handlers.NewWakeWatcher(ctx)
+
+	// Scheduler chạy các lệnh theo lịch cron (/schedule)
+	scheduler := services.NewScheduler(filepath.Join(cfg.DataDir, "schedules.json"), cfg.Location, func(job services.ScheduledJob) error {
+		cfg := store.Get()
+		p := i18n.NewPrinter(langs.Lang(job.ChatID, cfg.DefaultLang()), cfg.Location)
+		return handlers.RunScheduledJob(p, bot, cfg, job, wakes)
+	})
+	scheduler.Start()
+
+	// Menu lệnh của Telegram theo quyền từng user, đăng ký lại khi user hoặc quyền thay đổi
+	menu := handlers.NewCommandMenu(bot, store.Get)
+
+	// Reload cấu hình khi nhận SIGHUP hoặc khi file cấu hình thay đổi
+	apply := func(next *config.Config) {
+		applyRuntime(store, next, checker, power)
+		go menu.Sync()
+	}
+	reload := func(reason string) {
+		reloadConfig(store, *configPath, reason, overlay, apply, notify)
+	}
+
+	// /set, /user và menu cài đặt đổi cấu hình đang chạy (không chạy cùng lúc với reload)
+	updateConfig := func(change func(*config.Config) (*config.Config, error)) (*config.Config, error) {
+		reloadMu.Lock()
+		defer reloadMu.Unlock()
+
+		next, err := change(store.Get())
+		if err != nil {
+			return nil, err
 		}
+		apply(next)
+		return next, nil
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			reload("SIGHUP")
+		}
+	}()
+	if *configPath != "" {
+		goBackground(func() {
+			config.Watch(ctx, *configPath, configWatchInterval, func() {
+				reload(filepath.Base(*configPath))
+			})
+		})
+	}
 
-		userID := update.Message.From.ID
-		chatID := update.Message.Chat.ID
-		username := update.Message.From.UserName
+	// Thông báo khởi động theo lý do (Pi khởi động lại, bot crash, khởi động lại bình thường) đã chọn trong startup.notify
+	log.Printf("🚀 Startup: %s", startup.Kind)
+	if cfg.NotifyStartup(string(startup.Kind)) {
+		notify(services.NotifySystem, startup.Severity(), "startup/"+string(startup.Kind), func(p *i18n.Printer) string {
+			return services.FormatStartup(p, startup, bot.Self.UserName)
+		})
+	}
 
-		// Check whitelist
-		if !cfg.IsUserAllowed(userID) {
-			log.Printf("🚫 Unauthorized access attempt from user %d (@%s)", userID, username)
-			msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🚫 Bạn không có quyền sử dụng bot này.\n\n🆔 Your User ID: `%d`", userID))
-			msg.ParseMode = "Markdown"
-			bot.Send(msg)
-			continue
+	// Tin nhắn /live cập nhật liên tục, dừng hết khi bot dừng
+	live := handlers.NewLiveManager(ctx)
+
+	// Router xử lý lệnh song song bằng worker pool, middleware chạy theo thứ tự thêm vào
+	router := handlers.NewRouter(commandWorkers, commandQueueSize)
+	router.Use(
+		handlers.Recover(),
+		handlers.Logging(),
+		handlers.Locale(langs),
+		// Giới hạn tần suất trước Auth để người lạ không nhận được vô hạn tin nhắn từ chối
+		handlers.RateLimit(commandRateEvery, commandRateBurst),
+		handlers.Auth(func(req *handlers.Request) config.Role {
+			return requestRole(router, req)
+		}),
+		handlers.DetectLanguage(langs),
+		handlers.Timeout(commandTimeout, commandTimeouts),
+	)
+
+	// Danh sách lệnh: /help, menu lệnh của Telegram và kiểm tra cú pháp sinh ra từ đây
+	router.Register(handlers.Command{
+		Name: "pi",
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handlers.HandlePiCommand(req.Printer, req.Message)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name:    "live",
+		MaxArgs: 2,
+		Handler: func(ctx context.Context, req *handlers.Request) error {
+			return handlers.HandleLiveCommand(ctx, req, live)
+		},
+	})
+	router.Register(handlers.Command{
+		Name:    "wake",
+		Role:    config.RoleOperator,
+		MaxArgs: handlers.AnyArgs,
+		Handler: func(ctx context.Context, req *handlers.Request) error {
+			// /wake tự gửi và cập nhật tin nhắn tiến trình
+			handlers.HandleWakeCommand(req.Printer, req.Bot, req.Message, req.Config, wakes)
+			return nil
+		},
+	})
+	router.Register(handlers.Command{
+		Name:    "sleep",
+		Role:    config.RoleAdmin,
+		MaxArgs: handlers.AnyArgs,
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handlers.HandlePowerCommand(req.Printer, req.Message, req.Config, services.AgentSleep)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name:    "shutdown",
+		Role:    config.RoleAdmin,
+		MaxArgs: handlers.AnyArgs,
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handlers.HandlePowerCommand(req.Printer, req.Message, req.Config, services.AgentShutdown)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name:    "pcstats",
+		MaxArgs: handlers.AnyArgs,
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handlers.HandlePCStatsCommand(req.Printer, req.Message, power, req.Config.WOLTrackEnabled)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name: "ip",
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handlers.HandleIPCommand(req.Printer, req.Message, publicIP)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name:    "devices",
+		MaxArgs: handlers.AnyArgs,
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handlers.HandleDevicesCommand(req.Printer, req.Message, devices, req.Config.DevicesSweep)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name:    "schedule",
+		Role:    config.RoleOperator,
+		MaxArgs: handlers.AnyArgs,
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handlers.HandleScheduleCommand(req.Printer, req.Message, scheduler, req.Config)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name:   "id",
+		Public: true,
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return tgbotapi.NewMessage(req.ChatID(), req.Printer.T("auth.user_id", req.UserID())+"\n"+req.Printer.T("id.role", req.Role))
+		}),
+	})
+	router.Register(handlers.Command{
+		Name: "alert",
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handleAlertStatus(req.Printer, req.ChatID(), req.Config)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name:    "set",
+		Role:    config.RoleAdmin,
+		MaxArgs: handlers.AnyArgs,
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handlers.HandleSetCommand(req.Printer, req.Message, req.Config, overrides, updateConfig)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name:    "user",
+		Role:    config.RoleAdmin,
+		MaxArgs: handlers.AnyArgs,
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handlers.HandleUserCommand(req.Printer, req.Message, req.Config, users, updateConfig)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name:    "subscribe",
+		MaxArgs: handlers.AnyArgs,
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handlers.HandleSubscribeCommand(req.Printer, req.Bot, req.Message, req.ThreadID, req.Config, subs)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name:    "unsubscribe",
+		MaxArgs: 1,
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handlers.HandleUnsubscribeCommand(req.Printer, req.Message, req.ThreadID, subs)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name:    "lang",
+		MaxArgs: 1,
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return handlers.HandleLangCommand(req.Printer, req.Message, req.Config, langs)
+		}),
+	})
+	router.Register(handlers.Command{
+		Name: "help",
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return tgbotapi.NewMessage(req.ChatID(), handlers.HelpText(req.Printer, router.Commands(), req.Role))
+		}),
+	})
+	router.Register(handlers.Command{
+		Name:    "start",
+		MaxArgs: handlers.AnyArgs,
+		Hidden:  true,
+		Handler: handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+			return tgbotapi.NewMessage(req.ChatID(), req.Printer.T("start.welcome"))
+		}),
+	})
+	router.NotFound(handlers.Reply(func(req *handlers.Request) tgbotapi.MessageConfig {
+		return tgbotapi.NewMessage(req.ChatID(), req.Printer.T("error.unknown_command"))
+	}))
+	router.HandleCallback(func(ctx context.Context, req *handlers.Request) error {
+		return handleCallback(req, overrides, updateConfig, live, wakes, langs)
+	})
+	go menu.SetCommands(router.Commands())
+
+	var updates <-chan incomingUpdate
+	switch cfg.BotMode {
+	case "webhook":
+		webhook, err := startWebhook(bot, cfg)
+		if err != nil {
+			log.Fatalf("❌ Cannot start webhook: %v", err)
 		}
+		updates = webhook.Updates()
 
-		var msg tgbotapi.MessageConfig
-
-		switch update.Message.Command() {
-		case "pi":
-			msg = handlers.HandlePiCommand(update.Message)
-		case "id":
-			msg = tgbotapi.NewMessage(chatID, fmt.Sprintf("🆔 Your User ID: `%d`", userID))
-			msg.ParseMode = "Markdown"
-		case "start":
-			msg = tgbotapi.NewMessage(chatID, "👋 Xin chào! Sử dụng lệnh /pi để xem thông tin hệ thống Raspberry Pi.")
-		case "help":
-			helpText := "📖 *Danh sách lệnh:*\n\n" +
-				"/pi - Xem thông tin hệ thống (CPU, RAM, Disk, Network)\n" +
-				"/wake - Bật PC qua Wake-on-LAN\n" +
-				"/id - Xem User ID của bạn\n" +
-				"/alert - Xem trạng thái cảnh báo\n" +
-				"/help - Hiển thị trợ giúp"
-			msg = tgbotapi.NewMessage(chatID, helpText)
-			msg.ParseMode = "Markdown"
-		case "alert":
-			msg = handleAlertStatus(chatID, cfg)
-		case "wake":
-			msg = handlers.HandleWakeCommand(update.Message, cfg)
+		// Xoá webhook khi dừng bot để lần sau có thể chuyển sang polling, đóng channel update
+		go func() {
+			<-ctx.Done()
+			stopCtx, cancel := context.WithTimeout(context.Background(), store.Get().ShutdownTimeout)
+			defer cancel()
+			if err := webhook.Stop(stopCtx); err != nil {
+				log.Printf("⚠️ Webhook stopped with error: %v", err)
+			}
+		}()
+	default:
+		if err := deleteWebhook(bot); err != nil {
+			log.Printf("⚠️ Cannot delete webhook before polling: %v", err)
+		}
+		log.Printf("🔄 Receiving updates by long polling")
+		updates = pollUpdates(ctx, bot, 60)
+	}
+
+	for incoming := range updates {
+		update := incoming.Update
+		req := &handlers.Request{Bot: bot, Config: store.Get(), ThreadID: incoming.ThreadID}
+
+		switch {
+		case update.CallbackQuery != nil:
+			req.Callback = update.CallbackQuery
+		case update.Message != nil && update.Message.IsCommand() && update.Message.From != nil:
+			// Trong group, bỏ qua lệnh gửi cho bot khác (/pi@otherbot)
+			if addressedToOtherBot(update.Message, bot.Self.UserName) {
+				continue
+			}
+			req.Message = update.Message
 		default:
-			msg = tgbotapi.NewMessage(chatID, "❓ Lệnh không hợp lệ. Sử dụng /help để xem danh sách lệnh.")
+			continue
+		}
+
+		if !router.Dispatch(req) {
+			log.Printf("⚠️ Command queue full, dropping /%s from user %d", req.Name(), req.UserID())
+			p := i18n.NewPrinter(langs.Resolve(req.ChatID(), req.UserID(), req.Config.DefaultLang()), req.Config.Location)
+			if req.Callback != nil {
+				bot.Request(tgbotapi.NewCallback(req.Callback.ID, p.Text("error.busy")))
+			} else {
+				req.Reply(context.Background(), tgbotapi.NewMessage(req.ChatID(), p.T("error.busy")))
+			}
 		}
+	}
+
+	// Channel update đóng khi nhận SIGINT/SIGTERM: báo người dùng, chờ lệnh và job đang chạy,
+	// dừng các vòng lặp nền rồi lưu trạng thái
+	if cfg := store.Get(); cfg.ShutdownNotify {
+		notify(services.NotifySystem, services.SeverityInfo, "shutdown", func(p *i18n.Printer) string {
+			return formatShutdownNotice(p, bot.Self.UserName)
+		})
+	}
+	router.Close()
+	log.Printf("✅ Command handlers finished")
+	scheduler.Stop()
+	log.Printf("✅ Scheduler stopped")
+	background.Wait()
+	live.Wait()
+	wakes.Wait()
+	log.Printf("✅ Background monitors stopped")
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), outboxFlushTimeout)
+	outbox.Flush(flushCtx)
+	cancelFlush()
+	if n := outbox.Len(); n > 0 {
+		log.Printf("📤 %d notifications left in the outbox, sending them after restart", n)
+	}
 
-		if _, err := bot.Send(msg); err != nil {
-			log.Printf("Error sending message: %v", err)
+	if err := devices.Flush(); err != nil {
+		log.Printf("⚠️ Cannot save device registry: %v", err)
+	}
+	if power != nil {
+		if err := power.Flush(); err != nil {
+			log.Printf("⚠️ Cannot save PC usage log: %v", err)
 		}
 	}
+	if err := runs.MarkStopped(); err != nil {
+		log.Printf("⚠️ Cannot save run state: %v", err)
+	}
+	log.Printf("👋 Bot stopped")
 }
 
-// handleAlertStatus trả về thông tin về trạng thái alert
-func handleAlertStatus(chatID int64, cfg *config.Config) tgbotapi.MessageConfig {
-	var status string
-	if cfg.AlertEnabled && len(cfg.AllowedUsers) > 0 {
-		status = fmt.Sprintf(`🚨 *Trạng thái cảnh báo*
+// handleCallback xử lý khi người dùng bấm nút inline keyboard (đã qua middleware Auth)
+func handleCallback(req *handlers.Request, overrides *config.Overrides, updateConfig handlers.ConfigUpdater, live *handlers.LiveManager, wakes *handlers.WakeWatcher, langs *services.LanguageStore) error {
+	bot, cfg, query, p := req.Bot, req.Config, req.Callback, req.Printer
 
-✅ *Trạng thái:* Đang hoạt động
-⏱️ *Kiểm tra mỗi:* %v
-👥 *Gửi đến:* %d người dùng
+	if query.Message == nil {
+		bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		return nil
+	}
 
-📊 *Ngưỡng cảnh báo:*
-├ 🌡️ Nhiệt độ CPU: > %.0f°C
-├ 📈 Sử dụng CPU: > %.0f%%
-├ 💾 Sử dụng RAM: > %.0f%%
-└ 💿 Sử dụng Disk: > %.0f%%
+	switch {
+	case handlers.IsWakeCallback(query.Data):
+		// Trả lời ngay để Telegram tắt biểu tượng loading trên nút
+		bot.Request(tgbotapi.NewCallback(query.ID, p.Text("callback.processing")))
+		handlers.HandleWakeCallback(p, bot, query, cfg, wakes)
+	case handlers.IsPowerCallback(query.Data):
+		bot.Request(tgbotapi.NewCallback(query.ID, p.Text("callback.sending")))
+		// Bỏ nút xác nhận để không bấm lại lần nữa
+		bot.Request(tgbotapi.NewEditMessageReplyMarkup(query.Message.Chat.ID, query.Message.MessageID, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}))
+		_, err := handlers.Send(bot, handlers.HandlePowerCallback(p, query, cfg))
+		return err
+	case handlers.IsLiveCallback(query.Data):
+		bot.Request(tgbotapi.NewCallback(query.ID, handlers.HandleLiveCallback(p, bot, query, cfg, live)))
+	case handlers.IsSettingsCallback(query.Data):
+		bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		_, err := handlers.Send(bot, handlers.HandleSettingsCallback(p, query, cfg, overrides, updateConfig))
+		return err
+	case handlers.IsLangCallback(query.Data):
+		bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		_, err := handlers.Send(bot, handlers.HandleLangCallback(p, query, cfg, langs))
+		return err
+	default:
+		bot.Request(tgbotapi.NewCallback(query.ID, p.Text("common.invalid_button")))
+	}
+	return nil
+}
 
-_Bạn sẽ nhận cảnh báo khi hệ thống vượt ngưỡng_`,
+// handleAlertStatus trả về thông tin về trạng thái alert
+func handleAlertStatus(p *i18n.Printer, chatID int64, cfg *config.Config) tgbotapi.MessageConfig {
+	var status string
+	if cfg.AlertEnabled {
+		status = p.T("alert.status.enabled",
 			cfg.AlertInterval,
-			len(cfg.AllowedUsers),
+			cfg.AlertCooldown,
+			len(cfg.Users()),
 			cfg.CPUTempThreshold,
 			cfg.CPUUsageThreshold,
 			cfg.MemoryThreshold,
 			cfg.DiskThreshold,
+			cfg.WiFiSignalThreshold,
 		)
 	} else {
-		status = "🚨 *Trạng thái cảnh báo*\n\n❌ *Trạng thái:* Đã tắt\n\n_Đặt ALERT\\_ENABLED=true và ALLOWED\\_USERS để bật_"
+		status = p.T("alert.status.disabled")
 	}
 
 	msg := tgbotapi.NewMessage(chatID, status)
-	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(handlers.SettingsButton(p)))
 	return msg
 }
 
-// formatTime trả về thời gian hiện tại dạng dễ đọc theo timezone Asia/Ho_Chi_Minh
-func formatTime() string {
-	loc, err := time.LoadLocation("Asia/Ho_Chi_Minh")
+// alertThresholds tạo ngưỡng cảnh báo từ cấu hình
+func alertThresholds(cfg *config.Config) services.AlertThresholds {
+	return services.AlertThresholds{
+		CPUTemperature: cfg.CPUTempThreshold,
+		CPUUsage:       cfg.CPUUsageThreshold,
+		MemoryUsage:    cfg.MemoryThreshold,
+		DiskUsage:      cfg.DiskThreshold,
+
+		WiFiSignal:          cfg.WiFiSignalThreshold,
+		WiFiReconnects:      cfg.WiFiReconnectLimit,
+		WiFiReconnectWindow: cfg.WiFiReconnectWindow,
+	}
+}
+
+// powerHosts tạo danh sách PC cần theo dõi bật/tắt từ cấu hình
+func powerHosts(cfg *config.Config) []services.PowerHost {
+	hosts := make([]services.PowerHost, 0, len(cfg.WOLTargets))
+	for _, t := range cfg.WOLTargets {
+		hosts = append(hosts, services.PowerHost{Name: t.Name, Host: t.Host, MAC: t.MAC, Methods: t.CheckMethods, Ports: t.Ports})
+	}
+	return hosts
+}
+
+// commandRole trả về quyền tối thiểu để chạy lệnh
+func commandRole(router *handlers.Router, message *tgbotapi.Message) config.Role {
+	command, args := message.Command(), message.CommandArguments()
+
+	// Người dùng tự chọn thông báo cho chat riêng của mình; đăng ký group/channel cần admin
+	if command == "subscribe" || command == "unsubscribe" {
+		first := strings.ToLower(strings.TrimSpace(args))
+		if message.Chat.IsPrivate() && !strings.HasPrefix(first, "chat") && !strings.HasPrefix(first, "list") && (command == "subscribe" || first == "") {
+			return config.RoleViewer
+		}
+		return config.RoleAdmin
+	}
+
+	// Chọn ngôn ngữ cho chat riêng của mình; đổi ngôn ngữ của group cần admin
+	if command == "lang" && !message.Chat.IsPrivate() {
+		return config.RoleAdmin
+	}
+
+	// Đặt tên thiết bị thay đổi dữ liệu, xem danh sách thì không
+	if command == "devices" && strings.HasPrefix(strings.ToLower(strings.TrimSpace(args)), "name") {
+		return config.RoleOperator
+	}
+	if c, ok := router.Command(command); ok {
+		return c.MinRole()
+	}
+	return config.RoleViewer
+}
+
+// requestRole trả về quyền tối thiểu để chạy lệnh hoặc bấm nút
+func requestRole(router *handlers.Router, req *handlers.Request) config.Role {
+	if req.Callback != nil {
+		if handlers.IsLangCallback(req.Callback.Data) && req.Callback.Message != nil && !req.Callback.Message.Chat.IsPrivate() {
+			return config.RoleAdmin
+		}
+		return callbackRole(req.Callback.Data)
+	}
+	return commandRole(router, req.Message)
+}
+
+// callbackRole trả về quyền tối thiểu khi bấm nút inline keyboard
+func callbackRole(data string) config.Role {
+	switch {
+	case handlers.IsWakeCallback(data):
+		return config.RoleOperator
+	case handlers.IsPowerCallback(data), handlers.IsSettingsCallback(data):
+		return config.RoleAdmin
+	}
+	return config.RoleViewer
+}
+
+// reloadMu đảm bảo chỉ một lần reload chạy tại một thời điểm
+var reloadMu sync.Mutex
+
+// reloadConfig đọc lại cấu hình, áp dụng các thay đổi có thể đổi khi đang chạy
+// (ngưỡng cảnh báo, allowed users, PC Wake-on-LAN) và báo kết quả cho người dùng.
+// Giá trị đã đổi bằng /set, /user (overlay) vẫn được giữ.
+func reloadConfig(store *config.Holder, path, reason string, overlay func(*config.Config) *config.Config, apply func(*config.Config), notify func(services.NotifyType, services.Severity, string, func(*i18n.Printer) string)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	current := store.Get()
+	next, err := config.Load(path)
 	if err != nil {
-		loc = time.UTC
+		log.Printf("❌ Config reload (%s) rejected: %v", reason, err)
+		notify(services.NotifyConfig, services.SeverityWarning, "rejected/"+err.Error(), func(p *i18n.Printer) string {
+			return p.T("config.rejected", reason, p.Err(err))
+		})
+		return
+	}
+	next = overlay(next)
+
+	changes := config.Diff(current, next)
+	if len(changes) == 0 {
+		log.Printf("🔄 Config reloaded (%s): no changes", reason)
+		return
+	}
+
+	applied := config.ApplyLive(current, next)
+	apply(applied)
+
+	log.Printf("🔄 Config reloaded (%s): %d change(s)", reason, len(changes))
+	notify(services.NotifyConfig, services.SeverityInfo, "", func(p *i18n.Printer) string {
+		return formatConfigChanges(p, reason, changes)
+	})
+}
+
+// applyRuntime thay cấu hình đang chạy và cập nhật các service dùng giá trị có thể đổi khi đang chạy
+func applyRuntime(store *config.Holder, cfg *config.Config, checker *services.AlertChecker, power *services.PowerTracker) {
+	store.Set(cfg)
+	if checker != nil {
+		checker.SetThresholds(alertThresholds(cfg))
+		checker.SetCooldown(cfg.AlertCooldown)
+		checker.SetInterval(cfg.AlertInterval)
+	}
+	if power != nil {
+		power.SetHosts(powerHosts(cfg))
+	}
+}
+
+// formatConfigChanges format danh sách thay đổi cấu hình
+func formatConfigChanges(p *i18n.Printer, reason string, changes []config.Change) string {
+	var live, restart strings.Builder
+	for _, c := range changes {
+		line := fmt.Sprintf("• <code>%s</code>: <code>%s</code> → <code>%s</code>\n", i18n.Escape(c.Key), i18n.Escape(c.Old), i18n.Escape(c.New))
+		if c.Restart {
+			restart.WriteString(line)
+		} else {
+			live.WriteString(line)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(p.T("config.reloaded", reason))
+	if live.Len() > 0 {
+		sb.WriteString(p.T("config.applied") + live.String())
+	}
+	if restart.Len() > 0 {
+		sb.WriteString(p.T("config.restart_required") + restart.String())
+	}
+	return sb.String()
+}
+
+// notifySubscribers đưa thông báo (HTML) vào hàng đợi gửi đến người dùng có quyền và các group/channel/topic đã đăng ký,
+// nội dung theo ngôn ngữ của từng chat (mỗi ngôn ngữ chỉ tạo một lần)
+func notifySubscribers(outbox *services.Outbox, cfg *config.Config, subs *services.SubscriptionStore, langs *services.LanguageStore, t services.NotifyType, sev services.Severity, key string, text func(*i18n.Printer) string) {
+	texts := make(map[i18n.Lang]string)
+	for _, dest := range subs.Recipients(cfg.Users(), t, sev) {
+		lang := langs.Lang(dest.ChatID, cfg.DefaultLang())
+		if _, ok := texts[lang]; !ok {
+			texts[lang] = text(i18n.NewPrinter(lang, cfg.Location))
+		}
+		queued := outbox.Enqueue(services.OutboxMessage{
+			ChatID:   dest.ChatID,
+			ThreadID: dest.ThreadID,
+			Type:     t,
+			Severity: sev,
+			Lang:     lang,
+			Text:     texts[lang],
+			Key:      key,
+		})
+		if !queued {
+			log.Printf("🔁 Skipping duplicate %s notification to chat %d", t, dest.ChatID)
+		}
+	}
+}
+
+// runConfigCommand xử lý subcommand `pi-monitor config check`, trả về exit code
+func runConfigCommand(args []string) int {
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	configPath := fs.String("config", os.Getenv("CONFIG_FILE"), "File cấu hình YAML")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: pi-monitor config check [--config <file>]")
+		fs.PrintDefaults()
+	}
+
+	if len(args) == 0 || args[0] != "check" {
+		fs.Usage()
+		return 2
+	}
+	fs.Parse(args[1:])
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		return 1
+	}
+
+	source := "biến môi trường"
+	if *configPath != "" {
+		source = *configPath + " + biến môi trường"
+	}
+	fmt.Printf("✅ Cấu hình hợp lệ (%s)\n", source)
+	fmt.Printf("   Users: %d, PC: %d, timezone: %s\n", len(cfg.Users()), len(cfg.WOLTargets), cfg.Location)
+	if len(cfg.Users()) == 0 {
+		fmt.Println("⚠️  Chưa cấu hình user nào (users.admins / ADMIN_USERS): bot sẽ từ chối mọi lệnh")
 	}
-	return time.Now().In(loc).Format("02/01/2006 15:04:05")
+	return 0
 }
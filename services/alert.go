@@ -1,10 +1,15 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+
+	"pi-monitor/i18n"
 )
 
 // AlertThresholds định nghĩa các ngưỡng cảnh báo
@@ -13,6 +18,10 @@ type AlertThresholds struct {
 	CPUUsage       float64 // % sử dụng CPU
 	MemoryUsage    float64 // % sử dụng RAM
 	DiskUsage      float64 // % sử dụng Disk
+
+	WiFiSignal          float64       // Tín hiệu Wi-Fi tối thiểu (dBm), 0 = tắt
+	WiFiReconnects      int           // Số lần kết nối lại Wi-Fi tối đa trong WiFiReconnectWindow, 0 = tắt
+	WiFiReconnectWindow time.Duration // Cửa sổ đếm số lần kết nối lại
 }
 
 // DefaultThresholds trả về các ngưỡng mặc định
@@ -22,6 +31,10 @@ func DefaultThresholds() AlertThresholds {
 		CPUUsage:       90.0, // Cảnh báo khi > 90%
 		MemoryUsage:    85.0, // Cảnh báo khi > 85%
 		DiskUsage:      90.0, // Cảnh báo khi > 90%
+
+		WiFiSignal:          -75.0,            // Cảnh báo khi < -75 dBm
+		WiFiReconnects:      3,                // Cảnh báo khi kết nối lại >= 3 lần
+		WiFiReconnectWindow: 10 * time.Minute, // trong 10 phút
 	}
 }
 
@@ -33,22 +46,99 @@ const (
 	AlertCPUUsage AlertType = "CPU_USAGE"
 	AlertMemory   AlertType = "MEMORY_USAGE"
 	AlertDisk     AlertType = "DISK_USAGE"
+
+	AlertWiFiSignal    AlertType = "WIFI_SIGNAL"
+	AlertWiFiReconnect AlertType = "WIFI_RECONNECT"
 )
 
-// Alert chứa thông tin cảnh báo
+// Alert chứa thông tin cảnh báo, nội dung tin nhắn tạo bằng Format theo ngôn ngữ người nhận
 type Alert struct {
 	Type      AlertType
 	Value     float64
 	Threshold float64
-	Message   string
 	Timestamp time.Time
+
+	Used, Total uint64        // RAM/Disk đã dùng và tổng dung lượng (byte)
+	Interface   string        // Interface Wi-Fi
+	SSID        string        // Mạng Wi-Fi đang kết nối
+	Window      time.Duration // Cửa sổ đếm số lần kết nối lại Wi-Fi
+}
+
+// Format tạo nội dung cảnh báo theo ngôn ngữ của p
+func (a Alert) Format(p *i18n.Printer) string {
+	switch a.Type {
+	case AlertCPUTemp:
+		return p.T("alert.cpu_temp", p.Number(a.Value, 1), p.Number(a.Threshold, 1))
+	case AlertCPUUsage:
+		return p.T("alert.cpu_usage", p.Percent(a.Value, 1), p.Percent(a.Threshold, 1))
+	case AlertMemory:
+		return p.T("alert.memory", p.Percent(a.Value, 1), p.Bytes(a.Used), p.Bytes(a.Total), p.Percent(a.Threshold, 1))
+	case AlertDisk:
+		return p.T("alert.disk", p.Percent(a.Value, 1), p.Bytes(a.Used), p.Bytes(a.Total), p.Percent(a.Threshold, 1))
+	case AlertWiFiReconnect:
+		return p.T("alert.wifi_reconnect", a.Interface, p.Plural(int64(a.Value), "unit.time"), p.Duration(a.Window), p.Plural(int64(a.Threshold), "unit.time"))
+	case AlertWiFiSignal:
+		return p.T("alert.wifi_signal", a.SSID, p.Number(a.Value, 0), p.Number(a.Threshold, 0))
+	}
+	return string(a.Type)
+}
+
+// Severity trả về mức độ của cảnh báo: critical khi nhiệt độ vượt ngưỡng từ 10°C
+// hoặc CPU/RAM/Disk từ 98% trở lên, còn lại là warning
+func (a Alert) Severity() Severity {
+	switch a.Type {
+	case AlertCPUTemp:
+		if a.Value >= a.Threshold+10 {
+			return SeverityCritical
+		}
+	case AlertCPUUsage, AlertMemory, AlertDisk:
+		if a.Value >= 98 {
+			return SeverityCritical
+		}
+	}
+	return SeverityWarning
+}
+
+// AlertsSeverity trả về mức độ cao nhất của các cảnh báo
+func AlertsSeverity(alerts []Alert) Severity {
+	sev := SeverityWarning
+	for _, a := range alerts {
+		if a.Severity() == SeverityCritical {
+			sev = SeverityCritical
+		}
+	}
+	return sev
+}
+
+// WeakWiFiSignal kiểm tra tín hiệu Wi-Fi (dBm) dưới ngưỡng. Ngưỡng 0 là tắt cảnh báo, tín hiệu 0 là không đọc được.
+func WeakWiFiSignal(signalDBm, threshold float64) bool {
+	return threshold != 0 && signalDBm != 0 && signalDBm < threshold
+}
+
+// AlertsKey định danh nhóm cảnh báo theo loại (không gồm giá trị và thời gian) để bỏ thông báo trùng
+func AlertsKey(alerts []Alert) string {
+	types := make([]string, len(alerts))
+	for i, a := range alerts {
+		types[i] = string(a.Type)
+	}
+	sort.Strings(types)
+	return strings.Join(types, ",")
 }
 
 // AlertChecker kiểm tra và phát hiện bất thường
 type AlertChecker struct {
+	mu sync.Mutex // Bảo vệ Thresholds khi đổi cấu hình lúc đang chạy
+
 	Thresholds     AlertThresholds
 	lastAlerts     map[AlertType]time.Time // Tracking để tránh spam
 	cooldownPeriod time.Duration           // Thời gian chờ giữa các alert cùng loại
+	interval       time.Duration           // Khoảng thời gian kiểm tra, đổi được khi đang chạy
+
+	// Theo dõi kết nối lại Wi-Fi
+	wifiConnected  bool
+	wifiBSSID      string
+	wifiSeen       bool
+	wifiReconnects []time.Time
 }
 
 // NewAlertChecker tạo AlertChecker mới
@@ -60,6 +150,41 @@ func NewAlertChecker(thresholds AlertThresholds) *AlertChecker {
 	}
 }
 
+// SetThresholds đổi ngưỡng cảnh báo khi đang chạy (reload cấu hình)
+func (ac *AlertChecker) SetThresholds(thresholds AlertThresholds) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.Thresholds = thresholds
+}
+
+// SetCooldown đổi thời gian chờ trước khi cảnh báo lại cùng loại
+func (ac *AlertChecker) SetCooldown(cooldown time.Duration) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.cooldownPeriod = cooldown
+}
+
+// SetInterval đổi khoảng thời gian kiểm tra, áp dụng từ lần kiểm tra tiếp theo
+func (ac *AlertChecker) SetInterval(interval time.Duration) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.interval = interval
+}
+
+// currentInterval trả về khoảng thời gian kiểm tra đã đặt bằng SetInterval (0 nếu chưa đặt)
+func (ac *AlertChecker) currentInterval() time.Duration {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	return ac.interval
+}
+
+// CurrentThresholds trả về ngưỡng cảnh báo đang dùng
+func (ac *AlertChecker) CurrentThresholds() AlertThresholds {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	return ac.Thresholds
+}
+
 // CheckSystem kiểm tra hệ thống và trả về các cảnh báo
 func (ac *AlertChecker) CheckSystem() ([]Alert, error) {
 	info, err := GetSystemInfo()
@@ -67,6 +192,9 @@ func (ac *AlertChecker) CheckSystem() ([]Alert, error) {
 		return nil, fmt.Errorf("failed to get system info: %v", err)
 	}
 
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
 	var alerts []Alert
 	now := time.Now()
 
@@ -77,7 +205,6 @@ func (ac *AlertChecker) CheckSystem() ([]Alert, error) {
 				Type:      AlertCPUTemp,
 				Value:     info.CPU.Temperature,
 				Threshold: ac.Thresholds.CPUTemperature,
-				Message:   fmt.Sprintf("🌡️ *Nhiệt độ CPU quá cao!*\n├ Hiện tại: *%.1f°C*\n└ Ngưỡng: %.1f°C", info.CPU.Temperature, ac.Thresholds.CPUTemperature),
 				Timestamp: now,
 			})
 			ac.lastAlerts[AlertCPUTemp] = now
@@ -91,7 +218,6 @@ func (ac *AlertChecker) CheckSystem() ([]Alert, error) {
 				Type:      AlertCPUUsage,
 				Value:     info.CPU.UsagePercent,
 				Threshold: ac.Thresholds.CPUUsage,
-				Message:   fmt.Sprintf("📈 *CPU đang quá tải!*\n├ Hiện tại: *%.1f%%*\n└ Ngưỡng: %.1f%%", info.CPU.UsagePercent, ac.Thresholds.CPUUsage),
 				Timestamp: now,
 			})
 			ac.lastAlerts[AlertCPUUsage] = now
@@ -105,8 +231,9 @@ func (ac *AlertChecker) CheckSystem() ([]Alert, error) {
 				Type:      AlertMemory,
 				Value:     info.Memory.UsedPercent,
 				Threshold: ac.Thresholds.MemoryUsage,
-				Message:   fmt.Sprintf("💾 *RAM sắp hết!*\n├ Đã dùng: *%.1f%%* (%s/%s)\n└ Ngưỡng: %.1f%%", info.Memory.UsedPercent, info.Memory.Used, info.Memory.Total, ac.Thresholds.MemoryUsage),
 				Timestamp: now,
+				Used:      info.Memory.Used,
+				Total:     info.Memory.Total,
 			})
 			ac.lastAlerts[AlertMemory] = now
 		}
@@ -119,16 +246,75 @@ func (ac *AlertChecker) CheckSystem() ([]Alert, error) {
 				Type:      AlertDisk,
 				Value:     info.Disk.UsedPercent,
 				Threshold: ac.Thresholds.DiskUsage,
-				Message:   fmt.Sprintf("💿 *Ổ đĩa sắp đầy!*\n├ Đã dùng: *%.1f%%* (%s/%s)\n└ Ngưỡng: %.1f%%", info.Disk.UsedPercent, info.Disk.Used, info.Disk.Total, ac.Thresholds.DiskUsage),
 				Timestamp: now,
+				Used:      info.Disk.Used,
+				Total:     info.Disk.Total,
 			})
 			ac.lastAlerts[AlertDisk] = now
 		}
 	}
 
+	// Check Wi-Fi
+	if info.WiFi != nil {
+		alerts = append(alerts, ac.checkWiFi(info.WiFi, now)...)
+	}
+
 	return alerts, nil
 }
 
+// checkWiFi kiểm tra tín hiệu Wi-Fi yếu và kết nối lại liên tục (reconnect storm)
+func (ac *AlertChecker) checkWiFi(wifi *WiFiInfo, now time.Time) []Alert {
+	var alerts []Alert
+
+	// Đếm số lần kết nối lại: mất kết nối -> có lại, hoặc đổi access point
+	if ac.wifiSeen && wifi.Connected && (!ac.wifiConnected || (wifi.BSSID != "" && wifi.BSSID != ac.wifiBSSID)) {
+		ac.wifiReconnects = append(ac.wifiReconnects, now)
+	}
+	ac.wifiSeen = true
+	ac.wifiConnected = wifi.Connected
+	if wifi.Connected {
+		ac.wifiBSSID = wifi.BSSID
+	}
+
+	// Bỏ các lần kết nối lại nằm ngoài cửa sổ thời gian
+	recent := ac.wifiReconnects[:0]
+	for _, t := range ac.wifiReconnects {
+		if now.Sub(t) <= ac.Thresholds.WiFiReconnectWindow {
+			recent = append(recent, t)
+		}
+	}
+	ac.wifiReconnects = recent
+
+	if ac.Thresholds.WiFiReconnects > 0 && len(ac.wifiReconnects) >= ac.Thresholds.WiFiReconnects {
+		if ac.canAlert(AlertWiFiReconnect, now) {
+			alerts = append(alerts, Alert{
+				Type:      AlertWiFiReconnect,
+				Value:     float64(len(ac.wifiReconnects)),
+				Threshold: float64(ac.Thresholds.WiFiReconnects),
+				Timestamp: now,
+				Interface: wifi.Interface,
+				Window:    ac.Thresholds.WiFiReconnectWindow,
+			})
+			ac.lastAlerts[AlertWiFiReconnect] = now
+		}
+	}
+
+	if wifi.Connected && WeakWiFiSignal(wifi.SignalDBm, ac.Thresholds.WiFiSignal) {
+		if ac.canAlert(AlertWiFiSignal, now) {
+			alerts = append(alerts, Alert{
+				Type:      AlertWiFiSignal,
+				Value:     wifi.SignalDBm,
+				Threshold: ac.Thresholds.WiFiSignal,
+				Timestamp: now,
+				SSID:      wifi.SSID,
+			})
+			ac.lastAlerts[AlertWiFiSignal] = now
+		}
+	}
+
+	return alerts
+}
+
 // canAlert kiểm tra xem có thể gửi alert không (cooldown)
 func (ac *AlertChecker) canAlert(alertType AlertType, now time.Time) bool {
 	lastTime, exists := ac.lastAlerts[alertType]
@@ -139,47 +325,67 @@ func (ac *AlertChecker) canAlert(alertType AlertType, now time.Time) bool {
 }
 
 // FormatAlerts format danh sách cảnh báo thành message
-func FormatAlerts(alerts []Alert) string {
+func FormatAlerts(p *i18n.Printer, alerts []Alert) string {
 	if len(alerts) == 0 {
 		return ""
 	}
 
 	var sb strings.Builder
-	sb.WriteString("🚨 *CẢNH BÁO HỆ THỐNG RASPBERRY PI*\n\n")
+	sb.WriteString(p.T("alert.title") + "\n\n")
 
 	for i, alert := range alerts {
-		sb.WriteString(alert.Message)
+		sb.WriteString(alert.Format(p))
 		if i < len(alerts)-1 {
 			sb.WriteString("\n\n")
 		}
 	}
 
-	sb.WriteString(fmt.Sprintf("\n\n⏰ _Thời gian: %s_", alerts[0].Timestamp.Format("02/01/2006 15:04:05")))
+	sb.WriteString("\n\n" + p.T("common.time_footer", p.DateTime(alerts[0].Timestamp)))
 
 	return sb.String()
 }
 
-// StartMonitoring bắt đầu monitoring và gọi callback khi có alert
-func StartMonitoring(checker *AlertChecker, interval time.Duration, onAlert func([]Alert)) {
+// StartMonitoring bắt đầu monitoring và gọi callback khi có alert, dừng khi ctx bị huỷ.
+// onCheck (nếu có) nhận kết quả mỗi lần kiểm tra (nil = thành công), dùng cho heartbeat.
+// onAlert nil = chỉ kiểm tra, không gửi alert (cảnh báo tắt nhưng vẫn cần báo lỗi cho heartbeat).
+func StartMonitoring(ctx context.Context, checker *AlertChecker, interval time.Duration, onAlert func([]Alert), onCheck func(error)) {
 	log.Printf("🔍 Alert monitoring started (interval: %v)", interval)
+	thresholds := checker.CurrentThresholds()
 	log.Printf("📊 Thresholds: CPU Temp > %.0f°C, CPU > %.0f%%, RAM > %.0f%%, Disk > %.0f%%",
-		checker.Thresholds.CPUTemperature,
-		checker.Thresholds.CPUUsage,
-		checker.Thresholds.MemoryUsage,
-		checker.Thresholds.DiskUsage,
+		thresholds.CPUTemperature,
+		thresholds.CPUUsage,
+		thresholds.MemoryUsage,
+		thresholds.DiskUsage,
 	)
 
+	checker.SetInterval(interval)
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("🔍 Alert monitoring stopped")
+			return
+		case <-ticker.C:
+		}
+
+		if next := checker.currentInterval(); next != interval {
+			interval = next
+			ticker.Reset(interval)
+			log.Printf("🔍 Alert interval changed to %v", interval)
+		}
+
 		alerts, err := checker.CheckSystem()
+		if onCheck != nil {
+			onCheck(err)
+		}
 		if err != nil {
 			log.Printf("Error checking system: %v", err)
 			continue
 		}
 
-		if len(alerts) > 0 {
+		if len(alerts) > 0 && onAlert != nil {
 			log.Printf("⚠️ Found %d alert(s)", len(alerts))
 			onAlert(alerts)
 		}
@@ -0,0 +1,563 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pi-monitor/i18n"
+)
+
+// maxDeviceHistory là số sự kiện online/offline tối đa lưu cho mỗi thiết bị
+const maxDeviceHistory = 50
+
+//go:embed data/oui.txt
+var embeddedOUI []byte
+
+var (
+	ouiMu    sync.RWMutex
+	ouiTable = parseOUITable(embeddedOUI)
+)
+
+// ARPEntry là một dòng trong bảng ARP/neighbor của kernel
+type ARPEntry struct {
+	IP        string
+	MAC       string
+	Interface string
+}
+
+// DeviceEvent ghi lại thời điểm thiết bị online/offline
+type DeviceEvent struct {
+	Time   time.Time `json:"time"`
+	Online bool      `json:"online"`
+}
+
+// legacyVendorRandomMAC là vendor mà file thiết bị của bản cũ lưu cho MAC ngẫu nhiên, được chuyển sang Device.RandomMAC khi đọc
+const legacyVendorRandomMAC = "MAC ngẫu nhiên"
+
+// Device là một thiết bị trong mạng LAN
+type Device struct {
+	MAC       string        `json:"mac"`
+	IP        string        `json:"ip"`
+	Interface string        `json:"interface,omitempty"`
+	Vendor    string        `json:"vendor,omitempty"`
+	RandomMAC bool          `json:"random_mac,omitempty"` // MAC ngẫu nhiên (bit locally administered), không tra được vendor
+	Name      string        `json:"name,omitempty"`       // Tên do người dùng đặt
+	FirstSeen time.Time     `json:"first_seen"`
+	LastSeen  time.Time     `json:"last_seen"`
+	Online    bool          `json:"online"`
+	History   []DeviceEvent `json:"history,omitempty"`
+}
+
+// DisplayName trả về tên đã đặt, hoặc vendor, hoặc MAC
+func (d Device) DisplayName(p *i18n.Printer) string {
+	if d.Name != "" {
+		return d.Name
+	}
+	if vendor := d.VendorName(p); vendor != "" {
+		return vendor
+	}
+	return d.MAC
+}
+
+// VendorName trả về tên hiển thị của vendor theo ngôn ngữ của Printer
+func (d Device) VendorName(p *i18n.Printer) string {
+	if d.Vendor == "" && d.RandomMAC {
+		return p.Text("devices.vendor.random")
+	}
+	return d.Vendor
+}
+
+// registryFile là nội dung file registry
+type registryFile struct {
+	Baselined bool      `json:"baselined"` // Đã quét lần đầu, thiết bị thấy sau đó là thiết bị mới
+	Devices   []*Device `json:"devices"`
+}
+
+// DeviceRegistry lưu danh sách thiết bị đã thấy trong mạng LAN xuống file
+type DeviceRegistry struct {
+	OfflineAfter time.Duration // Không thấy trong bảng ARP quá thời gian này -> offline
+
+	mu        sync.Mutex
+	path      string
+	devices   map[string]*Device
+	baselined bool
+}
+
+// NewDeviceRegistry tạo registry mới và đọc danh sách thiết bị đã lưu (nếu có)
+func NewDeviceRegistry(path string, offlineAfter time.Duration) *DeviceRegistry {
+	r := &DeviceRegistry{
+		OfflineAfter: offlineAfter,
+		path:         path,
+		devices:      make(map[string]*Device),
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		file, err := parseRegistryFile(data)
+		if err != nil {
+			log.Printf("⚠️ Cannot parse device registry %s: %v", path, err)
+		}
+		r.baselined = file.Baselined
+		for _, d := range file.Devices {
+			if d.Vendor == legacyVendorRandomMAC {
+				d.Vendor, d.RandomMAC = "", true
+			}
+			r.devices[d.MAC] = d
+		}
+	}
+
+	return r
+}
+
+// parseRegistryFile đọc file registry; file của bản cũ là mảng thiết bị,
+// đã quét lần đầu nếu có thiết bị từng thấy trong bảng ARP (không chỉ được đặt tên trước)
+func parseRegistryFile(data []byte) (registryFile, error) {
+	var file registryFile
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &file.Devices); err != nil {
+			return registryFile{}, err
+		}
+		for _, d := range file.Devices {
+			if !d.LastSeen.IsZero() {
+				file.Baselined = true
+			}
+		}
+		return file, nil
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return registryFile{}, err
+	}
+	return file, nil
+}
+
+// Scan đọc bảng ARP (tuỳ chọn quét chủ động subnet trước) và cập nhật registry.
+// Trả về các thiết bị chưa từng thấy trước đây.
+func (r *DeviceRegistry) Scan(sweep bool) ([]Device, error) {
+	if sweep {
+		SweepLocalSubnets()
+	}
+
+	entries, err := ReadARPTable()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Update(entries, time.Now()), nil
+}
+
+// Update cập nhật registry từ bảng ARP và trả về các thiết bị mới.
+// Lần cập nhật đầu tiên chỉ ghi nhận, không coi là thiết bị mới (kể cả khi đã đặt tên trước cho vài thiết bị).
+func (r *DeviceRegistry) Update(entries []ARPEntry, now time.Time) []Device {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	baseline := !r.baselined
+	r.baselined = true
+	var added []*Device
+	seen := make(map[string]bool)
+
+	for _, e := range entries {
+		seen[e.MAC] = true
+
+		d, exists := r.devices[e.MAC]
+		if !exists {
+			d = &Device{
+				MAC:       e.MAC,
+				Vendor:    LookupVendor(e.MAC),
+				RandomMAC: IsRandomMAC(e.MAC),
+				FirstSeen: now,
+			}
+			r.devices[e.MAC] = d
+			if !baseline {
+				added = append(added, d)
+			}
+		}
+
+		if d.FirstSeen.IsZero() {
+			d.FirstSeen = now
+		}
+		d.IP = e.IP
+		d.Interface = e.Interface
+		d.LastSeen = now
+		if !d.Online {
+			d.Online = true
+			d.addEvent(now, true)
+		}
+	}
+
+	// Thiết bị không còn trong bảng ARP quá lâu -> offline
+	for mac, d := range r.devices {
+		if !seen[mac] && d.Online && now.Sub(d.LastSeen) >= r.OfflineAfter {
+			d.Online = false
+			d.addEvent(now, false)
+		}
+	}
+
+	if err := r.save(); err != nil {
+		log.Printf("⚠️ Cannot save device registry: %v", err)
+	}
+
+	newDevices := make([]Device, 0, len(added))
+	for _, d := range added {
+		newDevices = append(newDevices, *d)
+	}
+	return newDevices
+}
+
+// addEvent thêm sự kiện online/offline, giữ tối đa maxDeviceHistory sự kiện
+func (d *Device) addEvent(t time.Time, online bool) {
+	d.History = append(d.History, DeviceEvent{Time: t, Online: online})
+	if len(d.History) > maxDeviceHistory {
+		d.History = d.History[len(d.History)-maxDeviceHistory:]
+	}
+}
+
+// List trả về danh sách thiết bị, online trước, sắp xếp theo IP
+func (r *DeviceRegistry) List() []Device {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	devices := make([]Device, 0, len(r.devices))
+	for _, d := range r.devices {
+		devices = append(devices, *d)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		if devices[i].Online != devices[j].Online {
+			return devices[i].Online
+		}
+		return ipLess(devices[i].IP, devices[j].IP)
+	})
+	return devices
+}
+
+// Find tìm thiết bị theo MAC, IP hoặc tên đã đặt
+func (r *DeviceRegistry) Find(query string) (Device, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if mac, err := NormalizeMAC(query); err == nil {
+		if d, ok := r.devices[mac]; ok {
+			return *d, true
+		}
+	}
+	for _, d := range r.devices {
+		if d.IP == query || strings.EqualFold(d.Name, query) {
+			return *d, true
+		}
+	}
+	return Device{}, false
+}
+
+// SetName đặt tên cho thiết bị (tên rỗng để xoá)
+func (r *DeviceRegistry) SetName(mac, name string) error {
+	normalized, err := NormalizeMAC(mac)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.devices[normalized]
+	if !ok {
+		// Cho phép đặt tên trước cho thiết bị chưa từng thấy
+		d = &Device{MAC: normalized, Vendor: LookupVendor(normalized), RandomMAC: IsRandomMAC(normalized)}
+		r.devices[normalized] = d
+	}
+	d.Name = strings.TrimSpace(name)
+
+	return r.save()
+}
+
+// Flush ghi registry xuống file, gọi khi dừng bot
+func (r *DeviceRegistry) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.save()
+}
+
+// save ghi registry xuống file (gọi khi đang giữ lock)
+func (r *DeviceRegistry) save() error {
+	if r.path == "" {
+		return nil
+	}
+
+	devices := make([]*Device, 0, len(r.devices))
+	for _, d := range r.devices {
+		devices = append(devices, d)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].MAC < devices[j].MAC })
+
+	data, err := json.MarshalIndent(registryFile{Baselined: r.baselined, Devices: devices}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, data, 0o644)
+}
+
+// ReadARPTable đọc bảng ARP của kernel từ /proc/net/arp
+func ReadARPTable() ([]ARPEntry, error) {
+	paths := []string{
+		"/proc/net/arp",
+		"/host/proc/1/net/arp", // Network namespace của host khi chạy trong Docker
+	}
+
+	var lastErr error
+	var entries []ARPEntry
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		entries = append(entries, parseARPTable(data)...)
+	}
+
+	if entries == nil && lastErr != nil {
+		return nil, i18n.Errorf("devices.err.arp", lastErr)
+	}
+	return dedupeARPEntries(entries), nil
+}
+
+// parseARPTable parse nội dung /proc/net/arp, vd:
+//
+//	IP address       HW type     Flags       HW address            Mask     Device
+//	192.168.1.10     0x1         0x2         aa:bb:cc:dd:ee:ff     *        eth0
+func parseARPTable(data []byte) []ARPEntry {
+	var entries []ARPEntry
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Scan() // Bỏ dòng header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+
+		// Flags 0x0 = incomplete (chưa resolve được MAC)
+		if fields[2] == "0x0" {
+			continue
+		}
+
+		mac, err := NormalizeMAC(fields[3])
+		if err != nil || mac == "00:00:00:00:00:00" {
+			continue
+		}
+
+		entries = append(entries, ARPEntry{IP: fields[0], MAC: mac, Interface: fields[5]})
+	}
+	return entries
+}
+
+// dedupeARPEntries bỏ các dòng trùng MAC (khi đọc cả bảng ARP của container và host)
+func dedupeARPEntries(entries []ARPEntry) []ARPEntry {
+	seen := make(map[string]bool)
+	result := entries[:0]
+	for _, e := range entries {
+		if seen[e.MAC] {
+			continue
+		}
+		seen[e.MAC] = true
+		result = append(result, e)
+	}
+	return result
+}
+
+// SweepLocalSubnets gửi một gói UDP nhỏ đến mọi địa chỉ trong các subnet IPv4 cục bộ
+// để kernel thực hiện ARP resolve, sau đó chờ bảng ARP được cập nhật.
+// Chỉ quét subnet có tối đa 1024 địa chỉ (/22 trở lên).
+func SweepLocalSubnets() {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		log.Printf("⚠️ Cannot list interface addresses: %v", err)
+		return
+	}
+
+	sent := 0
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || ipnet.IP.IsLoopback() || ipnet.IP.To4() == nil {
+			continue
+		}
+
+		ones, bits := ipnet.Mask.Size()
+		if bits != 32 || bits-ones > 10 || bits-ones < 2 {
+			continue
+		}
+
+		network := binary.BigEndian.Uint32(ipnet.IP.To4().Mask(ipnet.Mask))
+		self := binary.BigEndian.Uint32(ipnet.IP.To4())
+		size := uint32(1) << uint(bits-ones)
+
+		// Bỏ địa chỉ network và broadcast
+		for i := uint32(1); i < size-1; i++ {
+			host := network + i
+			if host == self {
+				continue
+			}
+
+			ip := make(net.IP, 4)
+			binary.BigEndian.PutUint32(ip, host)
+
+			conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: ip, Port: 9})
+			if err != nil {
+				continue
+			}
+			conn.Write([]byte{0})
+			conn.Close()
+			sent++
+		}
+	}
+
+	if sent > 0 {
+		// Chờ các ARP reply về
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// LookupVendor tra nhà sản xuất từ 3 byte đầu của MAC (rỗng nếu không biết, xem thêm IsRandomMAC)
+func LookupVendor(mac string) string {
+	macBytes, err := ParseMAC(mac)
+	if err != nil {
+		return ""
+	}
+
+	ouiMu.RLock()
+	vendor, ok := ouiTable[fmt.Sprintf("%02X%02X%02X", macBytes[0], macBytes[1], macBytes[2])]
+	ouiMu.RUnlock()
+	if ok {
+		return vendor
+	}
+
+	// MAC ảo của Docker có bit "locally administered" nhưng có prefix cố định
+	if macBytes[0] == 0x02 && macBytes[1] == 0x42 {
+		return "Docker"
+	}
+	return ""
+}
+
+// IsRandomMAC kiểm tra MAC có bit "locally administered": MAC ngẫu nhiên (điện thoại) hoặc ảo (VM)
+func IsRandomMAC(mac string) bool {
+	macBytes, err := ParseMAC(mac)
+	if err != nil {
+		return false
+	}
+	return macBytes[0]&0x02 != 0
+}
+
+// LoadOUIFile nạp thêm bảng OUI từ file (mỗi dòng: "AABBCC Vendor" hoặc "AA:BB:CC Vendor")
+func LoadOUIFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return i18n.Errorf("devices.err.oui", err)
+	}
+
+	table := parseOUITable(data)
+
+	ouiMu.Lock()
+	for prefix, vendor := range table {
+		ouiTable[prefix] = vendor
+	}
+	ouiMu.Unlock()
+
+	log.Printf("📇 Loaded %d OUI entries from %s", len(table), path)
+	return nil
+}
+
+// parseOUITable parse bảng OUI, bỏ qua dòng trống và comment (#)
+func parseOUITable(data []byte) map[string]string {
+	table := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		prefix := strings.ToUpper(strings.NewReplacer(":", "", "-", "").Replace(fields[0]))
+		if len(prefix) != 6 {
+			continue
+		}
+		table[prefix] = strings.Join(fields[1:], " ")
+	}
+	return table
+}
+
+// ipLess so sánh hai địa chỉ IPv4 theo thứ tự số
+func ipLess(a, b string) bool {
+	ipA, ipB := net.ParseIP(a).To4(), net.ParseIP(b).To4()
+	if ipA == nil || ipB == nil {
+		return a < b
+	}
+	return binary.BigEndian.Uint32(ipA) < binary.BigEndian.Uint32(ipB)
+}
+
+// StartDeviceMonitoring quét mạng LAN định kỳ và gọi callback khi có thiết bị lạ, dừng khi ctx bị huỷ
+func StartDeviceMonitoring(ctx context.Context, registry *DeviceRegistry, interval time.Duration, sweep bool, onNew func([]Device)) {
+	log.Printf("📡 LAN device monitoring started (interval: %v, sweep: %v)", interval, sweep)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		newDevices, err := registry.Scan(sweep)
+		if err != nil {
+			log.Printf("Error scanning LAN devices: %v", err)
+		} else if len(newDevices) > 0 {
+			log.Printf("📡 Found %d new device(s)", len(newDevices))
+			onNew(newDevices)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("📡 LAN device monitoring stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DevicesKey định danh nhóm thiết bị theo MAC để bỏ thông báo trùng
+func DevicesKey(devices []Device) string {
+	macs := make([]string, len(devices))
+	for i, d := range devices {
+		macs[i] = d.MAC
+	}
+	sort.Strings(macs)
+	return strings.Join(macs, ",")
+}
+
+// FormatNewDevices format thông báo khi phát hiện thiết bị lạ
+func FormatNewDevices(p *i18n.Printer, devices []Device) string {
+	var sb strings.Builder
+	sb.WriteString(p.T("devices.new.title"))
+
+	for _, d := range devices {
+		sb.WriteString(p.T("devices.new.item", d.IP, d.MAC, valueOrNA(d.VendorName(p))))
+	}
+
+	sb.WriteString(p.T("devices.new.hint"))
+	return sb.String()
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// heartbeatTimeout là thời gian tối đa của một lần ping
+const heartbeatTimeout = 10 * time.Second
+
+// Heartbeat ping định kỳ URL của dịch vụ giám sát bên ngoài (kiểu healthchecks.io) để báo bot còn chạy (dead man's switch):
+// khi Pi mất điện hoặc bot dừng, dịch vụ không nhận được ping và tự gửi cảnh báo.
+// Khi vòng lặp theo dõi bị lỗi, ping được gửi đến <url>/fail kèm nội dung lỗi.
+type Heartbeat struct {
+	url     *url.URL
+	client  *http.Client
+	started time.Time
+
+	mu      sync.Mutex
+	failure error         // Lỗi gần nhất của vòng lặp theo dõi, nil = bình thường
+	state   string        // Kết quả lần ping trước (chỉ log khi kết quả đổi)
+	changed chan struct{} // Báo trạng thái đổi để ping ngay
+}
+
+// NewHeartbeat tạo heartbeat ping đến rawURL (có thể kèm query string, vd: ?rid=...)
+func NewHeartbeat(rawURL string) (*Heartbeat, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return &Heartbeat{
+		url:     u,
+		client:  &http.Client{Timeout: heartbeatTimeout},
+		started: time.Now(),
+		changed: make(chan struct{}, 1),
+	}, nil
+}
+
+// Report ghi nhận kết quả một lần kiểm tra của vòng lặp theo dõi (nil = thành công).
+// Khi chuyển từ bình thường sang lỗi hoặc ngược lại, heartbeat ping ngay thay vì chờ lần ping tiếp theo.
+func (h *Heartbeat) Report(err error) {
+	h.mu.Lock()
+	changed := (err == nil) != (h.failure == nil)
+	h.failure = err
+	h.mu.Unlock()
+
+	if changed {
+		select {
+		case h.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Ping gửi một heartbeat: URL gốc khi bình thường, <url>/fail khi vòng lặp theo dõi đang lỗi.
+// Nội dung là trạng thái và số liệu cơ bản của Pi (hiển thị trong log của dịch vụ giám sát).
+func (h *Heartbeat) Ping(ctx context.Context) error {
+	h.mu.Lock()
+	failure := h.failure
+	h.mu.Unlock()
+
+	// /fail nối vào path, giữ nguyên query string
+	u := *h.url
+	if failure != nil {
+		u.Path += "/fail"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, heartbeatTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewBufferString(h.body(failure)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("User-Agent", "pi-monitor")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("heartbeat: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// body tạo nội dung heartbeat: trạng thái, thời gian chạy của bot và số liệu hệ thống
+func (h *Heartbeat) body(failure error) string {
+	var sb strings.Builder
+	if failure != nil {
+		fmt.Fprintf(&sb, "status: fail\nerror: %v\n", failure)
+	} else {
+		sb.WriteString("status: ok\n")
+	}
+	fmt.Fprintf(&sb, "bot_uptime: %s\n", time.Since(h.started).Round(time.Second))
+
+	info, err := GetSystemInfo()
+	if err != nil {
+		fmt.Fprintf(&sb, "system_error: %v\n", err)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "pi_uptime: %s\n", info.Uptime.Round(time.Second))
+	fmt.Fprintf(&sb, "cpu_usage: %.1f%%\n", info.CPU.UsagePercent)
+	fmt.Fprintf(&sb, "cpu_temp: %.1f°C\n", info.CPU.Temperature)
+	fmt.Fprintf(&sb, "memory: %.1f%%\n", info.Memory.UsedPercent)
+	fmt.Fprintf(&sb, "disk: %.1f%%\n", info.Disk.UsedPercent)
+	if info.WiFi != nil && info.WiFi.Connected {
+		fmt.Fprintf(&sb, "wifi_signal: %.0f dBm\n", info.WiFi.SignalDBm)
+	}
+	return sb.String()
+}
+
+// StartHeartbeat ping ngay khi khởi động rồi mỗi interval (và khi trạng thái đổi) đến khi ctx bị huỷ
+func StartHeartbeat(ctx context.Context, h *Heartbeat, interval time.Duration) {
+	log.Printf("💓 Heartbeat started (interval: %v)", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		h.ping(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Printf("💓 Heartbeat stopped")
+			return
+		case <-ticker.C:
+		case <-h.changed:
+		}
+	}
+}
+
+// ping gửi heartbeat và log khi kết quả đổi (tránh log mỗi lần ping)
+func (h *Heartbeat) ping(ctx context.Context) {
+	err := h.Ping(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	state := "ok"
+	switch {
+	case err != nil:
+		state = "error: " + err.Error()
+	case h.failure != nil:
+		state = "fail: " + h.failure.Error()
+	}
+	if state == h.state {
+		return
+	}
+	h.state = state
+	switch {
+	case err != nil:
+		log.Printf("⚠️ Heartbeat ping failed: %v", err)
+	case h.failure != nil:
+		log.Printf("💔 Heartbeat reported failure: %v", h.failure)
+	default:
+		log.Printf("💓 Heartbeat ping OK")
+	}
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// pingRequest là một heartbeat mà dịch vụ giám sát giả nhận được
+type pingRequest struct {
+	path  string
+	query string
+	body  string
+}
+
+// fakeMonitor là dịch vụ giám sát giả (kiểu healthchecks.io): trả status cố định và ghi lại các ping
+type fakeMonitor struct {
+	status int
+
+	mu    sync.Mutex
+	pings []pingRequest
+}
+
+func (f *fakeMonitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	f.pings = append(f.pings, pingRequest{path: r.URL.Path, query: r.URL.RawQuery, body: string(body)})
+	f.mu.Unlock()
+	w.WriteHeader(f.status)
+}
+
+// last trả về ping gần nhất
+func (f *fakeMonitor) last(t *testing.T) pingRequest {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.pings) == 0 {
+		t.Fatal("no ping received")
+	}
+	return f.pings[len(f.pings)-1]
+}
+
+// newTestHeartbeat tạo heartbeat ping đến fakeMonitor tại path (kèm query string nếu có)
+func newTestHeartbeat(t *testing.T, status int, path string) (*Heartbeat, *fakeMonitor) {
+	t.Helper()
+	monitor := &fakeMonitor{status: status}
+	srv := httptest.NewServer(monitor)
+	t.Cleanup(srv.Close)
+
+	h, err := NewHeartbeat(srv.URL + path)
+	if err != nil {
+		t.Fatalf("NewHeartbeat() = %v", err)
+	}
+	return h, monitor
+}
+
+func TestHeartbeatPing(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		failure   error
+		wantPath  string
+		wantQuery string
+		wantBody  string
+	}{
+		{name: "ok", url: "/ping/abc", wantPath: "/ping/abc", wantBody: "status: ok"},
+		{name: "ok trailing slash", url: "/ping/abc/", wantPath: "/ping/abc", wantBody: "status: ok"},
+		{name: "ok with query", url: "/ping/abc?rid=42", wantPath: "/ping/abc", wantQuery: "rid=42", wantBody: "status: ok"},
+		{name: "fail", url: "/ping/abc", failure: errors.New("no sensor"), wantPath: "/ping/abc/fail", wantBody: "error: no sensor"},
+		{name: "fail with query", url: "/ping/abc?rid=42", failure: errors.New("no sensor"),
+			wantPath: "/ping/abc/fail", wantQuery: "rid=42", wantBody: "status: fail"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, monitor := newTestHeartbeat(t, http.StatusOK, tt.url)
+			h.Report(tt.failure)
+
+			if err := h.Ping(context.Background()); err != nil {
+				t.Fatalf("Ping() = %v", err)
+			}
+			got := monitor.last(t)
+			if got.path != tt.wantPath || got.query != tt.wantQuery {
+				t.Errorf("ping to %s?%s, want %s?%s", got.path, got.query, tt.wantPath, tt.wantQuery)
+			}
+			if !strings.Contains(got.body, tt.wantBody) {
+				t.Errorf("body = %q, missing %q", got.body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHeartbeatPingStatus(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusInternalServerError} {
+		h, _ := newTestHeartbeat(t, status, "/ping")
+		err := h.Ping(context.Background())
+		if err == nil {
+			t.Errorf("Ping() with HTTP %d succeeded", status)
+			continue
+		}
+		if want := fmt.Sprintf("HTTP %d", status); !strings.Contains(err.Error(), want) {
+			t.Errorf("Ping() = %v, want %s", err, want)
+		}
+	}
+}
+
+func TestHeartbeatRecovers(t *testing.T) {
+	h, monitor := newTestHeartbeat(t, http.StatusOK, "/ping?rid=7")
+
+	h.Report(errors.New("read /proc/stat: permission denied"))
+	if err := h.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() = %v", err)
+	}
+	if got := monitor.last(t); got.path != "/ping/fail" || got.query != "rid=7" {
+		t.Fatalf("failing ping to %s?%s, want /ping/fail?rid=7", got.path, got.query)
+	}
+
+	h.Report(nil)
+	if err := h.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() = %v", err)
+	}
+	if got := monitor.last(t); got.path != "/ping" || got.query != "rid=7" {
+		t.Errorf("recovered ping to %s?%s, want /ping?rid=7", got.path, got.query)
+	}
+}
+
+func TestHeartbeatReportSignalsChange(t *testing.T) {
+	h, _ := newTestHeartbeat(t, http.StatusOK, "/ping")
+
+	// Lỗi liên tiếp chỉ báo một lần, để StartHeartbeat ping ngay khi trạng thái đổi
+	h.Report(errors.New("first"))
+	h.Report(errors.New("second"))
+	if got := len(h.changed); got != 1 {
+		t.Fatalf("changed after failures = %d, want 1", got)
+	}
+	<-h.changed
+
+	h.Report(nil)
+	h.Report(nil)
+	if got := len(h.changed); got != 1 {
+		t.Errorf("changed after recovery = %d, want 1", got)
+	}
+}
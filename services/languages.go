@@ -0,0 +1,153 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"pi-monitor/i18n"
+)
+
+// languageFile là nội dung file lưu ngôn ngữ (vd: data/languages.json)
+type languageFile struct {
+	Chosen   map[int64]i18n.Lang `json:"chosen"`   // Chọn bằng /lang, theo chat (chat riêng: ID chat = ID user)
+	Detected map[int64]i18n.Lang `json:"detected"` // language_code Telegram gửi kèm tin nhắn, theo user
+}
+
+// LanguageStore lưu ngôn ngữ của từng chat và user xuống file
+type LanguageStore struct {
+	mu   sync.Mutex
+	path string
+	data languageFile
+}
+
+// NewLanguageStore tạo store và nạp ngôn ngữ đã lưu (nếu có)
+func NewLanguageStore(path string) *LanguageStore {
+	s := &LanguageStore{path: path}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &s.data); err != nil {
+			log.Printf("⚠️ Cannot parse languages %s: %v", path, err)
+		}
+	}
+	if s.data.Chosen == nil {
+		s.data.Chosen = make(map[int64]i18n.Lang)
+	}
+	if s.data.Detected == nil {
+		s.data.Detected = make(map[int64]i18n.Lang)
+	}
+	return s
+}
+
+// Chosen trả về ngôn ngữ chat đã chọn bằng /lang
+func (s *LanguageStore) Chosen(chatID int64) (i18n.Lang, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.data.Chosen[chatID]
+	return l, ok
+}
+
+// Set lưu ngôn ngữ chat chọn bằng /lang
+func (s *LanguageStore) Set(chatID int64, lang i18n.Lang) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, had := s.data.Chosen[chatID]
+	s.data.Chosen[chatID] = lang
+	if err := s.save(); err != nil {
+		if had {
+			s.data.Chosen[chatID] = old
+		} else {
+			delete(s.data.Chosen, chatID)
+		}
+		return err
+	}
+	return nil
+}
+
+// Reset bỏ ngôn ngữ đã chọn, chat quay về dùng language_code của Telegram
+func (s *LanguageStore) Reset(chatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, had := s.data.Chosen[chatID]
+	if !had {
+		return nil
+	}
+	delete(s.data.Chosen, chatID)
+	if err := s.save(); err != nil {
+		s.data.Chosen[chatID] = old
+		return err
+	}
+	return nil
+}
+
+// Detect ghi nhận language_code Telegram gửi kèm tin nhắn của user.
+// Mã chưa hỗ trợ (hoặc trống) thì bỏ qua và giữ ngôn ngữ đã ghi nhận trước đó.
+func (s *LanguageStore) Detect(userID int64, code string) {
+	lang, ok := i18n.Parse(code)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Detected[userID] == lang {
+		return
+	}
+	s.data.Detected[userID] = lang
+	if err := s.save(); err != nil {
+		log.Printf("⚠️ Cannot save languages: %v", err)
+	}
+}
+
+// Resolve trả về ngôn ngữ trả lời user trong chat: ngôn ngữ chat đã chọn, rồi ngôn ngữ user
+// đã chọn trong chat riêng, rồi language_code của user, cuối cùng là fallback
+func (s *LanguageStore) Resolve(chatID, userID int64, fallback i18n.Lang) i18n.Lang {
+	return s.ResolveCode(chatID, userID, "", fallback)
+}
+
+// ResolveCode giống Resolve nhưng language_code gửi kèm tin nhắn hiện tại (code) được ưu tiên hơn
+// language_code đã ghi nhận. Không ghi lại code, dùng Detect để ghi nhận.
+func (s *LanguageStore) ResolveCode(chatID, userID int64, code string, fallback i18n.Lang) i18n.Lang {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if l, ok := s.data.Chosen[chatID]; ok {
+		return l
+	}
+	if l, ok := s.data.Chosen[userID]; ok {
+		return l
+	}
+	if l, ok := i18n.Parse(code); ok {
+		return l
+	}
+	if l, ok := s.data.Detected[userID]; ok {
+		return l
+	}
+	return fallback
+}
+
+// Lang trả về ngôn ngữ gửi thông báo tới chat (không có người gửi): ngôn ngữ đã chọn,
+// rồi language_code của user với chat riêng, cuối cùng là fallback
+func (s *LanguageStore) Lang(chatID int64, fallback i18n.Lang) i18n.Lang {
+	return s.Resolve(chatID, chatID, fallback)
+}
+
+// save ghi ngôn ngữ xuống file (gọi khi đang giữ lock)
+func (s *LanguageStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
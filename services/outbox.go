@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"pi-monitor/i18n"
+)
+
+const (
+	// outboxMaxSize là số tin nhắn tối đa trong hàng đợi, đầy thì bỏ tin cũ nhất
+	outboxMaxSize = 500
+	// outboxMaxAge là thời gian tối đa một tin nhắn được giữ để gửi lại
+	outboxMaxAge = 24 * time.Hour
+	// outboxDedupWindow là khoảng thời gian tin nhắn trùng (cùng loại, nơi nhận và Key) đã gửi không được gửi lại
+	outboxDedupWindow = 10 * time.Minute
+	// outboxMinBackoff, outboxMaxBackoff giới hạn thời gian chờ giữa các lần gửi lại (tăng gấp đôi mỗi lần lỗi)
+	outboxMinBackoff = 5 * time.Second
+	outboxMaxBackoff = 10 * time.Minute
+	// outboxPrivateInterval, outboxGroupInterval là khoảng cách tối thiểu giữa hai tin nhắn đến cùng một chat
+	// (Telegram giới hạn ~1 tin/giây với chat riêng và 20 tin/phút với group/channel)
+	outboxPrivateInterval = time.Second
+	outboxGroupInterval   = 3 * time.Second
+)
+
+// OutboxDelayedAfter là thời gian từ lúc tạo, gửi trễ hơn thì tin nhắn được đánh dấu là thông báo trễ
+const OutboxDelayedAfter = time.Minute
+
+// OutboxMessage là thông báo chờ gửi đến một chat hoặc forum topic
+type OutboxMessage struct {
+	ID          uint64     `json:"id"`
+	ChatID      int64      `json:"chat_id"`
+	ThreadID    int        `json:"thread_id,omitempty"`
+	Type        NotifyType `json:"type"`
+	Severity    Severity   `json:"severity"`
+	Lang        i18n.Lang  `json:"lang"`          // Ngôn ngữ nội dung, dùng cho dòng đánh dấu thông báo trễ
+	Text        string     `json:"text"`          // Nội dung HTML
+	Key         string     `json:"key,omitempty"` // Định danh nội dung để bỏ tin trùng (không gồm thời gian trong Text), rỗng = không bỏ
+	CreatedAt   time.Time  `json:"created_at"`
+	Attempts    int        `json:"attempts,omitempty"`
+	NextAttempt time.Time  `json:"next_attempt,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// Destination trả về nơi nhận tin nhắn
+func (m OutboxMessage) Destination() Destination {
+	return Destination{ChatID: m.ChatID, ThreadID: m.ThreadID}
+}
+
+// Delayed kiểm tra tin nhắn gửi lúc now có bị trễ so với lúc tạo không
+func (m OutboxMessage) Delayed(now time.Time) bool {
+	return now.Sub(m.CreatedAt) >= OutboxDelayedAfter
+}
+
+// dedupKey định danh tin nhắn theo loại, nơi nhận và Key. Rỗng nếu tin nhắn không có Key.
+func (m OutboxMessage) dedupKey() string {
+	if m.Key == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%d/%d/%s", m.Type, m.ChatID, m.ThreadID, m.Key)))
+	return hex.EncodeToString(sum[:])
+}
+
+// DeliveryError là lỗi gửi tin nhắn kèm cách xử lý:
+// RetryAfter > 0 là Telegram yêu cầu chờ (HTTP 429), Permanent là không thể gửi lại (chat không tồn tại, bot bị chặn)
+type DeliveryError struct {
+	Err        error
+	RetryAfter time.Duration
+	Permanent  bool
+}
+
+func (e *DeliveryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// OutboxSender gửi một tin nhắn trong hàng đợi
+type OutboxSender func(m OutboxMessage) error
+
+// Outbox là hàng đợi thông báo gửi đi, lưu xuống file (vd: data/outbox.json) để không mất thông báo
+// khi mạng chập chờn hoặc bot khởi động lại. Tin nhắn lỗi được gửi lại với thời gian chờ tăng dần,
+// theo thứ tự tạo trong từng chat.
+type Outbox struct {
+	mu      sync.Mutex
+	path    string
+	send    OutboxSender
+	pending []*OutboxMessage
+	nextID  uint64
+	sent    map[string]time.Time // Tin nhắn đã gửi gần đây (theo dedupKey) để bỏ tin trùng
+	chatAt  map[int64]time.Time  // Thời điểm sớm nhất được gửi tin tiếp theo đến từng chat
+	wake    chan struct{}
+}
+
+// NewOutbox tạo hàng đợi và nạp các tin nhắn chưa gửi được lần chạy trước (nếu có)
+func NewOutbox(path string, send OutboxSender) *Outbox {
+	o := &Outbox{
+		path:   path,
+		send:   send,
+		sent:   make(map[string]time.Time),
+		chatAt: make(map[int64]time.Time),
+		wake:   make(chan struct{}, 1),
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &o.pending); err != nil {
+			log.Printf("⚠️ Cannot parse outbox %s: %v", path, err)
+		}
+	}
+	for _, m := range o.pending {
+		o.nextID = max(o.nextID, m.ID)
+	}
+	if n := len(o.pending); n > 0 {
+		log.Printf("📤 Outbox: %d queued messages from the previous run", n)
+	}
+	return o
+}
+
+// Enqueue thêm tin nhắn vào hàng đợi. Trả về false nếu tin nhắn trùng với tin đang chờ hoặc vừa gửi.
+func (o *Outbox) Enqueue(m OutboxMessage) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	if key := m.dedupKey(); key != "" {
+		if at, ok := o.sent[key]; ok && now.Sub(at) < outboxDedupWindow {
+			return false
+		}
+		for _, p := range o.pending {
+			if p.dedupKey() == key {
+				return false
+			}
+		}
+	}
+
+	o.nextID++
+	m.ID = o.nextID
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = now
+	}
+	m.Attempts, m.NextAttempt, m.LastError = 0, time.Time{}, ""
+	o.pending = append(o.pending, &m)
+	if n := len(o.pending) - outboxMaxSize; n > 0 {
+		log.Printf("⚠️ Outbox full, dropping %d oldest messages", n)
+		o.pending = o.pending[n:]
+	}
+	if err := o.save(); err != nil {
+		log.Printf("⚠️ Cannot save outbox: %v", err)
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// Len trả về số tin nhắn đang chờ gửi
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+// Run gửi tin nhắn trong hàng đợi đến khi ctx bị huỷ
+func (o *Outbox) Run(ctx context.Context) {
+	log.Printf("📤 Outbox started")
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("📤 Outbox stopped (%d queued)", o.Len())
+			return
+		case <-timer.C:
+		case <-o.wake:
+		}
+
+		wait := o.deliver(ctx)
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+	}
+}
+
+// Flush gửi ngay các tin nhắn còn trong hàng đợi (bỏ qua thời gian chờ gửi lại) đến khi hết, gặp lỗi hoặc ctx hết hạn.
+// Dùng khi dừng bot; tin nhắn chưa gửi được vẫn nằm trong file để gửi ở lần chạy sau.
+func (o *Outbox) Flush(ctx context.Context) {
+	o.mu.Lock()
+	for _, m := range o.pending {
+		m.NextAttempt = time.Time{}
+	}
+	o.mu.Unlock()
+
+	for o.Len() > 0 {
+		wait := o.deliver(ctx)
+		if ctx.Err() != nil || o.Len() == 0 {
+			return
+		}
+		if o.retrying() {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retrying kiểm tra có tin nhắn đang chờ gửi lại sau lỗi không
+func (o *Outbox) retrying() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	for _, m := range o.pending {
+		if m.NextAttempt.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// deliver gửi các tin nhắn đến hạn, trả về thời gian chờ đến lượt gửi tiếp theo.
+// Lỗi mạng dừng lượt gửi (các tin sau cũng sẽ lỗi), lượt sau bắt đầu lại từ tin lỗi khi hết thời gian chờ.
+func (o *Outbox) deliver(ctx context.Context) time.Duration {
+	wait := outboxMaxBackoff
+	blocked := make(map[int64]bool) // Chat có tin trước chưa gửi được, giữ thứ tự tin trong chat
+
+	for _, m := range o.snapshot() {
+		if ctx.Err() != nil {
+			break
+		}
+		now := time.Now()
+		if blocked[m.ChatID] {
+			continue
+		}
+		if m.CreatedAt.Before(now.Add(-outboxMaxAge)) {
+			log.Printf("🗑️ Dropping %s notification to chat %d queued since %s: %q", m.Type, m.ChatID, m.CreatedAt.Format(time.DateTime), i18n.PlainText(m.Text))
+			o.remove(m.ID, false)
+			continue
+		}
+		due := m.NextAttempt
+		if at := o.chatReady(m.ChatID); at.After(due) {
+			due = at
+		}
+		if due.After(now) {
+			blocked[m.ChatID] = true
+			wait = min(wait, due.Sub(now))
+			continue
+		}
+
+		err := o.send(*m)
+		o.markSent(m.ChatID)
+		if err == nil {
+			if m.Delayed(time.Now()) {
+				log.Printf("✅ %s notification (%s) sent to chat %d, delayed since %s", m.Type, m.Severity, m.ChatID, m.CreatedAt.Format(time.DateTime))
+			} else {
+				log.Printf("✅ %s notification (%s) sent to chat %d", m.Type, m.Severity, m.ChatID)
+			}
+			o.remove(m.ID, true)
+			continue
+		}
+
+		var de *DeliveryError
+		switch {
+		case errors.As(err, &de) && de.Permanent:
+			log.Printf("❌ Cannot send %s notification to chat %d, dropping: %v: %q", m.Type, m.ChatID, err, i18n.PlainText(m.Text))
+			o.remove(m.ID, false)
+		case errors.As(err, &de) && de.RetryAfter > 0:
+			log.Printf("🐢 Telegram rate limit for chat %d, retrying in %v", m.ChatID, de.RetryAfter)
+			o.delayChat(m.ChatID, de.RetryAfter)
+			blocked[m.ChatID] = true
+			wait = min(wait, de.RetryAfter)
+		default:
+			backoff := o.retry(m.ID, err)
+			log.Printf("⚠️ Error sending %s notification to chat %d (queued, retrying in %v): %v", m.Type, m.ChatID, backoff, err)
+			return min(wait, backoff)
+		}
+	}
+	return wait
+}
+
+// snapshot trả về bản sao các tin nhắn đang chờ theo thứ tự tạo
+func (o *Outbox) snapshot() []*OutboxMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	list := make([]*OutboxMessage, len(o.pending))
+	for i, m := range o.pending {
+		c := *m
+		list[i] = &c
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// remove bỏ tin nhắn khỏi hàng đợi, delivered = đã gửi thành công (ghi nhận để bỏ tin trùng)
+func (o *Outbox) remove(id uint64, delivered bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	for i, m := range o.pending {
+		if m.ID != id {
+			continue
+		}
+		if key := m.dedupKey(); delivered && key != "" {
+			o.sent[key] = now
+		}
+		o.pending = append(o.pending[:i], o.pending[i+1:]...)
+		break
+	}
+	for key, at := range o.sent {
+		if now.Sub(at) >= outboxDedupWindow {
+			delete(o.sent, key)
+		}
+	}
+	if err := o.save(); err != nil {
+		log.Printf("⚠️ Cannot save outbox: %v", err)
+	}
+}
+
+// retry ghi nhận lần gửi lỗi và hẹn lần gửi lại, trả về thời gian chờ
+func (o *Outbox) retry(id uint64, err error) time.Duration {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, m := range o.pending {
+		if m.ID != id {
+			continue
+		}
+		m.Attempts++
+		backoff := outboxMaxBackoff
+		if shift := m.Attempts - 1; shift < 8 {
+			backoff = min(outboxMinBackoff<<shift, outboxMaxBackoff)
+		}
+		m.NextAttempt = time.Now().Add(backoff)
+		m.LastError = err.Error()
+		if err := o.save(); err != nil {
+			log.Printf("⚠️ Cannot save outbox: %v", err)
+		}
+		return backoff
+	}
+	return outboxMinBackoff
+}
+
+// chatReady trả về thời điểm sớm nhất được gửi tin tiếp theo đến chat
+func (o *Outbox) chatReady(chatID int64) time.Time {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.chatAt[chatID]
+}
+
+// markSent ghi nhận vừa gửi tin đến chat, tin tiếp theo phải chờ theo giới hạn của Telegram
+func (o *Outbox) markSent(chatID int64) {
+	interval := outboxPrivateInterval
+	if chatID < 0 {
+		interval = outboxGroupInterval
+	}
+	o.delayChat(chatID, interval)
+}
+
+// delayChat hoãn tin tiếp theo đến chat thêm d tính từ bây giờ
+func (o *Outbox) delayChat(chatID int64, d time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if at := time.Now().Add(d); at.After(o.chatAt[chatID]) {
+		o.chatAt[chatID] = at
+	}
+}
+
+// save ghi hàng đợi xuống file (gọi khi đang giữ lock)
+func (o *Outbox) save() error {
+	if o.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(o.pending, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(o.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(o.path, data, 0o644)
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSender là OutboxSender giả: trả lỗi cố định theo chat và ghi lại các tin đã thử gửi
+type fakeSender struct {
+	errs map[int64]error // Lỗi trả về cho từng chat, không có = gửi thành công
+
+	mu   sync.Mutex
+	sent []OutboxMessage
+}
+
+func (f *fakeSender) send(m OutboxMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, m)
+	return f.errs[m.ChatID]
+}
+
+func (f *fakeSender) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.sent)
+}
+
+// newTestOutbox tạo hàng đợi lưu trong thư mục tạm của test
+func newTestOutbox(t *testing.T, f *fakeSender) (*Outbox, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	return NewOutbox(path, f.send), path
+}
+
+// pendingMessage trả về bản sao tin nhắn đang chờ theo ID
+func pendingMessage(t *testing.T, o *Outbox, id uint64) OutboxMessage {
+	t.Helper()
+	for _, m := range o.snapshot() {
+		if m.ID == id {
+			return *m
+		}
+	}
+	t.Fatalf("message %d not queued", id)
+	return OutboxMessage{}
+}
+
+func TestOutboxDeliver(t *testing.T) {
+	errNetwork := errors.New("dial tcp: i/o timeout")
+	tests := []struct {
+		name         string
+		err          error
+		wantQueued   bool
+		wantAttempts int
+		wantWait     time.Duration // Thời gian chờ tối đa deliver trả về
+		wantOther    bool          // Tin đến chat khác trong cùng lượt vẫn được gửi
+	}{
+		{name: "sent", wantWait: outboxMaxBackoff, wantOther: true},
+		{name: "permanent", err: &DeliveryError{Err: errors.New("Forbidden: bot was blocked by the user"), Permanent: true},
+			wantWait: outboxMaxBackoff, wantOther: true},
+		{name: "retry after", err: &DeliveryError{Err: errors.New("Too Many Requests"), RetryAfter: 30 * time.Second},
+			wantQueued: true, wantWait: 30 * time.Second, wantOther: true},
+		{name: "network", err: errNetwork, wantQueued: true, wantAttempts: 1, wantWait: outboxMinBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeSender{errs: map[int64]error{1: tt.err}}
+			o, _ := newTestOutbox(t, f)
+			o.Enqueue(OutboxMessage{ChatID: 1, Type: NotifySystem, Text: "first"})
+			o.Enqueue(OutboxMessage{ChatID: 2, Type: NotifySystem, Text: "second"})
+
+			wait := o.deliver(context.Background())
+			if wait > tt.wantWait || wait < tt.wantWait-time.Second {
+				t.Errorf("deliver() wait = %v, want about %v", wait, tt.wantWait)
+			}
+			if gotOther := f.count() == 2; gotOther != tt.wantOther {
+				t.Errorf("sent %d message(s), other chat sent = %v, want %v", f.count(), gotOther, tt.wantOther)
+			}
+
+			queued := false
+			for _, m := range o.snapshot() {
+				if m.ChatID != 1 {
+					continue
+				}
+				queued = true
+				if m.Attempts != tt.wantAttempts {
+					t.Errorf("Attempts = %d, want %d", m.Attempts, tt.wantAttempts)
+				}
+				if tt.wantAttempts > 0 && m.LastError != errNetwork.Error() {
+					t.Errorf("LastError = %q, want %q", m.LastError, errNetwork)
+				}
+			}
+			if queued != tt.wantQueued {
+				t.Errorf("message queued = %v, want %v", queued, tt.wantQueued)
+			}
+		})
+	}
+}
+
+func TestOutboxRetryAfterDelaysChat(t *testing.T) {
+	f := &fakeSender{errs: map[int64]error{1: &DeliveryError{Err: errors.New("Too Many Requests"), RetryAfter: time.Minute}}}
+	o, _ := newTestOutbox(t, f)
+	o.Enqueue(OutboxMessage{ChatID: 1, Text: "first"})
+	o.Enqueue(OutboxMessage{ChatID: 1, Text: "second"})
+
+	o.deliver(context.Background())
+	o.deliver(context.Background())
+	if f.count() != 1 {
+		t.Errorf("sent %d message(s) during rate limit, want 1", f.count())
+	}
+	if ready := o.chatReady(1); time.Until(ready) < 59*time.Second {
+		t.Errorf("chat ready in %v, want about 1m", time.Until(ready))
+	}
+	if o.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", o.Len())
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	o, _ := newTestOutbox(t, &fakeSender{})
+	o.Enqueue(OutboxMessage{ChatID: 1, Text: "x"})
+
+	want := []time.Duration{
+		5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second,
+		160 * time.Second, 320 * time.Second, 10 * time.Minute, 10 * time.Minute, 10 * time.Minute,
+	}
+	for i, w := range want {
+		start := time.Now()
+		if got := o.retry(1, errors.New("offline")); got != w {
+			t.Errorf("attempt %d: backoff = %v, want %v", i+1, got, w)
+		}
+		m := pendingMessage(t, o, 1)
+		if m.Attempts != i+1 {
+			t.Errorf("attempt %d: Attempts = %d", i+1, m.Attempts)
+		}
+		if next := m.NextAttempt.Sub(start); next < w || next > w+time.Second {
+			t.Errorf("attempt %d: NextAttempt in %v, want %v", i+1, next, w)
+		}
+	}
+}
+
+func TestOutboxDedup(t *testing.T) {
+	base := OutboxMessage{ChatID: 1, Type: NotifySystem, Key: "cpu_temp"}
+	tests := []struct {
+		name string
+		next OutboxMessage
+		want bool
+	}{
+		{name: "same key", next: base, want: false},
+		{name: "other key", next: OutboxMessage{ChatID: 1, Type: NotifySystem, Key: "disk"}, want: true},
+		{name: "other chat", next: OutboxMessage{ChatID: 2, Type: NotifySystem, Key: "cpu_temp"}, want: true},
+		{name: "other topic", next: OutboxMessage{ChatID: 1, ThreadID: 7, Type: NotifySystem, Key: "cpu_temp"}, want: true},
+		{name: "other type", next: OutboxMessage{ChatID: 1, Type: NotifyDevices, Key: "cpu_temp"}, want: true},
+		{name: "no key", next: OutboxMessage{ChatID: 1, Type: NotifySystem}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, _ := newTestOutbox(t, &fakeSender{})
+			if !o.Enqueue(base) {
+				t.Fatal("first Enqueue() = false")
+			}
+			if got := o.Enqueue(tt.next); got != tt.want {
+				t.Errorf("Enqueue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutboxDedupAfterDelivery(t *testing.T) {
+	f := &fakeSender{}
+	o, _ := newTestOutbox(t, f)
+	m := OutboxMessage{ChatID: 1, Type: NotifySystem, Key: "cpu_temp"}
+
+	o.Enqueue(m)
+	o.deliver(context.Background())
+	if o.Enqueue(m) {
+		t.Error("Enqueue() right after delivery = true, want duplicate")
+	}
+
+	// Hết cửa sổ bỏ trùng thì gửi lại
+	o.mu.Lock()
+	for key := range o.sent {
+		o.sent[key] = time.Now().Add(-outboxDedupWindow)
+	}
+	o.mu.Unlock()
+	if !o.Enqueue(m) {
+		t.Error("Enqueue() after dedup window = false, want queued")
+	}
+
+	// Tin bị bỏ vì lỗi vĩnh viễn không tính là đã gửi
+	f.errs = map[int64]error{3: &DeliveryError{Err: errors.New("Bad Request: chat not found"), Permanent: true}}
+	dropped := OutboxMessage{ChatID: 3, Type: NotifySystem, Key: "cpu_temp"}
+	o.Enqueue(dropped)
+	o.deliver(context.Background())
+	if !o.Enqueue(dropped) {
+		t.Error("Enqueue() after permanent error = false, want queued")
+	}
+}
+
+func TestOutboxMaxAge(t *testing.T) {
+	f := &fakeSender{}
+	o, _ := newTestOutbox(t, f)
+	o.Enqueue(OutboxMessage{ChatID: 1, Text: "stale", CreatedAt: time.Now().Add(-outboxMaxAge - time.Minute)})
+	o.Enqueue(OutboxMessage{ChatID: 2, Text: "fresh", CreatedAt: time.Now().Add(-time.Hour)})
+
+	o.deliver(context.Background())
+	if f.count() != 1 || f.sent[0].Text != "fresh" {
+		t.Errorf("sent %+v, want only the fresh message", f.sent)
+	}
+	if o.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", o.Len())
+	}
+}
+
+func TestOutboxMaxSize(t *testing.T) {
+	o, _ := newTestOutbox(t, &fakeSender{})
+	for i := 0; i < outboxMaxSize+5; i++ {
+		o.Enqueue(OutboxMessage{ChatID: int64(i + 1)})
+	}
+
+	if o.Len() != outboxMaxSize {
+		t.Fatalf("Len() = %d, want %d", o.Len(), outboxMaxSize)
+	}
+	if first := o.snapshot()[0]; first.ID != 6 {
+		t.Errorf("oldest queued ID = %d, want 6", first.ID)
+	}
+}
+
+func TestOutboxReload(t *testing.T) {
+	f := &fakeSender{errs: map[int64]error{1: errors.New("offline")}}
+	o, path := newTestOutbox(t, f)
+	o.Enqueue(OutboxMessage{ChatID: 1, ThreadID: 4, Type: NotifyIP, Severity: SeverityInfo, Text: "<b>ip</b>", Key: "1.2.3.4/"})
+	o.Enqueue(OutboxMessage{ChatID: 1, Type: NotifySystem, Text: "second"})
+	o.deliver(context.Background())
+
+	reloaded := NewOutbox(path, f.send)
+	if reloaded.Len() != 2 {
+		t.Fatalf("reloaded Len() = %d, want 2", reloaded.Len())
+	}
+	m := pendingMessage(t, reloaded, 1)
+	if m.ThreadID != 4 || m.Type != NotifyIP || m.Text != "<b>ip</b>" || m.Key != "1.2.3.4/" {
+		t.Errorf("reloaded message = %+v", m)
+	}
+	if m.Attempts != 1 || m.LastError != "offline" || m.NextAttempt.IsZero() {
+		t.Errorf("reloaded retry state = %d/%q/%v, want 1/offline/set", m.Attempts, m.LastError, m.NextAttempt)
+	}
+
+	// ID tiếp tục sau tin đã nạp, tin trùng với tin đang chờ vẫn bị bỏ
+	reloaded.Enqueue(OutboxMessage{ChatID: 2})
+	if last := reloaded.snapshot()[2]; last.ID != 3 {
+		t.Errorf("new message ID = %d, want 3", last.ID)
+	}
+	if reloaded.Enqueue(OutboxMessage{ChatID: 1, ThreadID: 4, Type: NotifyIP, Key: "1.2.3.4/"}) {
+		t.Error("Enqueue() duplicate of reloaded message = true")
+	}
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"pi-monitor/i18n"
+)
+
+// powerHistoryDays là số ngày lịch sử sử dụng được giữ lại
+const powerHistoryDays = 35
+
+// PowerHost là một PC được theo dõi trạng thái bật/tắt
+type PowerHost struct {
+	Name    string
+	Host    string
+	MAC     string
+	Methods []string // Phương thức kiểm tra (icmp, arp, tcp)
+	Ports   []int    // Port TCP
+}
+
+// PowerSession là một phiên PC bật, End rỗng nghĩa là PC đang bật
+type PowerSession struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// PowerEvent được tạo khi PC chuyển trạng thái online/offline
+type PowerEvent struct {
+	Name     string
+	Online   bool
+	Time     time.Time
+	Duration time.Duration      // Thời lượng phiên vừa kết thúc (khi offline) hoặc thời gian đã tắt (khi online)
+	Result   ReachabilityResult // Kết quả kiểm tra khi online
+}
+
+// powerState là trạng thái đã lưu của một PC
+type powerState struct {
+	Online     bool           `json:"online"`
+	Since      time.Time      `json:"since"`       // Thời điểm chuyển sang trạng thái hiện tại
+	LastOnline time.Time      `json:"last_online"` // Lần cuối thấy PC online
+	Sessions   []PowerSession `json:"sessions,omitempty"`
+
+	failures int // Số lần kiểm tra thất bại liên tiếp
+}
+
+// PowerTracker theo dõi trạng thái bật/tắt của các PC và lưu lịch sử sử dụng
+type PowerTracker struct {
+	OfflineChecks int // Số lần kiểm tra thất bại liên tiếp trước khi coi là offline
+
+	mu     sync.Mutex
+	path   string
+	hosts  []PowerHost
+	states map[string]*powerState
+}
+
+// NewPowerTracker tạo tracker mới và đọc lịch sử đã lưu (nếu có)
+func NewPowerTracker(hosts []PowerHost, path string, offlineChecks int) *PowerTracker {
+	if offlineChecks < 1 {
+		offlineChecks = 1
+	}
+
+	t := &PowerTracker{
+		OfflineChecks: offlineChecks,
+		path:          path,
+		hosts:         hosts,
+		states:        make(map[string]*powerState),
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &t.states); err != nil {
+			log.Printf("⚠️ Cannot parse PC usage log %s: %v", path, err)
+			t.states = make(map[string]*powerState)
+		}
+	}
+
+	return t
+}
+
+// SetHosts đổi danh sách PC được theo dõi (reload cấu hình), lịch sử của PC cũ vẫn được giữ
+func (t *PowerTracker) SetHosts(hosts []PowerHost) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hosts = hosts
+}
+
+// Poll kiểm tra tất cả PC một lần và trả về các thay đổi trạng thái
+func (t *PowerTracker) Poll() []PowerEvent {
+	type checkResult struct {
+		host   PowerHost
+		result ReachabilityResult
+	}
+
+	t.mu.Lock()
+	hosts := t.hosts
+	t.mu.Unlock()
+
+	// Kiểm tra song song, không giữ lock trong lúc chờ mạng
+	results := make(chan checkResult, len(hosts))
+	for _, h := range hosts {
+		go func(h PowerHost) {
+			results <- checkResult{host: h, result: NewReachabilityChecker(h.Methods, h.Ports).Check(h.Host, h.MAC)}
+		}(h)
+	}
+
+	now := time.Now()
+	var events []PowerEvent
+
+	t.mu.Lock()
+	for range hosts {
+		r := <-results
+		if event, changed := t.update(r.host.Name, r.result, now); changed {
+			events = append(events, event)
+		}
+	}
+	err := t.save()
+	t.mu.Unlock()
+
+	if err != nil {
+		log.Printf("⚠️ Cannot save PC usage log: %v", err)
+	}
+	return events
+}
+
+// update cập nhật trạng thái một PC (gọi khi đang giữ lock)
+func (t *PowerTracker) update(name string, result ReachabilityResult, now time.Time) (PowerEvent, bool) {
+	state, exists := t.states[name]
+	if !exists {
+		// Lần đầu thấy PC: ghi nhận trạng thái, không thông báo
+		state = &powerState{Online: result.Online, Since: now}
+		if result.Online {
+			state.LastOnline = now
+			state.Sessions = append(state.Sessions, PowerSession{Start: now})
+		}
+		t.states[name] = state
+		return PowerEvent{}, false
+	}
+
+	if result.Online {
+		state.failures = 0
+		state.LastOnline = now
+		if state.Online {
+			return PowerEvent{}, false
+		}
+
+		offFor := now.Sub(state.Since)
+		state.Online = true
+		state.Since = now
+		state.Sessions = append(state.Sessions, PowerSession{Start: now})
+		t.prune(state, now)
+		return PowerEvent{Name: name, Online: true, Time: now, Duration: offFor, Result: result}, true
+	}
+
+	if !state.Online {
+		return PowerEvent{}, false
+	}
+
+	// Chờ vài lần thất bại liên tiếp để tránh báo sai khi mất gói
+	state.failures++
+	if state.failures < t.OfflineChecks {
+		return PowerEvent{}, false
+	}
+
+	// Phiên kết thúc tại lần cuối còn thấy PC online
+	end := state.LastOnline
+	if end.IsZero() {
+		end = now
+	}
+	state.failures = 0
+	state.Online = false
+	state.Since = end
+
+	var session time.Duration
+	if n := len(state.Sessions); n > 0 && state.Sessions[n-1].End.IsZero() {
+		state.Sessions[n-1].End = end
+		session = end.Sub(state.Sessions[n-1].Start)
+	}
+	return PowerEvent{Name: name, Online: false, Time: end, Duration: session}, true
+}
+
+// prune xoá các phiên cũ hơn powerHistoryDays ngày
+func (t *PowerTracker) prune(state *powerState, now time.Time) {
+	cutoff := now.AddDate(0, 0, -powerHistoryDays)
+	kept := state.Sessions[:0]
+	for _, s := range state.Sessions {
+		if s.End.IsZero() || s.End.After(cutoff) {
+			kept = append(kept, s)
+		}
+	}
+	state.Sessions = kept
+}
+
+// Flush ghi lịch sử sử dụng xuống file, gọi khi dừng bot
+func (t *PowerTracker) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.save()
+}
+
+// save ghi lịch sử sử dụng xuống file (gọi khi đang giữ lock)
+func (t *PowerTracker) save() error {
+	if t.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.states, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, data, 0o644)
+}
+
+// PowerStatus là trạng thái hiện tại và thời gian sử dụng của một PC
+type PowerStatus struct {
+	Name    string
+	Known   bool // Đã từng kiểm tra
+	Online  bool
+	Since   time.Time
+	Today   time.Duration
+	Week    time.Duration   // 7 ngày gần nhất (tính cả hôm nay)
+	PerDay  []time.Duration // 7 phần tử, PerDay[0] là 6 ngày trước, PerDay[6] là hôm nay
+	DayList []time.Time     // Ngày tương ứng với PerDay
+}
+
+// Status trả về trạng thái và thời gian sử dụng theo ngày/tuần của các PC, ngày cắt theo timezone của now
+func (t *PowerTracker) Status(now time.Time) []PowerStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var statuses []PowerStatus
+	for _, h := range t.hosts {
+		status := PowerStatus{Name: h.Name}
+
+		state, ok := t.states[h.Name]
+		if ok {
+			status.Known = true
+			status.Online = state.Online
+			status.Since = state.Since
+		}
+
+		for i := 6; i >= 0; i-- {
+			dayStart := today.AddDate(0, 0, -i)
+			dayEnd := dayStart.AddDate(0, 0, 1)
+
+			var used time.Duration
+			if ok {
+				used = sessionsOverlap(state.Sessions, dayStart, dayEnd, now)
+			}
+
+			status.PerDay = append(status.PerDay, used)
+			status.DayList = append(status.DayList, dayStart)
+			status.Week += used
+		}
+		status.Today = status.PerDay[6]
+
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// sessionsOverlap tính tổng thời gian các phiên nằm trong khoảng [from, to)
+func sessionsOverlap(sessions []PowerSession, from, to, now time.Time) time.Duration {
+	var total time.Duration
+	for _, s := range sessions {
+		end := s.End
+		if end.IsZero() {
+			end = now
+		}
+
+		start := s.Start
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// StartPowerTracking kiểm tra trạng thái PC định kỳ và gọi callback khi PC bật/tắt, dừng khi ctx bị huỷ
+func StartPowerTracking(ctx context.Context, tracker *PowerTracker, interval time.Duration, onChange func(PowerEvent)) {
+	tracker.mu.Lock()
+	count := len(tracker.hosts)
+	tracker.mu.Unlock()
+	log.Printf("🔌 PC power tracking started (%d host(s), interval: %v)", count, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for _, event := range tracker.Poll() {
+			log.Printf("🔌 %s is now online=%v", event.Name, event.Online)
+			onChange(event)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("🔌 PC power tracking stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// FormatPowerEvent format thông báo khi PC bật/tắt
+func FormatPowerEvent(p *i18n.Printer, e PowerEvent) string {
+	if e.Online {
+		return p.T("power.event.online",
+			e.Name,
+			e.Result,
+			p.DurationShort(e.Duration),
+		) + "\n\n" + p.T("common.time_footer", p.DateTime(e.Time))
+	}
+	return p.T("power.event.offline",
+		e.Name,
+		p.DurationShort(e.Duration),
+	) + "\n\n" + p.T("common.time_footer", p.DateTime(e.Time))
+}
@@ -0,0 +1,395 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"pi-monitor/i18n"
+)
+
+// PublicIP chứa địa chỉ IP public hiện tại của Pi
+type PublicIP struct {
+	IPv4      string    `json:"ipv4,omitempty"`
+	IPv6      string    `json:"ipv6,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Equal so sánh hai địa chỉ (bỏ qua thời gian cập nhật)
+func (p PublicIP) Equal(other PublicIP) bool {
+	return p.IPv4 == other.IPv4 && p.IPv6 == other.IPv6
+}
+
+// PublicIPMonitor định kỳ kiểm tra IP public và lưu giá trị cuối cùng xuống file
+type PublicIPMonitor struct {
+	Endpoints  []string // URL trả về IP dạng text
+	STUNServer string   // host:port của STUN server, để trống nếu không dùng
+	IPv6       bool     // Kiểm tra cả IPv6
+
+	statePath string
+	timeout   time.Duration
+
+	mu   sync.Mutex
+	last PublicIP
+}
+
+// NewPublicIPMonitor tạo monitor mới và đọc IP đã lưu từ lần chạy trước (nếu có)
+func NewPublicIPMonitor(endpoints []string, stunServer string, ipv6 bool, statePath string) *PublicIPMonitor {
+	m := &PublicIPMonitor{
+		Endpoints:  endpoints,
+		STUNServer: stunServer,
+		IPv6:       ipv6,
+		statePath:  statePath,
+		timeout:    5 * time.Second,
+	}
+
+	if data, err := os.ReadFile(statePath); err == nil {
+		if err := json.Unmarshal(data, &m.last); err != nil {
+			log.Printf("⚠️ Cannot parse public IP state %s: %v", statePath, err)
+		}
+	}
+
+	return m
+}
+
+// Last trả về IP public đã biết gần nhất
+func (m *PublicIPMonitor) Last() PublicIP {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+// Resolve lấy IP public hiện tại (không cập nhật trạng thái đã lưu).
+// Khi bật IPv6, chỉ lỗi nếu không lấy được cả IPv4 lẫn IPv6 (mạng chỉ có IPv6 vẫn báo được).
+func (m *PublicIPMonitor) Resolve() (PublicIP, error) {
+	result := PublicIP{UpdatedAt: time.Now()}
+
+	ipv4, err4 := m.resolveFamily("4")
+	if err4 != nil {
+		err4 = i18n.Errorf("publicip.err.ipv4", err4)
+		if !m.IPv6 {
+			return result, err4
+		}
+	}
+	result.IPv4 = ipv4
+
+	if m.IPv6 {
+		ipv6, err6 := m.resolveFamily("6")
+		if err6 != nil {
+			err6 = i18n.Errorf("publicip.err.ipv6", err6)
+			if err4 != nil {
+				return result, errors.Join(err4, err6)
+			}
+			// Nhiều mạng gia đình không có IPv6, không coi là lỗi
+			log.Printf("⚠️ Cannot resolve public IPv6: %v", err6)
+		} else if err4 != nil {
+			log.Printf("⚠️ Cannot resolve public IPv4: %v", err4)
+		}
+		result.IPv6 = ipv6
+	}
+
+	return result, nil
+}
+
+// Check lấy IP public hiện tại, so sánh với giá trị đã lưu và ghi lại nếu thay đổi.
+// Lần kiểm tra đầu tiên (chưa có giá trị cũ) không được tính là thay đổi.
+func (m *PublicIPMonitor) Check() (old, current PublicIP, changed bool, err error) {
+	current, err = m.Resolve()
+	if err != nil {
+		return PublicIP{}, PublicIP{}, false, err
+	}
+
+	m.mu.Lock()
+	old = m.last
+	known := old.IPv4 != "" || old.IPv6 != ""
+	// Một họ địa chỉ tạm thời không lấy được thì giữ giá trị cũ, tránh báo thay đổi giả
+	if current.IPv4 == "" {
+		current.IPv4 = old.IPv4
+	}
+	if m.IPv6 && current.IPv6 == "" {
+		current.IPv6 = old.IPv6
+	}
+	changed = known && !old.Equal(current)
+	m.last = current
+	m.mu.Unlock()
+
+	if !known || changed {
+		if err := m.save(current); err != nil {
+			log.Printf("⚠️ Cannot save public IP state: %v", err)
+		}
+	}
+
+	return old, current, changed, nil
+}
+
+// save ghi IP hiện tại xuống file trạng thái
+func (m *PublicIPMonitor) save(ip PublicIP) error {
+	if m.statePath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(m.statePath), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ip, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.statePath, data, 0o644)
+}
+
+// resolveFamily thử lần lượt STUN rồi các HTTP endpoint cho một họ địa chỉ ("4" hoặc "6")
+func (m *PublicIPMonitor) resolveFamily(family string) (string, error) {
+	var errs []error
+
+	if m.STUNServer != "" {
+		ip, err := stunLookup(m.STUNServer, family, m.timeout)
+		if err == nil {
+			return ip, nil
+		}
+		errs = append(errs, i18n.Errorf("error.source", "stun "+m.STUNServer, err))
+	}
+
+	client := newFamilyHTTPClient(family, m.timeout)
+	for _, endpoint := range m.Endpoints {
+		ip, err := httpLookup(client, endpoint, family)
+		if err == nil {
+			return ip, nil
+		}
+		errs = append(errs, i18n.Errorf("error.source", endpoint, err))
+	}
+
+	if len(errs) == 0 {
+		return "", i18n.Errorf("publicip.err.no_endpoint")
+	}
+	return "", errors.Join(errs...)
+}
+
+// newFamilyHTTPClient tạo HTTP client chỉ kết nối qua IPv4 hoặc IPv6
+func newFamilyHTTPClient(family string, timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "tcp"+family, addr)
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// httpLookup gọi endpoint dạng "what is my IP" và kiểm tra kết quả trả về
+func httpLookup(client *http.Client, endpoint, family string) (string, error) {
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", err
+	}
+
+	return parseFamilyIP(strings.TrimSpace(string(body)), family)
+}
+
+// parseFamilyIP kiểm tra chuỗi là IP hợp lệ thuộc đúng họ địa chỉ
+func parseFamilyIP(s, family string) (string, error) {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return "", i18n.Errorf("publicip.err.not_ip", s)
+	}
+	isV4 := ip.To4() != nil
+	if (family == "4") != isV4 {
+		return "", i18n.Errorf("publicip.err.family", s, family)
+	}
+	return ip.String(), nil
+}
+
+const (
+	stunMagicCookie       = 0x2112A442
+	stunBindingRequest    = 0x0001
+	stunBindingSuccess    = 0x0101
+	stunAttrMappedAddress = 0x0001
+	stunAttrXORMapped     = 0x0020
+)
+
+// stunLookup gửi STUN Binding Request (RFC 5389) và đọc địa chỉ public từ phản hồi
+func stunLookup(server, family string, timeout time.Duration) (string, error) {
+	conn, err := net.DialTimeout("udp"+family, server, timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	// Header 20 bytes: type, length, magic cookie, transaction ID
+	req := make([]byte, 20)
+	binary.BigEndian.PutUint16(req[0:], stunBindingRequest)
+	binary.BigEndian.PutUint32(req[4:], stunMagicCookie)
+	if _, err := rand.Read(req[8:20]); err != nil {
+		return "", err
+	}
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write(req); err != nil {
+		return "", err
+	}
+
+	resp := make([]byte, 1024)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return "", err
+	}
+
+	ip, err := parseSTUNResponse(resp[:n], req[8:20])
+	if err != nil {
+		return "", err
+	}
+	return parseFamilyIP(ip.String(), family)
+}
+
+// parseSTUNResponse đọc XOR-MAPPED-ADDRESS (hoặc MAPPED-ADDRESS) từ Binding Response
+func parseSTUNResponse(resp, txID []byte) (net.IP, error) {
+	if len(resp) < 20 {
+		return nil, i18n.Errorf("publicip.err.stun_short")
+	}
+	if binary.BigEndian.Uint16(resp[0:]) != stunBindingSuccess {
+		return nil, i18n.Errorf("publicip.err.stun_type", binary.BigEndian.Uint16(resp[0:]))
+	}
+	if string(resp[8:20]) != string(txID) {
+		return nil, i18n.Errorf("publicip.err.stun_transaction")
+	}
+
+	length := int(binary.BigEndian.Uint16(resp[2:]))
+	attrs := resp[20:]
+	if len(attrs) < length {
+		return nil, i18n.Errorf("publicip.err.stun_truncated")
+	}
+	attrs = attrs[:length]
+
+	var mapped net.IP
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:]))
+		if len(attrs) < 4+attrLen {
+			break
+		}
+		value := attrs[4 : 4+attrLen]
+
+		switch attrType {
+		case stunAttrXORMapped:
+			if ip := decodeSTUNAddress(value, resp[4:20]); ip != nil {
+				return ip, nil
+			}
+		case stunAttrMappedAddress:
+			mapped = decodeSTUNAddress(value, nil)
+		}
+
+		// Attribute được padding tới bội số của 4
+		attrs = attrs[4+(attrLen+3)&^3:]
+	}
+
+	if mapped != nil {
+		return mapped, nil
+	}
+	return nil, i18n.Errorf("publicip.err.stun_no_address")
+}
+
+// decodeSTUNAddress giải mã attribute địa chỉ; xorKey = magic cookie + transaction ID (nil nếu không XOR)
+func decodeSTUNAddress(value, xorKey []byte) net.IP {
+	if len(value) < 4 {
+		return nil
+	}
+
+	var size int
+	switch value[1] {
+	case 0x01:
+		size = net.IPv4len
+	case 0x02:
+		size = net.IPv6len
+	default:
+		return nil
+	}
+	if len(value) < 4+size {
+		return nil
+	}
+
+	ip := make(net.IP, size)
+	copy(ip, value[4:4+size])
+	if xorKey != nil {
+		for i := range ip {
+			ip[i] ^= xorKey[i]
+		}
+	}
+	return ip
+}
+
+// StartPublicIPMonitoring kiểm tra IP public định kỳ và gọi callback khi IP thay đổi, dừng khi ctx bị huỷ
+func StartPublicIPMonitoring(ctx context.Context, monitor *PublicIPMonitor, interval time.Duration, onChange func(old, current PublicIP)) {
+	log.Printf("🌍 Public IP monitoring started (interval: %v)", interval)
+
+	check := func() {
+		old, current, changed, err := monitor.Check()
+		if err != nil {
+			log.Printf("Error checking public IP: %v", err)
+			return
+		}
+		if changed {
+			log.Printf("🌍 Public IP changed: %s -> %s", old.IPv4, current.IPv4)
+			onChange(old, current)
+		}
+	}
+
+	check()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("🌍 Public IP monitoring stopped")
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// FormatPublicIPChange format thông báo khi IP public thay đổi
+func FormatPublicIPChange(p *i18n.Printer, old, current PublicIP) string {
+	var sb strings.Builder
+	sb.WriteString(p.T("publicip.changed.title"))
+
+	if old.IPv4 != current.IPv4 {
+		sb.WriteString(p.T("publicip.changed.ipv4", valueOrNA(old.IPv4), valueOrNA(current.IPv4)))
+	}
+	if old.IPv6 != current.IPv6 {
+		sb.WriteString(p.T("publicip.changed.ipv6", valueOrNA(old.IPv6), valueOrNA(current.IPv6)))
+	}
+	sb.WriteString(p.T("publicip.changed.since", p.DateTime(old.UpdatedAt)))
+
+	sb.WriteString("\n\n" + p.T("common.time_footer", p.DateTime(current.UpdatedAt)))
+	return sb.String()
+}
+
+// valueOrNA trả về "N/A" cho chuỗi rỗng
+func valueOrNA(s string) string {
+	if s == "" {
+		return "N/A"
+	}
+	return s
+}
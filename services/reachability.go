@@ -0,0 +1,351 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"pi-monitor/i18n"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// Các phương thức kiểm tra host online
+const (
+	ProbeICMP = "icmp"
+	ProbeARP  = "arp"
+	ProbeTCP  = "tcp"
+)
+
+// DefaultProbeMethods là thứ tự phương thức mặc định
+var DefaultProbeMethods = []string{ProbeICMP, ProbeARP, ProbeTCP}
+
+// DefaultProbePorts là các port TCP mặc định: SMB (445), HTTP (80), RDP (3389), SSH (22)
+var DefaultProbePorts = []int{445, 80, 3389, 22}
+
+// ReachabilityResult là kết quả kiểm tra host
+type ReachabilityResult struct {
+	Online  bool
+	Method  string        // Phương thức thành công (vd: icmp, arp, tcp/3389)
+	Latency time.Duration // Thời gian phản hồi
+	Err     error         // Lỗi của các phương thức khi host offline
+}
+
+// String mô tả ngắn gọn kết quả (vd: "icmp, 3ms")
+func (r ReachabilityResult) String() string {
+	if !r.Online {
+		return "offline"
+	}
+	return fmt.Sprintf("%s, %s", r.Method, formatLatency(r.Latency))
+}
+
+// Prober là một cách kiểm tra host có online không
+type Prober interface {
+	// Name trả về tên phương thức (icmp, arp, tcp/<port>)
+	Name() string
+	// Probe trả về latency nếu host phản hồi
+	Probe(ctx context.Context, host, mac string) (time.Duration, error)
+}
+
+// ReachabilityChecker chạy song song các Prober và trả về phương thức phản hồi nhanh nhất
+type ReachabilityChecker struct {
+	Probers []Prober
+	Timeout time.Duration
+}
+
+// NewReachabilityChecker tạo checker từ danh sách phương thức (icmp, arp, tcp) và các port TCP.
+// Danh sách rỗng dùng giá trị mặc định.
+func NewReachabilityChecker(methods []string, ports []int) *ReachabilityChecker {
+	if len(methods) == 0 {
+		methods = DefaultProbeMethods
+	}
+	if len(ports) == 0 {
+		ports = DefaultProbePorts
+	}
+
+	checker := &ReachabilityChecker{Timeout: 2 * time.Second}
+	for _, m := range methods {
+		switch strings.ToLower(strings.TrimSpace(m)) {
+		case ProbeICMP:
+			checker.Probers = append(checker.Probers, ICMPProber{})
+		case ProbeARP:
+			checker.Probers = append(checker.Probers, ARPProber{})
+		case ProbeTCP:
+			for _, port := range ports {
+				checker.Probers = append(checker.Probers, TCPProber{Port: port})
+			}
+		}
+	}
+	return checker
+}
+
+// Check kiểm tra host (IP/hostname) và/hoặc MAC có online không
+func (c *ReachabilityChecker) Check(host, mac string) ReachabilityResult {
+	if host == "" && mac == "" {
+		return ReachabilityResult{Err: i18n.Errorf("reach.err.no_target")}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+
+	type probeResult struct {
+		name    string
+		latency time.Duration
+		err     error
+	}
+
+	results := make(chan probeResult, len(c.Probers))
+	for _, p := range c.Probers {
+		go func(p Prober) {
+			latency, err := p.Probe(ctx, host, mac)
+			results <- probeResult{name: p.Name(), latency: latency, err: err}
+		}(p)
+	}
+
+	var errs []error
+	for range c.Probers {
+		r := <-results
+		if r.err == nil {
+			return ReachabilityResult{Online: true, Method: r.name, Latency: r.latency}
+		}
+		errs = append(errs, i18n.Errorf("reach.err.probe", r.name, r.err))
+	}
+
+	return ReachabilityResult{Err: errors.Join(errs...)}
+}
+
+// ICMPProber gửi ICMP echo request (không cần root nếu kernel cho phép qua net.ipv4.ping_group_range)
+type ICMPProber struct{}
+
+// Name trả về tên phương thức
+func (ICMPProber) Name() string { return ProbeICMP }
+
+// Probe gửi một gói ICMP echo và chờ echo reply
+func (ICMPProber) Probe(ctx context.Context, host, _ string) (time.Duration, error) {
+	if host == "" {
+		return 0, i18n.Errorf("reach.err.no_host")
+	}
+
+	ip, err := resolveHost(ctx, host)
+	if err != nil {
+		return 0, err
+	}
+
+	// Socket ICMP datagram (unprivileged), dự phòng raw socket khi chạy bằng root
+	network, rawNetwork, listenAddr, proto := "udp4", "ip4:icmp", "0.0.0.0", 1
+	var echoType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if ip.To4() == nil {
+		network, rawNetwork, listenAddr, proto = "udp6", "ip6:ipv6-icmp", "::", 58
+		echoType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	conn, err := icmp.ListenPacket(network, listenAddr)
+	privileged := false
+	if err != nil {
+		conn, err = icmp.ListenPacket(rawNetwork, listenAddr)
+		if err != nil {
+			return 0, i18n.Errorf("reach.err.icmp_socket", err)
+		}
+		privileged = true
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	id := os.Getpid() & 0xffff
+	seq := int(time.Now().UnixNano() & 0xffff)
+	msg := icmp.Message{
+		Type: echoType,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("pi-monitor")},
+	}
+	packet, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var dst net.Addr = &net.UDPAddr{IP: ip}
+	if privileged {
+		dst = &net.IPAddr{IP: ip}
+	}
+
+	start := time.Now()
+	if _, err := conn.WriteTo(packet, dst); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			return 0, err
+		}
+
+		reply, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil || reply.Type != replyType {
+			continue
+		}
+		echo, ok := reply.Body.(*icmp.Echo)
+		// Với socket datagram, kernel tự đặt ID nên chỉ so sánh Seq
+		if !ok || echo.Seq != seq || (privileged && echo.ID != id) {
+			continue
+		}
+		if !sameIP(peer, ip) {
+			continue
+		}
+		return time.Since(start), nil
+	}
+}
+
+// ARPProber kiểm tra MAC của PC có trong bảng neighbor của kernel ở trạng thái REACHABLE.
+// Gửi một gói UDP nhỏ đến host trước để kernel xác nhận lại ARP entry.
+type ARPProber struct{}
+
+// Name trả về tên phương thức
+func (ARPProber) Name() string { return ProbeARP }
+
+// Probe tìm MAC trong bảng neighbor
+func (ARPProber) Probe(ctx context.Context, host, mac string) (time.Duration, error) {
+	if mac == "" {
+		return 0, i18n.Errorf("reach.err.no_mac")
+	}
+	normalized, err := NormalizeMAC(mac)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	if host != "" {
+		if ip, err := resolveHost(ctx, host); err == nil {
+			if conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: ip, Port: 9}); err == nil {
+				conn.Write([]byte{0})
+				conn.Close()
+			}
+		}
+	}
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		state, err := neighborState(normalized)
+		if err != nil {
+			return 0, err
+		}
+		if state == "REACHABLE" {
+			return time.Since(start), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if state == "" {
+				return 0, i18n.Errorf("reach.err.arp_missing")
+			}
+			return 0, i18n.Errorf("reach.err.arp_state", state)
+		case <-ticker.C:
+		}
+	}
+}
+
+// neighborState trả về trạng thái neighbor (REACHABLE, STALE, FAILED...) của MAC.
+// Dùng `ip neigh show`; nếu không có lệnh ip thì đọc /proc/net/arp. File này chỉ cho biết entry có tồn tại
+// (entry vẫn còn vài phút sau khi PC tắt) nên trả về STALE, không coi là online.
+func neighborState(mac string) (string, error) {
+	out, err := exec.Command("ip", "neigh", "show").Output()
+	if err != nil {
+		entries, err := ReadARPTable()
+		if err != nil {
+			return "", err
+		}
+		for _, e := range entries {
+			if e.MAC == mac {
+				return "STALE", nil
+			}
+		}
+		return "", nil
+	}
+
+	// Dạng: 192.168.1.10 dev eth0 lladdr aa:bb:cc:dd:ee:ff REACHABLE
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] == "lladdr" && strings.EqualFold(fields[i+1], mac) {
+				return fields[len(fields)-1], nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// TCPProber thử kết nối TCP đến một port. Kết nối bị từ chối (RST) cũng nghĩa là host đang bật.
+type TCPProber struct {
+	Port int
+}
+
+// Name trả về tên phương thức
+func (p TCPProber) Name() string { return ProbeTCP + "/" + strconv.Itoa(p.Port) }
+
+// Probe kết nối TCP đến host:port
+func (p TCPProber) Probe(ctx context.Context, host, _ string) (time.Duration, error) {
+	if host == "" {
+		return 0, i18n.Errorf("reach.err.no_host")
+	}
+
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(p.Port)))
+	if err == nil {
+		conn.Close()
+		return time.Since(start), nil
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return time.Since(start), nil
+	}
+	return 0, err
+}
+
+// resolveHost phân giải hostname thành IP
+func resolveHost(ctx context.Context, host string) (net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip, nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, i18n.Errorf("reach.err.resolve", host)
+	}
+	return addrs[0].IP, nil
+}
+
+// sameIP so sánh địa chỉ peer trả về từ socket với IP đích
+func sameIP(addr net.Addr, ip net.IP) bool {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP.Equal(ip)
+	case *net.IPAddr:
+		return a.IP.Equal(ip)
+	}
+	return false
+}
+
+// formatLatency format latency dạng ngắn gọn (vd: 3ms, 0.4ms)
+func formatLatency(d time.Duration) string {
+	if d < time.Millisecond {
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeProber là Prober giả trả về latency/lỗi cố định sau delay, hoặc chờ đến khi ctx hết hạn nếu block
+type fakeProber struct {
+	name    string
+	delay   time.Duration
+	latency time.Duration
+	err     error
+	block   bool
+}
+
+func (p fakeProber) Name() string { return p.name }
+
+func (p fakeProber) Probe(ctx context.Context, _, _ string) (time.Duration, error) {
+	if p.block {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-time.After(p.delay):
+	}
+	return p.latency, p.err
+}
+
+// listenLocal mở listener TCP trên port ngẫu nhiên của 127.0.0.1 và trả về port
+func listenLocal(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+// closedPort trả về một port vừa được giải phóng trên 127.0.0.1 (không còn ai listen)
+func closedPort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	return port
+}
+
+func TestTCPProber(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		port    int
+		wantErr bool
+	}{
+		{name: "listener", host: "127.0.0.1", port: listenLocal(t)},
+		// Kết nối bị từ chối (RST) nghĩa là host đang bật
+		{name: "closed port", host: "127.0.0.1", port: closedPort(t)},
+		{name: "no host", host: "", port: 80, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			latency, err := TCPProber{Port: tt.port}.Probe(ctx, tt.host, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Probe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && latency <= 0 {
+				t.Errorf("Probe() latency = %v, want > 0", latency)
+			}
+		})
+	}
+}
+
+func TestTCPProberName(t *testing.T) {
+	if got := (TCPProber{Port: 3389}).Name(); got != "tcp/3389" {
+		t.Errorf("Name() = %q, want %q", got, "tcp/3389")
+	}
+}
+
+func TestTCPProberCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := (TCPProber{Port: listenLocal(t)}).Probe(ctx, "127.0.0.1", ""); err == nil {
+		t.Fatal("Probe() with cancelled context succeeded")
+	}
+}
+
+func TestReachabilityCheckerTCP(t *testing.T) {
+	port := listenLocal(t)
+	checker := &ReachabilityChecker{
+		Probers: []Prober{TCPProber{Port: port}},
+		Timeout: 2 * time.Second,
+	}
+
+	result := checker.Check("127.0.0.1", "")
+	if !result.Online {
+		t.Fatalf("Check() offline: %v", result.Err)
+	}
+	if want := (TCPProber{Port: port}).Name(); result.Method != want {
+		t.Errorf("Method = %q, want %q", result.Method, want)
+	}
+	if result.Latency <= 0 || result.Latency > checker.Timeout {
+		t.Errorf("Latency = %v, want in (0, %v]", result.Latency, checker.Timeout)
+	}
+}
+
+func TestReachabilityCheckerFastestMethod(t *testing.T) {
+	checker := &ReachabilityChecker{
+		Probers: []Prober{
+			fakeProber{name: "slow", delay: 200 * time.Millisecond, latency: 200 * time.Millisecond},
+			fakeProber{name: "fast", latency: 3 * time.Millisecond},
+			fakeProber{name: "broken", err: errors.New("boom")},
+		},
+		Timeout: time.Second,
+	}
+
+	result := checker.Check("host", "")
+	if !result.Online || result.Err != nil {
+		t.Fatalf("Check() = %+v, want online", result)
+	}
+	if result.Method != "fast" || result.Latency != 3*time.Millisecond {
+		t.Errorf("Check() = %s/%v, want fast/3ms", result.Method, result.Latency)
+	}
+	if got := result.String(); got != "fast, 3ms" {
+		t.Errorf("String() = %q, want %q", got, "fast, 3ms")
+	}
+}
+
+func TestReachabilityCheckerTimeout(t *testing.T) {
+	checker := &ReachabilityChecker{
+		Probers: []Prober{fakeProber{name: "hang", block: true}},
+		Timeout: 100 * time.Millisecond,
+	}
+
+	start := time.Now()
+	result := checker.Check("host", "")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Check() took %v, want about %v", elapsed, checker.Timeout)
+	}
+	if result.Online {
+		t.Fatal("Check() online, want offline")
+	}
+	if !errors.Is(result.Err, context.DeadlineExceeded) {
+		t.Errorf("Err = %v, want DeadlineExceeded", result.Err)
+	}
+}
+
+func TestReachabilityCheckerAllFail(t *testing.T) {
+	errICMP := errors.New("no reply")
+	errARP := errors.New("not in table")
+	checker := &ReachabilityChecker{
+		Probers: []Prober{
+			fakeProber{name: ProbeICMP, err: errICMP},
+			fakeProber{name: ProbeARP, err: errARP},
+			TCPProber{Port: 80}, // Không có host -> lỗi ngay
+		},
+		Timeout: time.Second,
+	}
+
+	result := checker.Check("", "aa:bb:cc:dd:ee:ff")
+	if result.Online {
+		t.Fatal("Check() online, want offline")
+	}
+	if got := result.String(); got != "offline" {
+		t.Errorf("String() = %q, want offline", got)
+	}
+	if !errors.Is(result.Err, errICMP) || !errors.Is(result.Err, errARP) {
+		t.Errorf("Err = %v, want joined prober errors", result.Err)
+	}
+	for _, name := range []string{"icmp: no reply", "arp: not in table", "tcp/80: "} {
+		if !strings.Contains(result.Err.Error(), name) {
+			t.Errorf("Err = %q, missing %q", result.Err, name)
+		}
+	}
+}
+
+func TestReachabilityCheckerNoTarget(t *testing.T) {
+	result := NewReachabilityChecker(nil, nil).Check("", "")
+	if result.Online || result.Err == nil {
+		t.Errorf("Check() = %+v, want error", result)
+	}
+}
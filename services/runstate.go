@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/host"
+
+	"pi-monitor/i18n"
+)
+
+// bootTimeTolerance là độ lệch boot time vẫn coi là cùng một lần khởi động.
+// Boot time tính từ đồng hồ hệ thống nên bị lệch khi Pi (không có RTC) đồng bộ giờ qua NTP.
+const bootTimeTolerance = 2 * time.Minute
+
+// StartupKind là lý do bot khởi động
+type StartupKind string
+
+const (
+	StartupFirst   StartupKind = "first"   // Lần đầu chạy, chưa có trạng thái lần chạy trước
+	StartupRestart StartupKind = "restart" // Khởi động lại sau khi dừng bình thường (redeploy, docker restart)
+	StartupCrash   StartupKind = "crash"   // Lần chạy trước không dừng bình thường (crash, SIGKILL, OOM)
+	StartupReboot  StartupKind = "reboot"  // Pi vừa khởi động lại
+)
+
+// RunState là trạng thái một lần chạy của bot, lưu lại để lần khởi động sau biết lần chạy trước kết thúc thế nào
+type RunState struct {
+	BootID    string    `json:"boot_id,omitempty"` // /proc/sys/kernel/random/boot_id, đổi sau mỗi lần Pi khởi động
+	BootTime  time.Time `json:"boot_time"`
+	StartedAt time.Time `json:"started_at"`
+	LastSeen  time.Time `json:"last_seen"`            // Cập nhật định kỳ khi bot đang chạy
+	StoppedAt time.Time `json:"stopped_at,omitempty"` // Thời điểm dừng bình thường, zero = đang chạy hoặc crash
+}
+
+// Startup là thông tin lần khởi động hiện tại so với lần chạy trước
+type Startup struct {
+	Kind     StartupKind
+	Time     time.Time
+	Previous RunState // Lần chạy trước, zero với StartupFirst
+	Clean    bool     // Lần chạy trước dừng bình thường (với StartupReboot: Pi được tắt/khởi động lại đúng cách)
+}
+
+// Severity trả về mức độ của thông báo khởi động: khởi động lại bình thường là info
+func (s Startup) Severity() Severity {
+	switch s.Kind {
+	case StartupCrash, StartupReboot:
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// LastSeen trả về thời điểm cuối cùng bot lần trước còn chạy
+func (s Startup) LastSeen() time.Time {
+	if s.Clean {
+		return s.Previous.StoppedAt
+	}
+	return s.Previous.LastSeen
+}
+
+// PreviousUptime trả về thời gian chạy của lần chạy trước
+func (s Startup) PreviousUptime() time.Duration {
+	return s.LastSeen().Sub(s.Previous.StartedAt)
+}
+
+// Downtime trả về thời gian bot không chạy
+func (s Startup) Downtime() time.Duration {
+	return s.Time.Sub(s.LastSeen())
+}
+
+// RunTracker ghi trạng thái lần chạy hiện tại: thời điểm khởi động, lần cuối còn chạy và dừng bình thường
+type RunTracker struct {
+	mu    sync.Mutex
+	path  string
+	state RunState
+}
+
+// NewRunTracker đọc trạng thái lần chạy trước từ path, so sánh với lần khởi động hiện tại
+// rồi ghi trạng thái mới (chưa dừng bình thường cho đến khi gọi MarkStopped)
+func NewRunTracker(path string) (*RunTracker, Startup) {
+	now := time.Now()
+	t := &RunTracker{
+		path: path,
+		state: RunState{
+			BootID:    readBootID(),
+			BootTime:  readBootTime(),
+			StartedAt: now,
+			LastSeen:  now,
+		},
+	}
+
+	startup := Startup{Kind: StartupFirst, Time: now}
+	if data, err := os.ReadFile(path); err == nil {
+		var prev RunState
+		if err := json.Unmarshal(data, &prev); err != nil {
+			log.Printf("⚠️ Cannot parse run state %s: %v", path, err)
+		} else {
+			startup = Startup{Kind: t.state.startupKind(prev), Time: now, Previous: prev, Clean: !prev.StoppedAt.IsZero()}
+		}
+	}
+
+	t.mu.Lock()
+	if err := t.save(); err != nil {
+		log.Printf("⚠️ Cannot save run state: %v", err)
+	}
+	t.mu.Unlock()
+	return t, startup
+}
+
+// startupKind so sánh lần chạy hiện tại với lần chạy trước
+func (s RunState) startupKind(prev RunState) StartupKind {
+	rebooted := false
+	if s.BootID != "" && prev.BootID != "" {
+		rebooted = s.BootID != prev.BootID
+	} else if !s.BootTime.IsZero() && !prev.BootTime.IsZero() {
+		d := s.BootTime.Sub(prev.BootTime)
+		rebooted = d > bootTimeTolerance || d < -bootTimeTolerance
+	}
+
+	switch {
+	case rebooted:
+		return StartupReboot
+	case prev.StoppedAt.IsZero():
+		return StartupCrash
+	default:
+		return StartupRestart
+	}
+}
+
+// Touch ghi lại thời điểm bot còn chạy
+func (t *RunTracker) Touch() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.state.LastSeen = time.Now()
+	return t.save()
+}
+
+// MarkStopped ghi lại bot dừng bình thường, gọi khi shutdown xong
+func (t *RunTracker) MarkStopped() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.state.LastSeen = now
+	t.state.StoppedAt = now
+	return t.save()
+}
+
+// save ghi trạng thái xuống file (gọi khi đang giữ lock)
+func (t *RunTracker) save() error {
+	if t.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(t.path, data, 0o644)
+}
+
+// StartRunTracking cập nhật thời điểm bot còn chạy định kỳ, dừng khi ctx bị huỷ
+func StartRunTracking(ctx context.Context, tracker *RunTracker, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := tracker.Touch(); err != nil {
+				log.Printf("⚠️ Cannot save run state: %v", err)
+			}
+		}
+	}
+}
+
+// readBootID đọc ID lần khởi động của kernel (chung giữa host và container)
+func readBootID() string {
+	data, err := os.ReadFile("/proc/sys/kernel/random/boot_id")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// readBootTime trả về thời điểm Pi khởi động
+func readBootTime() time.Time {
+	boot, err := host.BootTime()
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(int64(boot), 0)
+}
+
+// FormatStartup format thông báo khi bot khởi động
+func FormatStartup(p *i18n.Printer, s Startup, botName string) string {
+	var sb strings.Builder
+
+	switch s.Kind {
+	case StartupReboot:
+		sb.WriteString(p.T("startup.reboot"))
+	case StartupCrash:
+		sb.WriteString(p.T("startup.crash"))
+	case StartupRestart:
+		sb.WriteString(p.T("startup.restart"))
+	default:
+		sb.WriteString(p.T("startup.first"))
+	}
+
+	sb.WriteString(fmt.Sprintf("🤖 Bot: @%s\n", i18n.Escape(botName)))
+	if s.Kind == StartupReboot && !s.Previous.BootTime.IsZero() {
+		sb.WriteString(p.T("startup.pi_uptime", p.DurationShort(s.LastSeen().Sub(s.Previous.BootTime))))
+	}
+	if s.Kind != StartupFirst {
+		sb.WriteString(p.T("startup.previous_run", p.DurationShort(s.PreviousUptime())))
+		sb.WriteString(p.T("startup.last_seen", p.DateTime(s.LastSeen())))
+		sb.WriteString(p.T("startup.downtime", p.DurationShort(s.Downtime())))
+	}
+	if s.Kind == StartupReboot && !s.Clean {
+		sb.WriteString(p.T("startup.unclean"))
+	}
+
+	sb.WriteString("\n" + p.T("common.time_footer", p.DateTime(s.Time)) + "\n\n" + p.T("startup.help_hint"))
+	return sb.String()
+}
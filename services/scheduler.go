@@ -0,0 +1,235 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"pi-monitor/i18n"
+)
+
+// ScheduledJob là một lệnh được chạy theo lịch cron
+type ScheduledJob struct {
+	ID        int       `json:"id"`
+	Spec      string    `json:"spec"`    // Biểu thức cron 5 trường (vd: 30 8 * * 1-5) hoặc @daily, @every 1h
+	Command   string    `json:"command"` // Lệnh cần chạy (vd: wake office-pc)
+	ChatID    int64     `json:"chat_id"` // Chat nhận kết quả
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	LastRun   time.Time `json:"last_run,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+
+	Next time.Time `json:"-"` // Lần chạy tiếp theo (chỉ dùng khi hiển thị)
+}
+
+// Scheduler chạy các job theo lịch cron và lưu danh sách job xuống file
+type Scheduler struct {
+	mu       sync.Mutex
+	path     string
+	cron     *cron.Cron
+	jobs     map[int]*ScheduledJob
+	entries  map[int]cron.EntryID
+	nextID   int
+	run      func(ScheduledJob) error
+	location *time.Location
+}
+
+// NewScheduler tạo scheduler mới và nạp các job đã lưu (nếu có).
+// run được gọi mỗi khi đến giờ chạy job.
+func NewScheduler(path string, loc *time.Location, run func(ScheduledJob) error) *Scheduler {
+	s := &Scheduler{
+		path:     path,
+		cron:     cron.New(cron.WithLocation(loc)),
+		jobs:     make(map[int]*ScheduledJob),
+		entries:  make(map[int]cron.EntryID),
+		nextID:   1,
+		run:      run,
+		location: loc,
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		var jobs []*ScheduledJob
+		if err := json.Unmarshal(data, &jobs); err != nil {
+			log.Printf("⚠️ Cannot parse schedules %s: %v", path, err)
+		}
+		for _, job := range jobs {
+			if err := s.schedule(job); err != nil {
+				log.Printf("⚠️ Skipping job #%d (%s): %v", job.ID, job.Spec, err)
+				continue
+			}
+			s.jobs[job.ID] = job
+			if job.ID >= s.nextID {
+				s.nextID = job.ID + 1
+			}
+		}
+	}
+
+	return s
+}
+
+// Start bắt đầu chạy các job ở background
+func (s *Scheduler) Start() {
+	s.cron.Start()
+	log.Printf("⏰ Scheduler started (%d job(s), timezone: %s)", len(s.jobs), s.location)
+}
+
+// Stop dừng scheduler và chờ các job đang chạy kết thúc
+func (s *Scheduler) Stop() {
+	<-s.cron.Stop().Done()
+}
+
+// ParseCronSpec kiểm tra biểu thức cron hợp lệ
+func ParseCronSpec(spec string) error {
+	_, err := cron.ParseStandard(spec)
+	return err
+}
+
+// Add thêm job mới
+func (s *Scheduler) Add(spec, command string, chatID, userID int64) (ScheduledJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := &ScheduledJob{
+		ID:        s.nextID,
+		Spec:      spec,
+		Command:   command,
+		ChatID:    chatID,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.schedule(job); err != nil {
+		return ScheduledJob{}, i18n.Errorf("schedule.err.cron", err)
+	}
+
+	s.jobs[job.ID] = job
+	// Không lưu được thì huỷ job, tránh job chạy nhưng mất sau khi khởi động lại
+	if err := s.save(); err != nil {
+		s.cron.Remove(s.entries[job.ID])
+		delete(s.entries, job.ID)
+		delete(s.jobs, job.ID)
+		return ScheduledJob{}, i18n.Errorf("schedule.err.save", err)
+	}
+	s.nextID++
+
+	result := *job
+	result.Next = s.cron.Entry(s.entries[job.ID]).Next
+	return result, nil
+}
+
+// Remove xoá job theo ID
+func (s *Scheduler) Remove(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		return i18n.Errorf("schedule.err.not_found", id)
+	}
+
+	s.cron.Remove(s.entries[id])
+	delete(s.entries, id)
+	delete(s.jobs, id)
+
+	if err := s.save(); err != nil {
+		return i18n.Errorf("schedule.err.save", err)
+	}
+	return nil
+}
+
+// Get trả về job theo ID
+func (s *Scheduler) Get(id int) (ScheduledJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return ScheduledJob{}, false
+	}
+	return *job, true
+}
+
+// List trả về danh sách job, sắp xếp theo ID
+func (s *Scheduler) List() []ScheduledJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]ScheduledJob, 0, len(s.jobs))
+	for id, job := range s.jobs {
+		j := *job
+		j.Next = s.cron.Entry(s.entries[id]).Next
+		jobs = append(jobs, j)
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].ID < jobs[k].ID })
+	return jobs
+}
+
+// Location trả về timezone của scheduler
+func (s *Scheduler) Location() *time.Location {
+	return s.location
+}
+
+// schedule đăng ký job với cron (gọi khi đang giữ lock hoặc lúc khởi tạo)
+func (s *Scheduler) schedule(job *ScheduledJob) error {
+	id := job.ID
+	entryID, err := s.cron.AddFunc(job.Spec, func() { s.execute(id) })
+	if err != nil {
+		return err
+	}
+	s.entries[id] = entryID
+	return nil
+}
+
+// execute chạy job và lưu kết quả lần chạy gần nhất
+func (s *Scheduler) execute(id int) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	snapshot := *job
+	s.mu.Unlock()
+
+	log.Printf("⏰ Running job #%d: %s", snapshot.ID, snapshot.Command)
+	err := s.run(snapshot)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job.LastRun = time.Now()
+	job.LastError = ""
+	if err != nil {
+		job.LastError = err.Error()
+		log.Printf("❌ Job #%d failed: %v", snapshot.ID, err)
+	}
+	if err := s.save(); err != nil {
+		log.Printf("⚠️ Cannot save schedules: %v", err)
+	}
+}
+
+// save ghi danh sách job xuống file (gọi khi đang giữ lock)
+func (s *Scheduler) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	jobs := make([]*ScheduledJob, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].ID < jobs[k].ID })
+
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
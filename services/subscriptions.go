@@ -0,0 +1,233 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pi-monitor/i18n"
+)
+
+// NotifyType là loại thông báo, dùng để chọn chat nhận thông báo
+type NotifyType string
+
+const (
+	NotifySystem  NotifyType = "system"  // Cảnh báo CPU, RAM, Disk, nhiệt độ, Wi-Fi
+	NotifyDevices NotifyType = "devices" // Thiết bị lạ trong mạng LAN
+	NotifyPower   NotifyType = "power"   // PC bật/tắt
+	NotifyIP      NotifyType = "ip"      // IP public thay đổi
+	NotifyConfig  NotifyType = "config"  // Reload cấu hình
+)
+
+// NotifyTypes là tất cả loại thông báo (tên hiển thị nằm trong catalog i18n với key "notify.<loại>")
+var NotifyTypes = []NotifyType{NotifySystem, NotifyDevices, NotifyPower, NotifyIP, NotifyConfig}
+
+// ParseNotifyType đọc loại thông báo từ chuỗi
+func ParseNotifyType(s string) (NotifyType, error) {
+	t := NotifyType(strings.ToLower(strings.TrimSpace(s)))
+	for _, v := range NotifyTypes {
+		if v == t {
+			return t, nil
+		}
+	}
+	return "", i18n.Errorf("notify.err.type", s)
+}
+
+// Severity là mức độ nghiêm trọng của thông báo
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// severityRanks là thứ tự của các mức độ
+var severityRanks = map[Severity]int{SeverityInfo: 1, SeverityWarning: 2, SeverityCritical: 3}
+
+// ParseSeverity đọc mức độ từ chuỗi
+func ParseSeverity(s string) (Severity, error) {
+	sev := Severity(strings.ToLower(strings.TrimSpace(s)))
+	if severityRanks[sev] == 0 {
+		return "", i18n.Errorf("notify.err.severity", s)
+	}
+	return sev, nil
+}
+
+// AtLeast kiểm tra mức độ s có >= min không
+func (s Severity) AtLeast(min Severity) bool {
+	return severityRanks[s] >= severityRanks[min]
+}
+
+// Destination là nơi nhận thông báo: chat riêng, group, channel hoặc một topic trong forum group
+type Destination struct {
+	ChatID   int64
+	ThreadID int // message_thread_id của forum topic, 0 = chat chính
+}
+
+// Subscription là đăng ký nhận thông báo của một chat
+type Subscription struct {
+	ChatID      int64        `json:"chat_id"`
+	ThreadID    int          `json:"thread_id,omitempty"`
+	Title       string       `json:"title,omitempty"` // Tên group/channel (chỉ để hiển thị)
+	Types       []NotifyType `json:"types"`           // Rỗng = không nhận thông báo nào
+	MinSeverity Severity     `json:"min_severity"`
+	AddedBy     int64        `json:"added_by"`
+	AddedAt     time.Time    `json:"added_at"`
+}
+
+// Wants kiểm tra chat có muốn nhận thông báo loại t, mức độ sev không
+func (s Subscription) Wants(t NotifyType, sev Severity) bool {
+	if !sev.AtLeast(s.MinSeverity) {
+		return false
+	}
+	for _, v := range s.Types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Destination trả về nơi nhận thông báo của đăng ký
+func (s Subscription) Destination() Destination {
+	return Destination{ChatID: s.ChatID, ThreadID: s.ThreadID}
+}
+
+// SubscriptionStore lưu các đăng ký nhận thông báo xuống file (vd: data/subscriptions.json)
+type SubscriptionStore struct {
+	mu   sync.Mutex
+	path string
+	subs map[Destination]*Subscription
+}
+
+// NewSubscriptionStore tạo store và nạp các đăng ký đã lưu (nếu có)
+func NewSubscriptionStore(path string) *SubscriptionStore {
+	s := &SubscriptionStore{path: path, subs: make(map[Destination]*Subscription)}
+
+	if data, err := os.ReadFile(path); err == nil {
+		var subs []*Subscription
+		if err := json.Unmarshal(data, &subs); err != nil {
+			log.Printf("⚠️ Cannot parse subscriptions %s: %v", path, err)
+		}
+		for _, sub := range subs {
+			s.subs[sub.Destination()] = sub
+		}
+	}
+	return s
+}
+
+// Set thêm hoặc thay đăng ký của một chat
+func (s *SubscriptionStore) Set(sub Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.subs[sub.Destination()]; ok {
+		sub.AddedAt = old.AddedAt
+	}
+	if sub.AddedAt.IsZero() {
+		sub.AddedAt = time.Now()
+	}
+	s.subs[sub.Destination()] = &sub
+	return s.save()
+}
+
+// Remove xoá đăng ký của một chat
+func (s *SubscriptionStore) Remove(dest Destination) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subs[dest]; !ok {
+		return i18n.Errorf("notify.err.not_subscribed", dest.ChatID)
+	}
+	delete(s.subs, dest)
+	return s.save()
+}
+
+// Get trả về đăng ký của một chat
+func (s *SubscriptionStore) Get(dest Destination) (Subscription, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subs[dest]
+	if !ok {
+		return Subscription{}, false
+	}
+	return *sub, true
+}
+
+// List trả về tất cả đăng ký, sắp xếp theo chat
+func (s *SubscriptionStore) List() []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subs := make([]Subscription, 0, len(s.subs))
+	for _, sub := range s.sorted() {
+		subs = append(subs, *sub)
+	}
+	return subs
+}
+
+// Recipients trả về nơi nhận thông báo loại t, mức độ sev.
+// Người dùng (users) nhận tất cả thông báo qua chat riêng trừ khi đã đổi bằng /subscribe;
+// group/channel chỉ nhận khi đã đăng ký. Đăng ký chat riêng của người không còn quyền bị bỏ qua.
+func (s *SubscriptionStore) Recipients(users []int64, t NotifyType, sev Severity) []Destination {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	allowed := make(map[int64]bool, len(users))
+	var dests []Destination
+	for _, id := range users {
+		allowed[id] = true
+		if _, ok := s.subs[Destination{ChatID: id}]; !ok {
+			dests = append(dests, Destination{ChatID: id})
+		}
+	}
+
+	for _, sub := range s.sorted() {
+		// Chat riêng có ID dương, group/channel có ID âm
+		if sub.ChatID > 0 && !allowed[sub.ChatID] {
+			continue
+		}
+		if sub.Wants(t, sev) {
+			dests = append(dests, sub.Destination())
+		}
+	}
+	return dests
+}
+
+// sorted trả về các đăng ký sắp xếp theo chat (gọi khi đang giữ lock)
+func (s *SubscriptionStore) sorted() []*Subscription {
+	subs := make([]*Subscription, 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		if subs[i].ChatID != subs[j].ChatID {
+			return subs[i].ChatID < subs[j].ChatID
+		}
+		return subs[i].ThreadID < subs[j].ThreadID
+	})
+	return subs
+}
+
+// save ghi các đăng ký xuống file (gọi khi đang giữ lock)
+func (s *SubscriptionStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.sorted(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
@@ -1,7 +1,6 @@
 package services
 
 import (
-	"fmt"
 	"net"
 	"os"
 	"strconv"
@@ -20,8 +19,9 @@ type SystemInfo struct {
 	Memory    MemoryInfo
 	Disk      DiskInfo
 	Network   NetworkInfo
-	Uptime    string
-	Timestamp string
+	WiFi      *WiFiInfo // nil nếu không có Wi-Fi
+	Uptime    time.Duration
+	Timestamp time.Time
 }
 
 type CPUInfo struct {
@@ -32,28 +32,28 @@ type CPUInfo struct {
 }
 
 type MemoryInfo struct {
-	Total       string
-	Used        string
-	Available   string
+	Total       uint64 // byte
+	Used        uint64
+	Available   uint64
 	UsedPercent float64
 }
 
 type DiskInfo struct {
-	Total       string
-	Used        string
-	Free        string
+	Total       uint64 // byte
+	Used        uint64
+	Free        uint64
 	UsedPercent float64
 }
 
 type NetworkInfo struct {
-	IP        string
-	BytesSent string
-	BytesRecv string
+	IP   string
+	Sent uint64 // Tổng số byte đã gửi
+	Recv uint64 // Tổng số byte đã nhận
 }
 
 func GetSystemInfo() (*SystemInfo, error) {
 	info := &SystemInfo{
-		Timestamp: time.Now().Format("02/01/2006 15:04:05"),
+		Timestamp: time.Now(),
 	}
 
 	// CPU Info
@@ -79,33 +79,36 @@ func GetSystemInfo() (*SystemInfo, error) {
 	// Memory Info
 	memInfo, err := mem.VirtualMemory()
 	if err == nil {
-		info.Memory.Total = formatBytes(memInfo.Total)
-		info.Memory.Used = formatBytes(memInfo.Used)
-		info.Memory.Available = formatBytes(memInfo.Available)
+		info.Memory.Total = memInfo.Total
+		info.Memory.Used = memInfo.Used
+		info.Memory.Available = memInfo.Available
 		info.Memory.UsedPercent = memInfo.UsedPercent
 	}
 
 	// Disk Info
 	diskInfo, err := disk.Usage("/")
 	if err == nil {
-		info.Disk.Total = formatBytes(diskInfo.Total)
-		info.Disk.Used = formatBytes(diskInfo.Used)
-		info.Disk.Free = formatBytes(diskInfo.Free)
+		info.Disk.Total = diskInfo.Total
+		info.Disk.Used = diskInfo.Used
+		info.Disk.Free = diskInfo.Free
 		info.Disk.UsedPercent = diskInfo.UsedPercent
 	}
 
 	// Network Info
-	info.Network.IP = getLocalIP()
+	info.Network.IP = GetLocalIP()
 	netIO, err := psnet.IOCounters(false)
 	if err == nil && len(netIO) > 0 {
-		info.Network.BytesSent = formatBytes(netIO[0].BytesSent)
-		info.Network.BytesRecv = formatBytes(netIO[0].BytesRecv)
+		info.Network.Sent = netIO[0].BytesSent
+		info.Network.Recv = netIO[0].BytesRecv
 	}
 
+	// Wi-Fi Info
+	info.WiFi = GetWiFiInfo(WiFiInterface)
+
 	// Uptime
 	uptime, err := host.Uptime()
 	if err == nil {
-		info.Uptime = formatDuration(uptime)
+		info.Uptime = time.Duration(uptime) * time.Second
 	}
 
 	return info, nil
@@ -132,7 +135,8 @@ func getCPUTemperature() float64 {
 	return 0.0
 }
 
-func getLocalIP() string {
+// GetLocalIP trả về địa chỉ IPv4 LAN đầu tiên (không phải loopback)
+func GetLocalIP() string {
 	addrs, err := net.InterfaceAddrs()
 	if err != nil {
 		return "N/A"
@@ -147,30 +151,3 @@ func getLocalIP() string {
 	}
 	return "N/A"
 }
-
-func formatBytes(bytes uint64) string {
-	const unit = 1024
-	if bytes < unit {
-		return fmt.Sprintf("%d B", bytes)
-	}
-	div, exp := uint64(unit), 0
-	for n := bytes / unit; n >= unit; n /= unit {
-		div *= unit
-		exp++
-	}
-	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-}
-
-func formatDuration(seconds uint64) string {
-	days := seconds / 86400
-	hours := (seconds % 86400) / 3600
-	minutes := (seconds % 3600) / 60
-
-	if days > 0 {
-		return fmt.Sprintf("%d ngày %d giờ %d phút", days, hours, minutes)
-	}
-	if hours > 0 {
-		return fmt.Sprintf("%d giờ %d phút", hours, minutes)
-	}
-	return fmt.Sprintf("%d phút", minutes)
-}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WiFiInterface là interface Wi-Fi được theo dõi (vd: wlan0), để trống để tắt
+var WiFiInterface = "wlan0"
+
+// WiFiInfo chứa thông tin kết nối Wi-Fi
+type WiFiInfo struct {
+	Interface   string
+	Connected   bool
+	SSID        string
+	BSSID       string  // MAC của access point
+	SignalDBm   float64 // Cường độ tín hiệu (dBm)
+	LinkQuality float64 // Chất lượng link (%)
+	Bitrate     string  // vd: 72.2 MBit/s
+	Frequency   float64 // MHz
+}
+
+// GetWiFiInfo đọc thông tin Wi-Fi từ /proc/net/wireless và lệnh `iw dev <iface> link`.
+// Trả về nil nếu interface không tồn tại hoặc không phải Wi-Fi.
+func GetWiFiInfo(iface string) *WiFiInfo {
+	if iface == "" {
+		return nil
+	}
+
+	info := &WiFiInfo{Interface: iface}
+	found := false
+
+	if quality, level, ok := readProcWireless(iface); ok {
+		found = true
+		info.LinkQuality = quality
+		info.SignalDBm = level
+	}
+
+	if out, err := runIWLink(iface); err == nil {
+		found = true
+		parseIWLink(out, info)
+	} else if info.SignalDBm != 0 {
+		// Không có iw: /proc/net/wireless chỉ có dòng cho interface đang associated
+		info.Connected = true
+	}
+
+	if !found {
+		return nil
+	}
+	return info
+}
+
+// readProcWireless đọc link quality (%) và signal level (dBm) từ /proc/net/wireless
+func readProcWireless(iface string) (quality, level float64, ok bool) {
+	paths := []string{
+		"/proc/net/wireless",
+		"/host/proc/1/net/wireless", // Network namespace của host khi chạy trong Docker
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if quality, level, ok := parseProcWireless(data, iface); ok {
+			return quality, level, true
+		}
+	}
+	return 0, 0, false
+}
+
+// parseProcWireless parse nội dung /proc/net/wireless, vd:
+//
+//	wlan0: 0000   70.  -40.  -256        0      0      0      0      0        0
+func parseProcWireless(data []byte, iface string) (quality, level float64, ok bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, iface+":") {
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(line, iface+":"))
+		if len(fields) < 3 {
+			return 0, 0, false
+		}
+
+		link, err1 := strconv.ParseFloat(strings.TrimSuffix(fields[1], "."), 64)
+		lvl, err2 := strconv.ParseFloat(strings.TrimSuffix(fields[2], "."), 64)
+		if err1 != nil || err2 != nil {
+			return 0, 0, false
+		}
+
+		// Link quality thường có thang đo tối đa 70
+		return link / 70.0 * 100.0, lvl, true
+	}
+	return 0, 0, false
+}
+
+// runIWLink chạy `iw dev <iface> link` (nl80211)
+func runIWLink(iface string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "iw", "dev", iface, "link").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("iw: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// parseIWLink parse output của `iw dev <iface> link`, vd:
+//
+//	Connected to aa:bb:cc:dd:ee:ff (on wlan0)
+//		SSID: MyNet
+//		freq: 2437
+//		signal: -52 dBm
+//		tx bitrate: 72.2 MBit/s
+func parseIWLink(out string, info *WiFiInfo) {
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "Not connected"):
+			info.Connected = false
+			return
+		case strings.HasPrefix(line, "Connected to "):
+			info.Connected = true
+			if fields := strings.Fields(line); len(fields) >= 3 {
+				info.BSSID = fields[2]
+			}
+		case strings.HasPrefix(line, "SSID:"):
+			info.SSID = strings.TrimSpace(strings.TrimPrefix(line, "SSID:"))
+		case strings.HasPrefix(line, "freq:"):
+			if v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(line, "freq:")), 64); err == nil {
+				info.Frequency = v
+			}
+		case strings.HasPrefix(line, "signal:"):
+			fields := strings.Fields(strings.TrimPrefix(line, "signal:"))
+			if len(fields) > 0 {
+				if v, err := strconv.ParseFloat(fields[0], 64); err == nil {
+					info.SignalDBm = v
+				}
+			}
+		case strings.HasPrefix(line, "tx bitrate:"):
+			// Chỉ lấy "72.2 MBit/s", bỏ phần MCS phía sau
+			fields := strings.Fields(strings.TrimPrefix(line, "tx bitrate:"))
+			if len(fields) >= 2 {
+				info.Bitrate = fields[0] + " " + fields[1]
+			}
+		}
+	}
+}
@@ -1,63 +1,77 @@
 package services
 
 import (
+	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
 	"net"
+	"strconv"
 	"strings"
-	"time"
+
+	"pi-monitor/i18n"
 )
 
-// IsPCOnline kiểm tra xem PC có đang bật không bằng cách thử kết nối TCP (port 80 hoặc 445)
-// Nếu WOLHost trống, luôn trả về false (không check được)
-func IsPCOnline(host string) bool {
-	if host == "" {
-		return false
-	}
+// ParseMAC parse địa chỉ MAC dạng "AA:BB:CC:DD:EE:FF", "AA-BB-CC-DD-EE-FF" hoặc "aabbccddeeff"
+func ParseMAC(macAddr string) ([]byte, error) {
+	// Normalize MAC address - bỏ dấu : hoặc -
+	normalized := strings.ReplaceAll(macAddr, ":", "")
+	normalized = strings.ReplaceAll(normalized, "-", "")
+	normalized = strings.ToLower(strings.TrimSpace(normalized))
 
-	// Thử kết nối đến các port phổ biến: SMB (445), HTTP (80), RDP (3389)
-	ports := []string{"445", "80", "3389", "22"}
-	for _, port := range ports {
-		addr := net.JoinHostPort(host, port)
-		conn, err := net.DialTimeout("tcp", addr, 1*time.Second)
-		if err == nil {
-			conn.Close()
-			return true
-		}
+	if len(normalized) != 12 {
+		return nil, i18n.Errorf("wol.err.mac", macAddr)
 	}
 
-	// Thử ICMP ping bằng cách dial UDP (không cần quyền root)
-	conn, err := net.DialTimeout("udp", net.JoinHostPort(host, "80"), 1*time.Second)
-	if err == nil {
-		conn.Close()
-		// UDP không xác nhận được kết nối, thử cách khác
+	// Decode MAC address hex string thành bytes
+	macBytes, err := hex.DecodeString(normalized)
+	if err != nil {
+		return nil, i18n.Errorf("wol.err.parse_mac", err)
 	}
+	return macBytes, nil
+}
 
-	return false
+// NormalizeMAC chuyển MAC về dạng chuẩn "aa:bb:cc:dd:ee:ff"
+func NormalizeMAC(macAddr string) (string, error) {
+	macBytes, err := ParseMAC(macAddr)
+	if err != nil {
+		return "", err
+	}
+	return net.HardwareAddr(macBytes).String(), nil
 }
 
-// SendMagicPacket gửi Wake-on-LAN magic packet đến địa chỉ MAC
-// macAddr: địa chỉ MAC dạng "AA:BB:CC:DD:EE:FF" hoặc "AA-BB-CC-DD-EE-FF"
-// broadcast: địa chỉ broadcast dạng "255.255.255.255:9" hoặc "192.168.1.255:9"
-func SendMagicPacket(macAddr, broadcast string) error {
-	// Normalize MAC address - bỏ dấu : hoặc -
-	macAddr = strings.ReplaceAll(macAddr, ":", "")
-	macAddr = strings.ReplaceAll(macAddr, "-", "")
-	macAddr = strings.ToLower(macAddr)
+// ParseSecureOnPassword parse SecureOn password: 6 bytes dạng MAC (AA:BB:CC:DD:EE:FF)
+// hoặc 4 bytes dạng IPv4 (192.168.1.1)
+func ParseSecureOnPassword(password string) ([]byte, error) {
+	if password == "" {
+		return nil, nil
+	}
+	if ip := net.ParseIP(password).To4(); ip != nil && strings.Count(password, ".") == 3 {
+		return []byte(ip), nil
+	}
+	bytes, err := ParseMAC(password)
+	if err != nil {
+		return nil, i18n.Errorf("wol.err.secureon")
+	}
+	return bytes, nil
+}
 
-	if len(macAddr) != 12 {
-		return fmt.Errorf("địa chỉ MAC không hợp lệ: %s", macAddr)
+// BuildMagicPacket tạo magic packet cho địa chỉ MAC, kèm SecureOn password nếu có
+func BuildMagicPacket(macAddr, password string) ([]byte, error) {
+	macBytes, err := ParseMAC(macAddr)
+	if err != nil {
+		return nil, err
 	}
 
-	// Decode MAC address hex string thành bytes
-	macBytes, err := hex.DecodeString(macAddr)
+	passwordBytes, err := ParseSecureOnPassword(password)
 	if err != nil {
-		return fmt.Errorf("không thể parse MAC address: %w", err)
+		return nil, err
 	}
 
 	// Xây dựng magic packet:
-	// 6 bytes 0xFF + 16 lần lặp MAC address (6 bytes) = 102 bytes tổng
-	packet := make([]byte, 102)
+	// 6 bytes 0xFF + 16 lần lặp MAC address (6 bytes) = 102 bytes tổng (+ password)
+	packet := make([]byte, 102, 102+len(passwordBytes))
 
 	// 6 bytes đầu là 0xFF
 	for i := 0; i < 6; i++ {
@@ -69,22 +83,212 @@ func SendMagicPacket(macAddr, broadcast string) error {
 		copy(packet[i*6:], macBytes)
 	}
 
-	// Gửi qua UDP broadcast
-	udpAddr, err := net.ResolveUDPAddr("udp", broadcast)
+	return append(packet, passwordBytes...), nil
+}
+
+// InterfaceBroadcast tính directed broadcast (vd: 192.168.1.255) từ địa chỉ IPv4 của interface
+func InterfaceBroadcast(ifaceName string) (net.IP, error) {
+	iface, err := net.InterfaceByName(ifaceName)
 	if err != nil {
-		return fmt.Errorf("địa chỉ broadcast không hợp lệ: %w", err)
+		return nil, i18n.Errorf("wol.err.interface", ifaceName, err)
 	}
 
-	conn, err := net.DialUDP("udp", nil, udpAddr)
+	addrs, err := iface.Addrs()
 	if err != nil {
-		return fmt.Errorf("không thể mở kết nối UDP: %w", err)
+		return nil, i18n.Errorf("wol.err.interface_addrs", ifaceName, err)
+	}
+
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip := ipnet.IP.To4()
+		if ip == nil || len(ipnet.Mask) != net.IPv4len {
+			continue
+		}
+
+		broadcast := make(net.IP, net.IPv4len)
+		for i := range ip {
+			broadcast[i] = ip[i] | ^ipnet.Mask[i]
+		}
+		return broadcast, nil
+	}
+
+	return nil, i18n.Errorf("wol.err.no_ipv4", ifaceName)
+}
+
+// Các chế độ gửi magic packet
+const (
+	WOLModeUDP = "udp" // UDP broadcast (mặc định)
+	WOLModeRaw = "raw" // Ethernet frame EtherType 0x0842 qua raw socket (chỉ Linux, cần root/CAP_NET_RAW)
+	WOLModeAll = "all" // Gửi cả hai dạng
+)
+
+// EtherTypeWOL là EtherType của Wake-on-LAN dạng Ethernet frame
+const EtherTypeWOL = 0x0842
+
+// DefaultWOLPorts là các port UDP mặc định khi gửi magic packet
+var DefaultWOLPorts = []int{7, 9}
+
+// WakeOptions là cách gửi magic packet đến một PC
+type WakeOptions struct {
+	Broadcast string // Địa chỉ broadcast (vd: 192.168.1.255 hoặc 192.168.1.255:9), trống = tự tính từ Interface
+	Interface string // Interface gửi packet (vd: eth0), trống = theo route mặc định
+	Ports     []int  // Port UDP, trống = port trong Broadcast hoặc DefaultWOLPorts
+	Password  string // SecureOn password (để trống nếu không dùng)
+	Mode      string // udp, raw hoặc all
+}
+
+// SendMagicPacket gửi Wake-on-LAN magic packet đến địa chỉ MAC theo opts.
+// macAddr: địa chỉ MAC dạng "AA:BB:CC:DD:EE:FF" hoặc "AA-BB-CC-DD-EE-FF".
+// Trả về mô tả ngắn gọn nơi đã gửi (vd: "192.168.1.255:7,9 qua eth0").
+func SendMagicPacket(macAddr string, opts WakeOptions) (string, error) {
+	packet, err := BuildMagicPacket(macAddr, opts.Password)
+	if err != nil {
+		return "", err
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(opts.Mode))
+	if mode == "" {
+		mode = WOLModeUDP
+	}
+
+	if mode != WOLModeUDP && mode != WOLModeRaw && mode != WOLModeAll {
+		return "", i18n.Errorf("wol.err.mode", opts.Mode)
+	}
+
+	var sent []string
+	var errs []error
+
+	if mode == WOLModeRaw || mode == WOLModeAll {
+		if opts.Interface == "" {
+			errs = append(errs, i18n.Errorf("wol.err.raw_interface"))
+		} else if err := sendRawMagicPacket(opts.Interface, packet); err != nil {
+			errs = append(errs, i18n.Errorf("error.source", WOLModeRaw, err))
+		} else {
+			sent = append(sent, fmt.Sprintf("%s (Ethernet 0x%04x)", opts.Interface, EtherTypeWOL))
+		}
+	}
+
+	if mode == WOLModeUDP || mode == WOLModeAll {
+		if via, err := sendUDPMagicPacket(packet, opts); err != nil {
+			errs = append(errs, i18n.Errorf("error.source", WOLModeUDP, err))
+		} else {
+			sent = append(sent, via)
+		}
+	}
+
+	// Ở chế độ all, chỉ cần một cách gửi thành công
+	if len(sent) == 0 {
+		return "", errors.Join(errs...)
+	}
+	for _, err := range errs {
+		log.Printf("⚠️ Magic packet to %s: %v", macAddr, err)
+	}
+	return strings.Join(sent, " + "), nil
+}
+
+// sendUDPMagicPacket gửi magic packet qua UDP broadcast đến các port đã cấu hình
+func sendUDPMagicPacket(packet []byte, opts WakeOptions) (string, error) {
+	host, ports, err := wakeDestination(opts)
+	if err != nil {
+		return "", err
 	}
-	defer conn.Close()
 
-	_, err = conn.Write(packet)
+	conn, err := listenUDPOn(opts.Interface)
 	if err != nil {
-		return fmt.Errorf("không thể gửi magic packet: %w", err)
+		return "", i18n.Errorf("wol.err.udp_listen", err)
+	}
+	defer conn.Close()
+
+	portList := make([]string, 0, len(ports))
+	for _, port := range ports {
+		addr, err := net.ResolveUDPAddr("udp4", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return "", i18n.Errorf("wol.err.broadcast", err)
+		}
+		if _, err := conn.WriteTo(packet, addr); err != nil {
+			return "", i18n.Errorf("wol.err.send", addr, err)
+		}
+		portList = append(portList, strconv.Itoa(port))
+	}
+
+	via := host + ":" + strings.Join(portList, ",")
+	if opts.Interface != "" {
+		via += " (" + opts.Interface + ")"
+	}
+	return via, nil
+}
+
+// wakeDestination xác định địa chỉ broadcast và các port UDP cần gửi: send_ports nếu có,
+// rồi port ghi trong broadcast (vd: 192.168.1.255:9 chỉ gửi port 9), cuối cùng là DefaultWOLPorts
+func wakeDestination(opts WakeOptions) (string, []int, error) {
+	host := opts.Broadcast
+	var explicitPort int
+	if h, p, err := net.SplitHostPort(opts.Broadcast); err == nil {
+		host = h
+		if port, err := strconv.Atoi(p); err == nil && port > 0 && port < 65536 {
+			explicitPort = port
+		}
+	}
+
+	// Chưa cấu hình broadcast -> directed broadcast của interface, hoặc broadcast toàn mạng
+	if host == "" {
+		host = "255.255.255.255"
+		if opts.Interface != "" {
+			ip, err := InterfaceBroadcast(opts.Interface)
+			if err != nil {
+				return "", nil, err
+			}
+			host = ip.String()
+		}
+	}
+
+	ports := opts.Ports
+	if len(ports) == 0 && explicitPort != 0 {
+		ports = []int{explicitPort}
+	}
+	if len(ports) == 0 {
+		ports = DefaultWOLPorts
+	}
+	return host, ports, nil
+}
+
+// listenUDPOn mở socket UDP gắn với interface (nếu có) để packet không đi theo route mặc định.
+// Dùng SO_BINDTODEVICE khi có quyền, nếu không thì bind vào địa chỉ IPv4 của interface.
+func listenUDPOn(ifaceName string) (net.PacketConn, error) {
+	if ifaceName == "" {
+		return net.ListenPacket("udp4", ":0")
 	}
 
-	return nil
+	lc := net.ListenConfig{Control: bindToDevice(ifaceName)}
+	conn, err := lc.ListenPacket(context.Background(), "udp4", ":0")
+	if err == nil {
+		return conn, nil
+	}
+
+	ip, ipErr := interfaceIPv4(ifaceName)
+	if ipErr != nil {
+		return nil, fmt.Errorf("%v; %w", err, ipErr)
+	}
+	return net.ListenPacket("udp4", net.JoinHostPort(ip.String(), "0"))
+}
+
+// interfaceIPv4 trả về địa chỉ IPv4 đầu tiên của interface
+func interfaceIPv4(ifaceName string) (net.IP, error) {
+	iface, err := net.InterfaceByName(ifaceName)
+	if err != nil {
+		return nil, i18n.Errorf("wol.err.interface", ifaceName, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, i18n.Errorf("wol.err.interface_addrs", ifaceName, err)
+	}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.To4() != nil {
+			return ipnet.IP.To4(), nil
+		}
+	}
+	return nil, i18n.Errorf("wol.err.no_ipv4", ifaceName)
 }
@@ -0,0 +1,64 @@
+//go:build linux
+
+package services
+
+import (
+	"net"
+	"syscall"
+
+	"pi-monitor/i18n"
+)
+
+// bindToDevice gắn socket vào interface bằng SO_BINDTODEVICE (cần root/CAP_NET_RAW)
+func bindToDevice(ifaceName string) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, c syscall.RawConn) error {
+		var bindErr error
+		if err := c.Control(func(fd uintptr) {
+			bindErr = syscall.BindToDevice(int(fd), ifaceName)
+		}); err != nil {
+			return err
+		}
+		return bindErr
+	}
+}
+
+// sendRawMagicPacket gửi magic packet dạng Ethernet frame (EtherType 0x0842) đến ff:ff:ff:ff:ff:ff
+func sendRawMagicPacket(ifaceName string, packet []byte) error {
+	iface, err := net.InterfaceByName(ifaceName)
+	if err != nil {
+		return i18n.Errorf("wol.err.interface", ifaceName, err)
+	}
+	if len(iface.HardwareAddr) != 6 {
+		return i18n.Errorf("wol.err.not_ethernet", ifaceName)
+	}
+
+	fd, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_RAW, int(htons(EtherTypeWOL)))
+	if err != nil {
+		return i18n.Errorf("wol.err.raw_socket", err)
+	}
+	defer syscall.Close(fd)
+
+	// Ethernet header: MAC đích (broadcast) + MAC nguồn + EtherType
+	frame := make([]byte, 0, 14+len(packet))
+	frame = append(frame, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff)
+	frame = append(frame, iface.HardwareAddr...)
+	frame = append(frame, byte(EtherTypeWOL>>8), byte(EtherTypeWOL&0xff))
+	frame = append(frame, packet...)
+
+	addr := &syscall.SockaddrLinklayer{
+		Protocol: htons(EtherTypeWOL),
+		Ifindex:  iface.Index,
+		Halen:    6,
+	}
+	copy(addr.Addr[:], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+
+	if err := syscall.Sendto(fd, frame, 0, addr); err != nil {
+		return i18n.Errorf("wol.err.send_frame", ifaceName, err)
+	}
+	return nil
+}
+
+// htons chuyển uint16 sang network byte order
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
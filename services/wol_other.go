@@ -0,0 +1,21 @@
+//go:build !linux
+
+package services
+
+import (
+	"syscall"
+
+	"pi-monitor/i18n"
+)
+
+// bindToDevice không hỗ trợ ngoài Linux, socket sẽ bind vào địa chỉ IPv4 của interface
+func bindToDevice(string) func(network, address string, c syscall.RawConn) error {
+	return func(string, string, syscall.RawConn) error {
+		return i18n.Errorf("wol.err.bind_linux_only")
+	}
+}
+
+// sendRawMagicPacket không hỗ trợ ngoài Linux
+func sendRawMagicPacket(string, []byte) error {
+	return i18n.Errorf("wol.err.raw_linux_only")
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pi-monitor/i18n"
+)
+
+// Giao thức giữa Pi và pc-agent chạy trên PC:
+//
+//	POST /v1/power
+//	X-Agent-Timestamp: <unix seconds>
+//	X-Agent-Nonce: <random hex>
+//	X-Agent-Signature: hex(HMAC-SHA256(secret, "POST\n/v1/power\n<timestamp>\n<nonce>\n<body>"))
+//
+//	{"action": "ping" | "sleep" | "shutdown"}
+const (
+	AgentPath            = "/v1/power"
+	AgentHeaderTimestamp = "X-Agent-Timestamp"
+	AgentHeaderNonce     = "X-Agent-Nonce"
+	AgentHeaderSignature = "X-Agent-Signature"
+
+	// AgentMaxSkew là độ lệch thời gian tối đa giữa Pi và PC
+	AgentMaxSkew = 60 * time.Second
+)
+
+// AgentAction là lệnh gửi đến pc-agent
+type AgentAction string
+
+const (
+	AgentPing     AgentAction = "ping"
+	AgentSleep    AgentAction = "sleep"
+	AgentShutdown AgentAction = "shutdown"
+)
+
+// AgentRequest là nội dung request gửi đến pc-agent
+type AgentRequest struct {
+	Action AgentAction `json:"action"`
+}
+
+// AgentResponse là phản hồi của pc-agent
+type AgentResponse struct {
+	OK       bool   `json:"ok"`
+	Message  string `json:"message,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+}
+
+// SignAgentRequest tính chữ ký HMAC-SHA256 cho request
+func SignAgentRequest(secret []byte, method, path, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(method + "\n" + path + "\n" + timestamp + "\n" + nonce + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// AgentClient gửi lệnh đến pc-agent
+type AgentClient struct {
+	URL    string // vd: http://192.168.1.100:9770
+	Secret string
+	HTTP   *http.Client
+}
+
+// NewAgentClient tạo client mới
+func NewAgentClient(url, secret string) *AgentClient {
+	return &AgentClient{
+		URL:    strings.TrimSuffix(url, "/"),
+		Secret: secret,
+		HTTP:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Send gửi lệnh đến pc-agent và trả về phản hồi
+func (c *AgentClient) Send(action AgentAction) (*AgentResponse, error) {
+	if c.Secret == "" {
+		return nil, i18n.Errorf("agent.err.no_secret")
+	}
+
+	body, err := json.Marshal(AgentRequest{Action: action})
+	if err != nil {
+		return nil, err
+	}
+
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return nil, err
+	}
+	nonce := hex.EncodeToString(nonceBytes)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, c.URL+AgentPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, i18n.Errorf("agent.err.url", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(AgentHeaderTimestamp, timestamp)
+	req.Header.Set(AgentHeaderNonce, nonce)
+	req.Header.Set(AgentHeaderSignature, SignAgentRequest([]byte(c.Secret), http.MethodPost, AgentPath, timestamp, nonce, body))
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, i18n.Errorf("agent.err.connect", err)
+	}
+	defer resp.Body.Close()
+
+	var result AgentResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&result); err != nil {
+		return nil, i18n.Errorf("agent.err.response", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || !result.OK {
+		return &result, i18n.Errorf("agent.err.rejected", resp.StatusCode, result.Message)
+	}
+	return &result, nil
+}
+
+// AgentServer là HTTP handler của pc-agent: xác thực chữ ký rồi thực hiện lệnh
+type AgentServer struct {
+	Secret  []byte
+	Execute func(action AgentAction) error // Thực hiện sleep/shutdown ngay
+
+	mu     sync.Mutex
+	nonces map[string]time.Time // Nonce đã dùng, chống replay
+}
+
+// NewAgentServer tạo server mới
+func NewAgentServer(secret string, execute func(AgentAction) error) *AgentServer {
+	return &AgentServer{
+		Secret:  []byte(secret),
+		Execute: execute,
+		nonces:  make(map[string]time.Time),
+	}
+}
+
+// ServeHTTP xử lý request từ Pi
+func (s *AgentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != AgentPath {
+		writeAgentResponse(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeAgentResponse(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64*1024))
+	if err != nil {
+		writeAgentResponse(w, http.StatusBadRequest, "cannot read body")
+		return
+	}
+
+	if err := s.verify(r, body, time.Now()); err != nil {
+		log.Printf("🚫 Rejected request from %s: %v", r.RemoteAddr, err)
+		writeAgentResponse(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var req AgentRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeAgentResponse(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	switch req.Action {
+	case AgentPing:
+		writeAgentResponse(w, http.StatusOK, "pong")
+	case AgentSleep, AgentShutdown:
+		log.Printf("⚡ %s requested by %s", req.Action, r.RemoteAddr)
+		if err := s.Execute(req.Action); err != nil {
+			writeAgentResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeAgentResponse(w, http.StatusOK, string(req.Action)+" scheduled")
+	default:
+		writeAgentResponse(w, http.StatusBadRequest, "unknown action")
+	}
+}
+
+// verify kiểm tra timestamp, nonce và chữ ký của request
+func (s *AgentServer) verify(r *http.Request, body []byte, now time.Time) error {
+	timestamp := r.Header.Get(AgentHeaderTimestamp)
+	nonce := r.Header.Get(AgentHeaderNonce)
+	signature := r.Header.Get(AgentHeaderSignature)
+	if timestamp == "" || nonce == "" || signature == "" {
+		return errors.New("missing authentication headers")
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid timestamp")
+	}
+	skew := now.Sub(time.Unix(ts, 0))
+	if skew > AgentMaxSkew || skew < -AgentMaxSkew {
+		return fmt.Errorf("timestamp skew too large (%v)", skew.Round(time.Second))
+	}
+
+	expected := SignAgentRequest(s.Secret, r.Method, r.URL.Path, timestamp, nonce, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("invalid signature")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Nonce chỉ cần nhớ trong khoảng skew cho phép
+	for n, t := range s.nonces {
+		if now.Sub(t) > 2*AgentMaxSkew {
+			delete(s.nonces, n)
+		}
+	}
+	if _, used := s.nonces[nonce]; used {
+		return errors.New("replayed nonce")
+	}
+	s.nonces[nonce] = now
+	return nil
+}
+
+// writeAgentResponse ghi phản hồi JSON
+func writeAgentResponse(w http.ResponseWriter, status int, message string) {
+	hostname, _ := os.Hostname()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(AgentResponse{OK: status == http.StatusOK, Message: message, Hostname: hostname})
+}
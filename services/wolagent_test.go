@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testAgentSecret = "agent-secret"
+
+// executed ghi lại các lệnh mà AgentServer đã thực hiện
+type executed struct {
+	mu      sync.Mutex
+	actions []AgentAction
+}
+
+func (e *executed) execute(action AgentAction) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.actions = append(e.actions, action)
+	return nil
+}
+
+func (e *executed) count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.actions)
+}
+
+// newTestAgent chạy pc-agent giả trên httptest.Server
+func newTestAgent(t *testing.T) (*httptest.Server, *executed) {
+	t.Helper()
+	var e executed
+	srv := httptest.NewServer(NewAgentServer(testAgentSecret, e.execute))
+	t.Cleanup(srv.Close)
+	return srv, &e
+}
+
+// signedRequest là request đã ký, có thể sửa trước khi gửi để giả lập tấn công
+type signedRequest struct {
+	timestamp string
+	nonce     string
+	signature string
+	body      []byte
+}
+
+// sign ký request như AgentClient với secret, thời điểm và nonce cho trước
+func sign(t *testing.T, secret string, at time.Time, nonce string, req AgentRequest) signedRequest {
+	t.Helper()
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return signedRequest{
+		timestamp: timestamp,
+		nonce:     nonce,
+		signature: SignAgentRequest([]byte(secret), http.MethodPost, AgentPath, timestamp, nonce, body),
+		body:      body,
+	}
+}
+
+// send gửi request đã ký đến agent và trả về status code
+func (s signedRequest) send(t *testing.T, url string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url+AgentPath, bytes.NewReader(s.body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(AgentHeaderTimestamp, s.timestamp)
+	req.Header.Set(AgentHeaderNonce, s.nonce)
+	req.Header.Set(AgentHeaderSignature, s.signature)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAgentClientPing(t *testing.T) {
+	srv, e := newTestAgent(t)
+
+	resp, err := NewAgentClient(srv.URL+"/", testAgentSecret).Send(AgentPing)
+	if err != nil {
+		t.Fatalf("Send(ping) = %v", err)
+	}
+	if !resp.OK || resp.Message != "pong" {
+		t.Errorf("response = %+v, want ok pong", resp)
+	}
+	if e.count() != 0 {
+		t.Error("ping executed a power action")
+	}
+}
+
+func TestAgentClientWrongSecret(t *testing.T) {
+	srv, e := newTestAgent(t)
+
+	resp, err := NewAgentClient(srv.URL, "wrong").Send(AgentShutdown)
+	if err == nil {
+		t.Fatal("Send() with wrong secret succeeded")
+	}
+	if resp == nil || resp.OK || resp.Message != "invalid signature" {
+		t.Errorf("response = %+v, want invalid signature", resp)
+	}
+	if e.count() != 0 {
+		t.Error("rejected request was executed")
+	}
+}
+
+func TestAgentServerAuth(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		req    func() signedRequest
+		status int
+	}{
+		{
+			name:   "valid",
+			req:    func() signedRequest { return sign(t, testAgentSecret, now, "n1", AgentRequest{Action: AgentSleep}) },
+			status: http.StatusOK,
+		},
+		{
+			name:   "wrong secret",
+			req:    func() signedRequest { return sign(t, "wrong", now, "n1", AgentRequest{Action: AgentSleep}) },
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "missing signature",
+			req: func() signedRequest {
+				s := sign(t, testAgentSecret, now, "n1", AgentRequest{Action: AgentSleep})
+				s.signature = ""
+				return s
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "too old",
+			req: func() signedRequest {
+				return sign(t, testAgentSecret, now.Add(-AgentMaxSkew-5*time.Second), "n1", AgentRequest{Action: AgentSleep})
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "in the future",
+			req: func() signedRequest {
+				return sign(t, testAgentSecret, now.Add(AgentMaxSkew+5*time.Second), "n1", AgentRequest{Action: AgentSleep})
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "body changed after signing",
+			req: func() signedRequest {
+				s := sign(t, testAgentSecret, now, "n1", AgentRequest{Action: AgentPing})
+				s.body = []byte(`{"action":"shutdown"}`)
+				return s
+			},
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, e := newTestAgent(t)
+			if got := tt.req().send(t, srv.URL); got != tt.status {
+				t.Fatalf("status = %d, want %d", got, tt.status)
+			}
+			if wantRun := tt.status == http.StatusOK; (e.count() == 1) != wantRun {
+				t.Errorf("executed %d action(s), want executed = %v", e.count(), wantRun)
+			}
+		})
+	}
+}
+
+func TestAgentServerReplay(t *testing.T) {
+	srv, e := newTestAgent(t)
+
+	req := sign(t, testAgentSecret, time.Now(), "same-nonce", AgentRequest{Action: AgentShutdown})
+	if got := req.send(t, srv.URL); got != http.StatusOK {
+		t.Fatalf("first request status = %d, want 200", got)
+	}
+	if got := req.send(t, srv.URL); got != http.StatusUnauthorized {
+		t.Errorf("replayed request status = %d, want 401", got)
+	}
+	if e.count() != 1 {
+		t.Errorf("executed %d action(s), want 1", e.count())
+	}
+}
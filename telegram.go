@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"pi-monitor/config"
+	"pi-monitor/handlers"
+	"pi-monitor/i18n"
+	"pi-monitor/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// incomingUpdate là update từ Telegram kèm các trường mà tgbotapi v5.5.1 chưa hỗ trợ
+type incomingUpdate struct {
+	tgbotapi.Update
+	ThreadID int // message_thread_id của tin nhắn trong forum topic, 0 = chat chính
+}
+
+// decodeUpdate parse một update dạng JSON
+func decodeUpdate(data []byte) (incomingUpdate, error) {
+	var u incomingUpdate
+	if err := json.Unmarshal(data, &u.Update); err != nil {
+		return u, err
+	}
+
+	var extra struct {
+		Message *struct {
+			ThreadID       int  `json:"message_thread_id"`
+			IsTopicMessage bool `json:"is_topic_message"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(data, &extra); err == nil && extra.Message != nil && extra.Message.IsTopicMessage {
+		u.ThreadID = extra.Message.ThreadID
+	}
+	return u, nil
+}
+
+// pollUpdates nhận update bằng long polling (getUpdates), tương tự GetUpdatesChan nhưng giữ được message_thread_id.
+// Khi ctx bị huỷ, dừng polling và đóng channel. Update của request getUpdates đang chờ không được xác nhận
+// (offset) nên Telegram gửi lại ở lần khởi động sau.
+func pollUpdates(ctx context.Context, bot *tgbotapi.BotAPI, timeout int) <-chan incomingUpdate {
+	ch := make(chan incomingUpdate, bot.Buffer)
+
+	type result struct {
+		resp *tgbotapi.APIResponse
+		err  error
+	}
+
+	// wait chờ khoảng d, trả về false nếu ctx bị huỷ trước
+	wait := func(d time.Duration) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(d):
+			return true
+		}
+	}
+
+	go func() {
+		defer close(ch)
+
+		offset := 0
+		for {
+			params := tgbotapi.Params{}
+			params.AddNonZero("offset", offset)
+			params.AddNonZero("timeout", timeout)
+
+			// getUpdates chờ đến timeout giây, chạy riêng để dừng ngay khi ctx bị huỷ
+			done := make(chan result, 1)
+			go func() {
+				resp, err := bot.MakeRequest("getUpdates", params)
+				done <- result{resp, err}
+			}()
+
+			var r result
+			select {
+			case <-ctx.Done():
+				log.Printf("🔄 Long polling stopped")
+				return
+			case r = <-done:
+			}
+
+			if r.err != nil {
+				log.Printf("❌ Failed to get updates, retrying in 3 seconds: %v", r.err)
+				if !wait(3 * time.Second) {
+					return
+				}
+				continue
+			}
+
+			var raws []json.RawMessage
+			if err := json.Unmarshal(r.resp.Result, &raws); err != nil {
+				log.Printf("❌ Cannot parse updates: %v", err)
+				if !wait(3 * time.Second) {
+					return
+				}
+				continue
+			}
+
+			for _, raw := range raws {
+				u, err := decodeUpdate(raw)
+				if err != nil {
+					log.Printf("⚠️ Skipping malformed update: %v", err)
+					continue
+				}
+				if u.UpdateID < offset {
+					continue
+				}
+				select {
+				case ch <- u:
+					offset = u.UpdateID + 1
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch
+}
+
+// sendToDestination gửi tin nhắn HTML đến chat hoặc forum topic, gửi lại dạng text thường nếu Telegram không parse được HTML.
+// tgbotapi v5.5.1 không có message_thread_id nên gửi vào topic bằng request trực tiếp.
+func sendToDestination(bot *tgbotapi.BotAPI, dest services.Destination, text string) error {
+	if dest.ThreadID == 0 {
+		_, err := handlers.Send(bot, tgbotapi.NewMessage(dest.ChatID, text))
+		return err
+	}
+
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", dest.ChatID)
+	params.AddNonZero("message_thread_id", dest.ThreadID)
+	params["text"] = text
+	params["parse_mode"] = tgbotapi.ModeHTML
+	_, err := bot.MakeRequest("sendMessage", params)
+	if handlers.IsParseError(err) {
+		log.Printf("⚠️ Telegram rejected HTML message, sending as plain text: %v", err)
+		params["text"] = i18n.PlainText(text)
+		delete(params, "parse_mode")
+		_, err = bot.MakeRequest("sendMessage", params)
+	}
+	return err
+}
+
+// deliverNotification gửi thông báo trong hàng đợi, thông báo gửi trễ được đánh dấu kèm thời điểm tạo
+func deliverNotification(bot *tgbotapi.BotAPI, cfg *config.Config, m services.OutboxMessage) error {
+	text := m.Text
+	if m.Delayed(time.Now()) {
+		p := i18n.NewPrinter(m.Lang, cfg.Location)
+		text = p.T("outbox.delayed", p.DateTime(m.CreatedAt)) + "\n\n" + text
+	}
+	return deliveryError(sendToDestination(bot, m.Destination(), text))
+}
+
+// permanentDeliveryErrors là mô tả lỗi Telegram (400, 403) cho biết không thể gửi đến chat này nữa.
+// Lỗi 400 khác (vd: HTML sai, tin quá dài) có thể do nội dung hoặc lỗi tạm thời nên vẫn được gửi lại.
+var permanentDeliveryErrors = []string{
+	"chat not found",
+	"message thread not found",
+	"bot was blocked",
+	"bot was kicked",
+	"bot is not a member",
+	"user is deactivated",
+	"bot can't initiate conversation",
+}
+
+// deliveryError phân loại lỗi Telegram cho hàng đợi: chờ theo retry_after (429),
+// bỏ tin nhắn khi chat không tồn tại hoặc bot bị chặn, còn lại gửi lại sau
+func deliveryError(err error) error {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return err
+	}
+	if tgErr.RetryAfter > 0 {
+		return &services.DeliveryError{Err: err, RetryAfter: time.Duration(tgErr.RetryAfter) * time.Second}
+	}
+	if tgErr.Code == http.StatusBadRequest || tgErr.Code == http.StatusForbidden {
+		description := strings.ToLower(tgErr.Message)
+		for _, s := range permanentDeliveryErrors {
+			if strings.Contains(description, s) {
+				return &services.DeliveryError{Err: err, Permanent: true}
+			}
+		}
+	}
+	return err
+}
+
+// addressedToOtherBot kiểm tra lệnh dạng /pi@otherbot gửi cho bot khác trong group
+func addressedToOtherBot(message *tgbotapi.Message, botName string) bool {
+	command := message.CommandWithAt()
+	i := strings.Index(command, "@")
+	return i >= 0 && !strings.EqualFold(command[i+1:], botName)
+}
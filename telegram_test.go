@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pi-monitor/services"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestDeliveryError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		permanent  bool
+		retryAfter time.Duration
+	}{
+		{name: "network", err: errors.New("dial tcp: i/o timeout")},
+		{name: "rate limit", err: &tgbotapi.Error{Code: 429, Message: "Too Many Requests: retry after 17",
+			ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 17}}, retryAfter: 17 * time.Second},
+		{name: "chat not found", err: &tgbotapi.Error{Code: 400, Message: "Bad Request: chat not found"}, permanent: true},
+		{name: "topic deleted", err: &tgbotapi.Error{Code: 400, Message: "Bad Request: message thread not found"}, permanent: true},
+		{name: "blocked", err: &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}, permanent: true},
+		{name: "kicked", err: &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was kicked from the supergroup chat"}, permanent: true},
+		{name: "deactivated", err: &tgbotapi.Error{Code: 403, Message: "Forbidden: user is deactivated"}, permanent: true},
+		{name: "message too long", err: &tgbotapi.Error{Code: 400, Message: "Bad Request: message is too long"}},
+		{name: "server error", err: &tgbotapi.Error{Code: 502, Message: "Bad Gateway"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := deliveryError(tt.err)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("deliveryError() = %v, want wrapping %v", err, tt.err)
+			}
+			var de *services.DeliveryError
+			errors.As(err, &de)
+			if got := de != nil && de.Permanent; got != tt.permanent {
+				t.Errorf("Permanent = %v, want %v", got, tt.permanent)
+			}
+			var retryAfter time.Duration
+			if de != nil {
+				retryAfter = de.RetryAfter
+			}
+			if retryAfter != tt.retryAfter {
+				t.Errorf("RetryAfter = %v, want %v", retryAfter, tt.retryAfter)
+			}
+		})
+	}
+}
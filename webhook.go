@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"pi-monitor/config"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// webhookSecretHeader là header Telegram gửi kèm secret_token đã đăng ký bằng setWebhook
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// webhookMaxBody là kích thước tối đa của một update gửi đến webhook
+const webhookMaxBody = 1 << 20
+
+// webhookServer nhận update từ Telegram qua HTTP(S) POST thay cho long polling
+type webhookServer struct {
+	bot     *tgbotapi.BotAPI
+	cfg     *config.Config
+	path    string
+	secret  string
+	server  *http.Server
+	updates chan incomingUpdate
+
+	mu   sync.RWMutex  // Handler giữ RLock khi gửi vào updates, Stop giữ Lock khi đóng updates
+	done chan struct{} // Đóng khi webhook dừng, handler không gửi update nữa
+}
+
+// startWebhook mở listener nhận update và đăng ký webhook với Telegram (setWebhook).
+// Không có webhook.url thì chỉ lắng nghe, dùng khi tự đăng ký webhook hoặc thử bằng cách POST update vào listener.
+func startWebhook(bot *tgbotapi.BotAPI, cfg *config.Config) (*webhookServer, error) {
+	w := &webhookServer{
+		bot:     bot,
+		cfg:     cfg,
+		path:    "/",
+		secret:  cfg.WebhookSecret,
+		updates: make(chan incomingUpdate, bot.Buffer),
+		done:    make(chan struct{}),
+	}
+
+	if cfg.WebhookURL != "" {
+		u, err := url.Parse(cfg.WebhookURL)
+		if err != nil {
+			return nil, err
+		}
+		if u.Path != "" {
+			w.path = u.Path
+		}
+		// Telegram gửi lại secret nên không cần cấu hình, mỗi lần khởi động dùng secret mới
+		if w.secret == "" {
+			w.secret = randomSecret()
+		}
+	} else if w.secret == "" {
+		log.Printf("⚠️ Webhook has no URL and no secret: every POST to %s is accepted as an update", cfg.WebhookListen)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(w.path, w)
+	w.server = &http.Server{
+		Addr:              cfg.WebhookListen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Nạp certificate và mở port trước khi chạy server để báo lỗi ngay (file sai, port đã dùng, không có quyền...)
+	if cfg.WebhookCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.WebhookCertFile, cfg.WebhookKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		w.server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	ln, err := net.Listen("tcp", cfg.WebhookListen)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		var err error
+		if cfg.WebhookCertFile != "" {
+			err = w.server.ServeTLS(ln, "", "")
+		} else {
+			err = w.server.Serve(ln)
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("❌ Webhook server stopped: %v", err)
+		}
+	}()
+
+	scheme := "http"
+	if cfg.WebhookCertFile != "" {
+		scheme = "https"
+	}
+	log.Printf("🌐 Webhook listening on %s://%s%s", scheme, cfg.WebhookListen, w.path)
+
+	if cfg.WebhookURL != "" {
+		if err := w.register(); err != nil {
+			w.server.Close()
+			return nil, err
+		}
+		log.Printf("✅ Webhook registered: %s", cfg.WebhookURL)
+	}
+	return w, nil
+}
+
+// Updates trả về channel nhận update, đóng khi webhook dừng
+func (w *webhookServer) Updates() <-chan incomingUpdate {
+	return w.updates
+}
+
+// ServeHTTP nhận một update: kiểm tra secret token, parse JSON rồi chuyển cho vòng xử lý lệnh
+func (w *webhookServer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != w.path {
+		http.NotFound(rw, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if w.secret != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get(webhookSecretHeader)), []byte(w.secret)) != 1 {
+		log.Printf("🚫 Webhook request from %s with invalid secret token", r.RemoteAddr)
+		http.Error(rw, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, webhookMaxBody))
+	if err != nil {
+		http.Error(rw, "cannot read body", http.StatusBadRequest)
+		return
+	}
+	u, err := decodeUpdate(data)
+	if err != nil {
+		log.Printf("⚠️ Skipping malformed webhook update from %s: %v", r.RemoteAddr, err)
+		http.Error(rw, "malformed update", http.StatusBadRequest)
+		return
+	}
+
+	// Telegram sẽ gửi lại update chưa nhận được 2xx
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	select {
+	case <-w.done:
+		http.Error(rw, "shutting down", http.StatusServiceUnavailable)
+		return
+	default:
+	}
+	select {
+	case w.updates <- u:
+		rw.WriteHeader(http.StatusOK)
+	case <-w.done:
+		http.Error(rw, "shutting down", http.StatusServiceUnavailable)
+	case <-r.Context().Done():
+	}
+}
+
+// Stop xoá webhook khỏi Telegram, chờ các request đang nhận xong rồi đóng channel update.
+// Request chưa xong khi ctx hết hạn được trả 503 thay vì gửi vào channel đã đóng.
+func (w *webhookServer) Stop(ctx context.Context) error {
+	var errs []error
+	if w.cfg.WebhookURL != "" {
+		if err := deleteWebhook(w.bot); err != nil {
+			errs = append(errs, err)
+		} else {
+			log.Printf("🧹 Webhook deleted")
+		}
+	}
+	if err := w.server.Shutdown(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	close(w.done)
+	w.mu.Lock()
+	close(w.updates)
+	w.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// register gọi setWebhook với URL và secret token (tgbotapi v5.5.1 chưa hỗ trợ secret_token).
+// Certificate tự ký được gửi kèm để Telegram tin cậy.
+func (w *webhookServer) register() error {
+	params := tgbotapi.Params{}
+	params["url"] = w.cfg.WebhookURL
+	params["secret_token"] = w.secret
+
+	if w.cfg.WebhookSelfSigned {
+		files := []tgbotapi.RequestFile{{Name: "certificate", Data: tgbotapi.FilePath(w.cfg.WebhookCertFile)}}
+		_, err := w.bot.UploadFiles("setWebhook", params, files)
+		return err
+	}
+	_, err := w.bot.MakeRequest("setWebhook", params)
+	return err
+}
+
+// deleteWebhook xoá webhook đã đăng ký. Cần gọi trước khi dùng getUpdates,
+// Telegram từ chối long polling khi bot còn webhook.
+func deleteWebhook(bot *tgbotapi.BotAPI) error {
+	_, err := bot.MakeRequest("deleteWebhook", tgbotapi.Params{})
+	return err
+}
+
+// randomSecret tạo secret token ngẫu nhiên cho webhook
+func randomSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
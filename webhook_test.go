@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pi-monitor/config"
+)
+
+// topicUpdate là update Telegram thật (đã rút gọn) của lệnh /status gửi trong một forum topic
+const topicUpdate = `{
+	"update_id": 815623001,
+	"message": {
+		"message_id": 42,
+		"message_thread_id": 7,
+		"is_topic_message": true,
+		"from": {"id": 123456789, "is_bot": false, "first_name": "An", "language_code": "vi"},
+		"chat": {"id": -1001234567890, "title": "Nhà", "type": "supergroup", "is_forum": true},
+		"date": 1760851200,
+		"text": "/status",
+		"entities": [{"offset": 0, "length": 7, "type": "bot_command"}]
+	}
+}`
+
+// newTestWebhook tạo webhookServer chạy trên httptest.Server thay cho listener thật
+func newTestWebhook(t *testing.T, secret string) (*webhookServer, *httptest.Server) {
+	t.Helper()
+	w := &webhookServer{
+		cfg:     &config.Config{},
+		path:    "/hook",
+		secret:  secret,
+		updates: make(chan incomingUpdate, 1),
+		done:    make(chan struct{}),
+	}
+	srv := httptest.NewServer(w)
+	w.server = srv.Config
+	t.Cleanup(srv.Close)
+	return w, srv
+}
+
+// postUpdate gửi update đến webhook, secret rỗng thì không gửi header
+func postUpdate(t *testing.T, url, secret, body string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret != "" {
+		req.Header.Set(webhookSecretHeader, secret)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestWebhookSecret(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		want   int
+	}{
+		{name: "missing", secret: "", want: http.StatusForbidden},
+		{name: "wrong", secret: "guess", want: http.StatusForbidden},
+		{name: "valid", secret: "s3cret", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, srv := newTestWebhook(t, "s3cret")
+			if got := postUpdate(t, srv.URL+"/hook", tt.secret, topicUpdate); got != tt.want {
+				t.Fatalf("status = %d, want %d", got, tt.want)
+			}
+			if tt.want != http.StatusOK && len(w.updates) != 0 {
+				t.Error("rejected update was delivered")
+			}
+		})
+	}
+}
+
+func TestWebhookDeliversUpdate(t *testing.T) {
+	w, srv := newTestWebhook(t, "s3cret")
+	if got := postUpdate(t, srv.URL+"/hook", "s3cret", topicUpdate); got != http.StatusOK {
+		t.Fatalf("status = %d, want 200", got)
+	}
+
+	select {
+	case u := <-w.Updates():
+		if u.UpdateID != 815623001 || u.Message == nil || u.Message.Text != "/status" {
+			t.Errorf("update = %+v, want /status from update 815623001", u.Update)
+		}
+		if u.Message.Chat.ID != -1001234567890 {
+			t.Errorf("chat = %d, want -1001234567890", u.Message.Chat.ID)
+		}
+		if u.ThreadID != 7 {
+			t.Errorf("ThreadID = %d, want 7", u.ThreadID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("update not delivered")
+	}
+}
+
+func TestWebhookRejectsRequests(t *testing.T) {
+	w, srv := newTestWebhook(t, "")
+
+	resp, err := http.Get(srv.URL + "/hook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Allow"); got != http.MethodPost {
+		t.Errorf("Allow = %q, want POST", got)
+	}
+
+	if got := postUpdate(t, srv.URL+"/other", "", topicUpdate); got != http.StatusNotFound {
+		t.Errorf("wrong path status = %d, want 404", got)
+	}
+	if got := postUpdate(t, srv.URL+"/hook", "", "{not json"); got != http.StatusBadRequest {
+		t.Errorf("malformed status = %d, want 400", got)
+	}
+	if len(w.updates) != 0 {
+		t.Error("rejected request was delivered")
+	}
+}
+
+func TestWebhookAfterStop(t *testing.T) {
+	w, srv := newTestWebhook(t, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := w.Stop(ctx); err != nil {
+		t.Fatalf("Stop() = %v", err)
+	}
+	if _, ok := <-w.Updates(); ok {
+		t.Fatal("updates channel still open after Stop")
+	}
+
+	// Shutdown đã đóng listener của httptest, gọi thẳng handler như một request đến muộn
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, srv.URL+"/hook", strings.NewReader(topicUpdate))
+	w.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}